@@ -0,0 +1,254 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Truth returns truth value of value following Python rules:
+// nil, false, zero numbers, empty strings and empty Lists, Dicts, slices
+// and maps are false, everything else is true.
+func Truth(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if n, ok := toNumber(value); ok {
+		switch n.kind {
+		case intNumber:
+			return n.i != 0
+		case uintNumber:
+			return n.u != 0
+		case bigNumber:
+			return true
+		}
+		return n.f != 0
+	}
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array,
+		reflect.Chan:
+		return val.Len() != 0
+	case reflect.Pointer, reflect.Interface, reflect.Func:
+		return !val.IsNil()
+	}
+	return true
+}
+
+//=============================================================================
+
+// All returns true if all elements of the list are true (see Truth),
+// or if the list is empty.
+func All(list List) bool {
+	for _, val := range list {
+		if !Truth(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Any returns true if any element of the list is true (see Truth).
+// For empty list it returns false.
+func Any(list List) bool {
+	for _, val := range list {
+		if Truth(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns a new list with elements of list for which f returns true.
+// If f is nil elements that are true (see Truth) are returned.
+//
+//	listdict.Filter(nil, listdict.List{0, 1, "", "a"}) => [1 a]
+func Filter(f func(interface{}) bool, list List) List {
+	if f == nil {
+		f = Truth
+	}
+	out := List{}
+	for _, val := range list {
+		if f(val) {
+			out = append(out, val)
+		}
+	}
+	return out
+}
+
+// Map returns a new list with results of f applied to every element
+// of the list.
+func Map(f func(interface{}) interface{}, list List) List {
+	out := NewList(len(list))
+	for i, val := range list {
+		out[i] = f(val)
+	}
+	return out
+}
+
+// Max returns the largest element of the list, same as Python's max.
+// If key is not nil elements are compared by key(element).
+// For empty list it returns defaultVal if given, *ValueError otherwise.
+// If many elements are the largest the first one is returned.
+func Max(list List, key func(interface{}) interface{},
+	defaultVal ...interface{}) (interface{}, error) {
+
+	return extreme(list, key, 1, defaultVal)
+}
+
+// Min returns the smallest element of the list, same as Python's min.
+// If key is not nil elements are compared by key(element).
+// For empty list it returns defaultVal if given, *ValueError otherwise.
+// If many elements are the smallest the first one is returned.
+func Min(list List, key func(interface{}) interface{},
+	defaultVal ...interface{}) (interface{}, error) {
+
+	return extreme(list, key, -1, defaultVal)
+}
+
+// Reduce applies f cumulatively to the elements of the list, from left
+// to right, to reduce the list to a single value, same as Python's
+// functools.reduce. If initial is given it's placed before the elements.
+// For empty list without initial it returns *TypeError.
+//
+//	add := func(a, b interface{}) interface{} { return a.(int) + b.(int) }
+//	listdict.Reduce(add, listdict.List{1, 2, 3}) => 6
+func Reduce(f func(acc, val interface{}) interface{}, list List,
+	initial ...interface{}) (interface{}, error) {
+
+	values := list
+	if len(initial) > 0 {
+		values = append(List{initial[0]}, list...)
+	}
+	if len(values) == 0 {
+		return nil, &TypeError{Op: "reduce", Err: ErrEmptySequence}
+	}
+
+	acc := values[0]
+	for _, val := range values[1:] {
+		acc = f(acc, val)
+	}
+	return acc, nil
+}
+
+// Sum returns start plus sum of all elements of the list. Elements can be
+// of any Go numeric kind, the result is int if all values are integers
+// and float64 otherwise. Non-numeric elements return *TypeError.
+//
+//	listdict.Sum(listdict.List{1, int8(2), 0.5}, 0) => 3.5
+func Sum(list List, start interface{}) (interface{}, error) {
+	acc, ok := toNumber(start)
+	if !ok {
+		return nil, &TypeError{
+			Op: "+", Values: []interface{}{start, 0}, Err: ErrUnsupportedOperand}
+	}
+	acc = acc.asSumOperand()
+	for _, val := range list {
+		n, ok := toNumber(val)
+		if !ok {
+			return nil, &TypeError{Op: "+", Values: []interface{}{acc.value(), val},
+				Err: ErrUnsupportedOperand}
+		}
+		acc = acc.add(n.asSumOperand())
+	}
+	return acc.value(), nil
+}
+
+//=============================================================================
+
+// extreme returns the first element with the smallest (sign -1) or
+// the largest (sign 1) key.
+func extreme(list List, key func(interface{}) interface{}, sign int,
+	defaultVal []interface{}) (interface{}, error) {
+
+	if len(list) == 0 {
+		if len(defaultVal) > 0 {
+			return defaultVal[0], nil
+		}
+		return nil, &ValueError{Err: ErrEmptySequence}
+	}
+
+	keyOf := func(val interface{}) interface{} {
+		if key != nil {
+			return key(val)
+		}
+		return val
+	}
+
+	best, bestKey := list[0], keyOf(list[0])
+	for _, val := range list[1:] {
+		valKey := keyOf(val)
+		c, err := Compare(valKey, bestKey)
+		if err != nil {
+			return nil, err
+		}
+		if c == sign {
+			best, bestKey = val, valKey
+		}
+	}
+	return best, nil
+}
+
+// asSumOperand converts integer number to int64 (or *big.Int if it doesn't
+// fit) and leaves floats unchanged,
+// Python has a single int type.
+func (n number) asSumOperand() number {
+	if n.kind == uintNumber {
+		if n.u <= math.MaxInt64 {
+			return number{kind: intNumber, i: int64(n.u)}
+		}
+		return number{kind: bigNumber, b: n.bigInt()}
+	}
+	return n
+}
+
+// add returns a + n, numbers must be int64, *big.Int or float64.
+// Integers that overflow int64 become *big.Int, same as Python's int.
+func (n number) add(other number) number {
+	if n.kind == floatNumber || other.kind == floatNumber {
+		return number{kind: floatNumber, f: n.float() + other.float()}
+	}
+	if n.kind == intNumber && other.kind == intNumber {
+		sum := n.i + other.i
+		if (sum > n.i) == (other.i > 0) {
+			return number{kind: intNumber, i: sum}
+		}
+	}
+	sum := bigToNumber(new(big.Int).Add(n.bigInt(), other.bigInt()))
+	if sum.kind == uintNumber {
+		sum = number{kind: bigNumber, b: sum.bigInt()}
+	}
+	return sum
+}
+
+// float returns number as float64.
+func (n number) float() float64 {
+	switch n.kind {
+	case intNumber:
+		return float64(n.i)
+	case uintNumber:
+		return float64(n.u)
+	case bigNumber:
+		f, _ := n.bigFloat().Float64()
+		return f
+	}
+	return n.f
+}
+
+// value returns Go value of int64, *big.Int or float64 number as int,
+// *big.Int or float64.
+func (n number) value() interface{} {
+	switch n.kind {
+	case intNumber:
+		return int(n.i)
+	case bigNumber:
+		return n.b
+	}
+	return n.f
+}
@@ -0,0 +1,170 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+var truthTests = []struct {
+	in  interface{}
+	out bool
+}{
+	{nil, false},
+	{false, false},
+	{true, true},
+	{0, false},
+	{uint8(0), false},
+	{0.0, false},
+	{-1, true},
+	{0.1, true},
+	{"", false},
+	{"a", true},
+	{List{}, false},
+	{List{nil}, true},
+	{Dict{}, false},
+	{Dict{"a": nil}, true},
+	{[]int{}, false},
+	{struct{}{}, true},
+}
+
+func TestTruth(t *testing.T) {
+	for index, tt := range truthTests {
+		if out := Truth(tt.in); out != tt.out {
+			t.Errorf("%d. Truth(%#v) => %v, want %v", index, tt.in, out, tt.out)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestAnyAll(t *testing.T) {
+	if !All(List{}) || Any(List{}) {
+		t.Errorf("All([]), Any([]) => %v, %v, want true, false",
+			All(List{}), Any(List{}))
+	}
+	if All(List{1, "a", List{}}) || !Any(List{0, "", List{1}}) {
+		t.Errorf("All/Any with mixed truth values returned wrong result")
+	}
+	if !All(List{1, "a", Dict{"a": 1}}) || Any(List{0, nil, Dict{}}) {
+		t.Errorf("All/Any with uniform truth values returned wrong result")
+	}
+}
+
+//=============================================================================
+
+func TestFilterMap(t *testing.T) {
+	filtered := Filter(nil, List{0, 1, "", "a", List{}, nil, 2.5})
+	if want := (List{1, "a", 2.5}); !reflect.DeepEqual(filtered, want) {
+		t.Errorf("Filter(nil, ...) => %v, want %v", filtered, want)
+	}
+
+	isInt := func(v interface{}) bool { _, ok := v.(int); return ok }
+	filtered = Filter(isInt, List{1, "a", 2})
+	if want := (List{1, 2}); !reflect.DeepEqual(filtered, want) {
+		t.Errorf("Filter(isInt, ...) => %v, want %v", filtered, want)
+	}
+
+	double := func(v interface{}) interface{} { return v.(int) * 2 }
+	mapped := Map(double, List{1, 2, 3})
+	if want := (List{2, 4, 6}); !reflect.DeepEqual(mapped, want) {
+		t.Errorf("Map(double, [1 2 3]) => %v, want %v", mapped, want)
+	}
+}
+
+//=============================================================================
+
+func TestReduce(t *testing.T) {
+	add := func(a, b interface{}) interface{} { return a.(int) + b.(int) }
+
+	if out, err := Reduce(add, List{1, 2, 3}); out != 6 || err != nil {
+		t.Errorf("Reduce(add, [1 2 3]) => %v, %v, want 6, nil", out, err)
+	}
+	if out, err := Reduce(add, List{1, 2}, 10); out != 13 || err != nil {
+		t.Errorf("Reduce(add, [1 2], 10) => %v, %v, want 13, nil", out, err)
+	}
+	if out, err := Reduce(add, List{}, 10); out != 10 || err != nil {
+		t.Errorf("Reduce(add, [], 10) => %v, %v, want 10, nil", out, err)
+	}
+	_, err := Reduce(add, List{})
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) || !errors.Is(err, ErrEmptySequence) {
+		t.Errorf("Reduce(add, []) => %v, want TypeError", err)
+	}
+}
+
+//=============================================================================
+
+var sumTests = []struct {
+	in      List
+	start   interface{}
+	out     interface{}
+	isError bool
+}{
+	{List{1, 2, 3}, 0, 6, false},
+	{List{1, int8(2), uint64(3), true}, 0, 7, false},
+	{List{1, 0.5}, 0, 1.5, false},
+	{List{}, 10, 10, false},
+	{List{}, 1.5, 1.5, false},
+	{List{1, "2"}, 0, nil, true},
+	{List{1}, "0", nil, true},
+}
+
+func TestSum(t *testing.T) {
+	for index, st := range sumTests {
+		out, err := Sum(st.in, st.start)
+		if st.isError {
+			if !errors.Is(err, ErrUnsupportedOperand) {
+				t.Errorf("%d. Sum(%v, %v) => error %v, want %v",
+					index, st.in, st.start, err, ErrUnsupportedOperand)
+			}
+			continue
+		}
+		if out != st.out || err != nil {
+			t.Errorf("%d. Sum(%v, %v) => %#v, %v, want %#v, nil",
+				index, st.in, st.start, out, err, st.out)
+		}
+	}
+}
+
+//=============================================================================
+
+var minMaxTests = []struct {
+	in         List
+	key        func(interface{}) interface{}
+	defaultVal []interface{}
+	min, max   interface{}
+	outError   error
+}{
+	{List{3, 1.5, 2}, nil, nil, 1.5, 3, nil},
+	{List{"b", "a", "c"}, nil, nil, "a", "c", nil},
+	{List{"bb", "a", "cc", "d"},
+		func(v interface{}) interface{} { return len(v.(string)) }, nil,
+		"a", "bb", nil},
+	{List{}, nil, []interface{}{"none"}, "none", "none", nil},
+	{List{}, nil, nil, nil, nil, ErrEmptySequence},
+	{List{1, "a"}, nil, nil, nil, nil, ErrUnorderable},
+}
+
+func TestMinMax(t *testing.T) {
+	for index, mmt := range minMaxTests {
+		min, err := Min(mmt.in, mmt.key, mmt.defaultVal...)
+		if min != mmt.min || !errors.Is(err, mmt.outError) {
+			t.Errorf("%d. Min(%v) => %v, %v, want %v, %v",
+				index, mmt.in, min, err, mmt.min, mmt.outError)
+		}
+		max, err := Max(mmt.in, mmt.key, mmt.defaultVal...)
+		if max != mmt.max || !errors.Is(err, mmt.outError) {
+			t.Errorf("%d. Max(%v) => %v, %v, want %v, %v",
+				index, mmt.in, max, err, mmt.max, mmt.outError)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"sort"
+)
+
+// Mapping is the read API of Dict, implemented by Dict and ChainMap.
+type Mapping interface {
+	// Get returns value for the given key or defaultVal if key is missing
+	Get(key string, defaultVal interface{}) interface{}
+	// HasKey returns true if key is in the mapping
+	HasKey(key string) bool
+	// Items returns a list of the mapping's [key, value] pairs
+	Items() []List
+	// Keys returns a list of the mapping's keys
+	Keys() List
+}
+
+// ChainMap groups several Dicts into a single view, same as Python's
+// collections.ChainMap. Lookups search Maps in order and the first Dict
+// having the key wins, writes and deletes change only Maps[0]. A ChainMap
+// with empty Maps reads as empty and Set returns an error for it.
+//
+//	defaults := listdict.Dict{"color": "red", "user": "guest"}
+//	flags := listdict.Dict{"user": "admin"}
+//	cm := listdict.NewChainMap(flags, defaults)
+//	cm.Get("user", nil)   // "admin"
+//	cm.Get("color", nil)  // "red"
+//	cm.Layer("color")     // 1, true
+type ChainMap struct {
+	Maps []Dict
+}
+
+// NewChainMap returns new ChainMap searching maps in given order.
+// Without maps a single empty Dict is used.
+func NewChainMap(maps ...Dict) ChainMap {
+	if len(maps) == 0 {
+		maps = []Dict{NewDict()}
+	}
+	return ChainMap{Maps: maps}
+}
+
+//=============================================================================
+
+// Clear removes all elements from the first Dict.
+func (chain ChainMap) Clear() {
+	if len(chain.Maps) > 0 {
+		chain.Maps[0].Clear()
+	}
+}
+
+// Delete removes the given key from the first Dict. It returns *KeyError
+// if key is NOT in the first Dict, even if other Dicts have it.
+func (chain ChainMap) Delete(key string) error {
+	if len(chain.Maps) == 0 || !chain.Maps[0].HasKey(key) {
+		return &KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+	delete(chain.Maps[0], key)
+	return nil
+}
+
+// Get returns value for the given key from the first Dict having it or
+// defaultVal if key is NOT in any of them.
+func (chain ChainMap) Get(key string, defaultVal interface{}) interface{} {
+	if layer, ok := chain.Layer(key); ok {
+		return chain.Maps[layer][key]
+	}
+	return defaultVal
+}
+
+// HasKey returns true if key is in any of the Dicts, false otherwise.
+func (chain ChainMap) HasKey(key string) bool {
+	_, ok := chain.Layer(key)
+	return ok
+}
+
+// Items returns a list of the [key, value] pairs visible through the chain,
+// sorted by key.
+func (chain ChainMap) Items() []List {
+	keys := chain.sortedKeys()
+	items := make([]List, len(keys))
+	for i, key := range keys {
+		items[i] = List{key, chain.Get(key, nil)}
+	}
+	return items
+}
+
+// Keys returns a sorted list of keys from all the Dicts, without repeats.
+func (chain ChainMap) Keys() List {
+	keys := chain.sortedKeys()
+	list := make(List, len(keys))
+	for i, key := range keys {
+		list[i] = key
+	}
+	return list
+}
+
+// Layer returns index in Maps of the Dict the key is resolved from.
+// It returns false if key is NOT in any of the Dicts.
+func (chain ChainMap) Layer(key string) (int, bool) {
+	for i, dict := range chain.Maps {
+		if dict.HasKey(key) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Len returns the number of keys in all the Dicts, without repeats.
+func (chain ChainMap) Len() int {
+	return len(chain.sortedKeys())
+}
+
+// NewChild returns new ChainMap with child in front of Maps of the chain.
+// If child is nil a new empty Dict is used.
+func (chain ChainMap) NewChild(child Dict) ChainMap {
+	if child == nil {
+		child = NewDict()
+	}
+	return ChainMap{Maps: append([]Dict{child}, chain.Maps...)}
+}
+
+// Parents returns new ChainMap with all Maps of the chain but the first.
+// If there is only one Dict a new empty Dict is used.
+func (chain ChainMap) Parents() ChainMap {
+	if len(chain.Maps) == 0 {
+		return NewChainMap()
+	}
+	return NewChainMap(chain.Maps[1:]...)
+}
+
+// Set sets value for the given key in the first Dict, creating it if it
+// is nil. It returns *IndexError if Maps is empty.
+func (chain ChainMap) Set(key string, value interface{}) error {
+	if len(chain.Maps) == 0 {
+		return &IndexError{Index: 0, Err: ErrIndexOutOfRange}
+	}
+	if chain.Maps[0] == nil {
+		chain.Maps[0] = NewDict()
+	}
+	chain.Maps[0][key] = value
+	return nil
+}
+
+// ToDict returns key-value pairs visible through the chain as a new Dict.
+func (chain ChainMap) ToDict() Dict {
+	out := NewDict()
+	for i := len(chain.Maps) - 1; i >= 0; i-- {
+		out.Update(chain.Maps[i])
+	}
+	return out
+}
+
+// Values returns a list of the values visible through the chain,
+// in order of sorted keys.
+func (chain ChainMap) Values() List {
+	keys := chain.sortedKeys()
+	list := make(List, len(keys))
+	for i, key := range keys {
+		list[i] = chain.Get(key, nil)
+	}
+	return list
+}
+
+//=============================================================================
+
+// sortedKeys returns keys from all the Dicts, sorted and without repeats.
+func (chain ChainMap) sortedKeys() []string {
+	seen := make(map[string]bool)
+	keys := []string{}
+	for _, dict := range chain.Maps {
+		for key := range dict {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
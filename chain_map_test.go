@@ -0,0 +1,129 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+var chainLayerTests = []struct {
+	key   string
+	value interface{}
+	layer int
+	found bool
+}{
+	{"user", "admin", 0, true},
+	{"port", 8080, 1, true},
+	{"color", "red", 2, true},
+	{"missing", nil, -1, false},
+}
+
+func TestChainMapLookup(t *testing.T) {
+	flags := Dict{"user": "admin"}
+	env := Dict{"port": 8080, "user": "env"}
+	defaults := Dict{"color": "red", "port": 80, "user": "guest"}
+	chain := NewChainMap(flags, env, defaults)
+
+	for index, ct := range chainLayerTests {
+		if val := chain.Get(ct.key, nil); val != ct.value {
+			t.Errorf("%d. Get(%v) => %v, want %v", index, ct.key, val,
+				ct.value)
+		}
+		layer, found := chain.Layer(ct.key)
+		if layer != ct.layer || found != ct.found ||
+			chain.HasKey(ct.key) != ct.found {
+			t.Errorf("%d. Layer(%v) => %d, %v, want %d, %v", index, ct.key,
+				layer, found, ct.layer, ct.found)
+		}
+	}
+
+	if keys := chain.Keys(); !reflect.DeepEqual(keys,
+		List{"color", "port", "user"}) || chain.Len() != 3 {
+		t.Errorf("Keys() => %v, want [color port user]", keys)
+	}
+	want := []List{{"color", "red"}, {"port", 8080}, {"user", "admin"}}
+	if items := chain.Items(); !reflect.DeepEqual(items, want) {
+		t.Errorf("Items() => %v, want %v", items, want)
+	}
+	if dict := chain.ToDict(); !dict.IsEqual(Dict{"color": "red",
+		"port": 8080, "user": "admin"}) {
+		t.Errorf("ToDict() => %v", dict)
+	}
+
+	var mapping Mapping = chain
+	if mapping.Keys().IsEqual(Mapping(env).Keys()) {
+		t.Errorf("Mapping.Keys() => %v, want keys of all layers",
+			mapping.Keys())
+	}
+	if mapping.Get("port", 0) != 8080 {
+		t.Errorf("Mapping.Get(port) => %v, want 8080", mapping.Get("port", 0))
+	}
+}
+
+func TestChainMapWrite(t *testing.T) {
+	defaults := Dict{"user": "guest"}
+	chain := NewChainMap().NewChild(nil)
+	chain.Maps = append(chain.Maps[:1], defaults)
+
+	if err := chain.Set("user", "admin"); err != nil {
+		t.Errorf("Set(user) => %v, want nil", err)
+	}
+	if defaults["user"] != "guest" || chain.Get("user", nil) != "admin" {
+		t.Errorf("Set(user) changed %v, want only first Dict changed",
+			defaults)
+	}
+	if err := chain.Delete("user"); err != nil {
+		t.Errorf("Delete(user) => %v, want nil", err)
+	}
+	if err := chain.Delete("user"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete(user) from parent => %v, want %v", err,
+			ErrKeyNotFound)
+	}
+	if chain.Get("user", nil) != "guest" {
+		t.Errorf("Get(user) after Delete => %v, want guest",
+			chain.Get("user", nil))
+	}
+
+	child := chain.NewChild(Dict{"user": "child"})
+	if len(child.Maps) != 3 || child.Get("user", nil) != "child" {
+		t.Errorf("NewChild() => %v", child.Maps)
+	}
+	if parents := child.Parents(); len(parents.Maps) != 2 ||
+		parents.Get("user", nil) != "guest" {
+		t.Errorf("Parents() => %v", parents.Maps)
+	}
+	if root := NewChainMap(defaults).Parents(); len(root.Maps) != 1 ||
+		root.HasKey("user") {
+		t.Errorf("Parents() of single Dict => %v, want [map[]]", root.Maps)
+	}
+}
+
+func TestChainMapEmptyMaps(t *testing.T) {
+	chain := ChainMap{}
+	chain.Clear()
+	if err := chain.Delete("a"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete(a) => %v, want %v", err, ErrKeyNotFound)
+	}
+	if err := chain.Set("a", 1); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("Set(a) => %v, want %v", err, ErrIndexOutOfRange)
+	}
+	if parents := chain.Parents(); len(parents.Maps) != 1 {
+		t.Errorf("Parents() => %v, want [map[]]", parents.Maps)
+	}
+	if chain.Len() != 0 || chain.HasKey("a") {
+		t.Errorf("ChainMap{} => %v, want empty", chain.Items())
+	}
+
+	chain = ChainMap{Maps: []Dict{nil}}
+	if err := chain.Set("a", 1); err != nil || chain.Get("a", nil) != 1 {
+		t.Errorf("Set(a) on nil Dict => %v, %v", err, chain.Maps)
+	}
+}
@@ -0,0 +1,443 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of numbers known to compare functions
+const (
+	intNumber = iota
+	uintNumber
+	floatNumber
+	bigNumber // *big.Int that doesn't fit int64 or uint64
+)
+
+// number holds any Go numeric value (bools included, same as in Python)
+type number struct {
+	kind int
+	i    int64
+	u    uint64
+	f    float64
+	b    *big.Int
+}
+
+//=============================================================================
+
+// Compare returns -1, 0 or +1 depending on whether a is less than, equal to
+// or greater than b, following Python 3 rules:
+// numbers of any Go numeric kind (and bools) compare to each other,
+// strings compare lexicographically, Lists and Tuples compare element-wise.
+// Any other combination returns a *TypeError wrapping ErrUnorderable.
+// NaN is neither less nor greater than anything, so it compares as 0.
+func Compare(a, b interface{}) (int, error) {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return compareNumbers(x, y), nil
+		}
+	}
+
+	if x, ok := toString(a); ok {
+		if y, ok := toString(b); ok {
+			return strings.Compare(x, y), nil
+		}
+	}
+	if x, ok := a.(List); ok {
+		if y, ok := b.(List); ok {
+			return compareLists(x, y)
+		}
+	}
+	if x, ok := a.(Tuple); ok {
+		if y, ok := b.(Tuple); ok {
+			return compareLists(List(x), List(y))
+		}
+	}
+
+	return 0, &TypeError{
+		Op: "<", Values: []interface{}{a, b}, Err: ErrUnorderable}
+}
+
+// Equal reports whether a and b are equal following Python's == rules:
+// numbers of any Go numeric kind (and bools) are equal if they have the same
+// value, Lists and other slices are equal element-wise and Dicts and other
+// maps are equal if they have the same keys with equal values. Tuples are
+// equal only to Tuples. Sets are equal if they have the same elements.
+// NaN is not equal to anything. Values of other types are equal if they
+// are deeply equal.
+func Equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			if (x.kind == floatNumber && math.IsNaN(x.f)) ||
+				(y.kind == floatNumber && math.IsNaN(y.f)) {
+				return false
+			}
+			return compareNumbers(x, y) == 0
+		}
+		return false
+	}
+	if x, ok := toString(a); ok {
+		y, ok := toString(b)
+		return ok && x == y
+	}
+
+	_, aTuple := a.(Tuple)
+	_, bTuple := b.(Tuple)
+	if aTuple != bTuple {
+		return false
+	}
+
+	switch x := a.(type) {
+	case List:
+		if y, ok := b.(List); ok {
+			return equalLists(x, y)
+		}
+	case Tuple:
+		return equalLists(List(x), List(b.(Tuple)))
+	case Dict:
+		if y, ok := b.(Dict); ok {
+			return equalDicts(x, y)
+		}
+	case *HashDict:
+		if y, ok := b.(*HashDict); ok {
+			return x.IsEqual(y)
+		}
+	case Counter:
+		if y, ok := b.(Counter); ok {
+			return x.IsEqual(y.HashDict)
+		}
+	case ValuesView:
+		// Values can repeat, so they are not compared as sets
+		return false
+	case AbstractSet:
+		if _, ok := b.(ValuesView); ok {
+			return false
+		}
+		if y, ok := b.(AbstractSet); ok {
+			return x.Len() == y.Len() && isSubset(x, y)
+		}
+	}
+
+	valA, valB := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isSequence(valA) && isSequence(valB):
+		if valA.Len() != valB.Len() {
+			return false
+		}
+		for i := 0; i < valA.Len(); i++ {
+			if !Equal(valA.Index(i).Interface(), valB.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	case valA.Kind() == reflect.Map && valB.Kind() == reflect.Map:
+		if valA.Len() != valB.Len() {
+			return false
+		}
+		iter := valA.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			if !key.Type().AssignableTo(valB.Type().Key()) {
+				return false
+			}
+			other := valB.MapIndex(key)
+			if !other.IsValid() ||
+				!Equal(iter.Value().Interface(), other.Interface()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if valA.Type() == valB.Type() && valA.Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func equalLists(a, b List) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalDicts(a, b Dict) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, val := range a {
+		other, ok := b[key]
+		if !ok || !Equal(val, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSequence returns true for slices and arrays, byte slices excluded
+// (they are Python bytes, not lists).
+func isSequence(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Slice, reflect.Array:
+		return val.Type().Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// compareLists compares lists element-wise, shorter list is smaller if
+// all its elements are equal to the beginning of the longer one.
+// Same as in Python the first pair of not equal elements decides.
+func compareLists(a, b List) (int, error) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if !Equal(a[i], b[i]) {
+			return Compare(a[i], b[i])
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1, nil
+	case len(a) > len(b):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// sortedBy returns a stably sorted copy of values. Elements are ordered by
+// the result of key (the element itself if key is nil) using Compare.
+// The first comparison error stops sorting and is returned.
+func sortedBy[T any](values []T, key func(T) interface{}, reverse bool) (
+	[]T, error) {
+
+	type keyed struct {
+		key interface{}
+		val T
+	}
+	items := make([]keyed, len(values))
+	for i, val := range values {
+		items[i].val = val
+		if key != nil {
+			items[i].key = key(val)
+		} else {
+			items[i].key = val
+		}
+	}
+
+	var err error
+	sort.SliceStable(items, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		a, b := items[i].key, items[j].key
+		if reverse {
+			a, b = b, a
+		}
+		c, cmpErr := Compare(a, b)
+		if cmpErr != nil {
+			err = cmpErr
+			return false
+		}
+		return c < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, len(items))
+	for i, item := range items {
+		out[i] = item.val
+	}
+	return out, nil
+}
+
+//=============================================================================
+
+// toNumber converts any Go numeric or bool value to number.
+func toNumber(value interface{}) (number, bool) {
+	if value == nil {
+		return number{}, false
+	}
+	if b, ok := value.(*big.Int); ok && b != nil {
+		return bigToNumber(b), true
+	}
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		if val.Bool() {
+			return number{kind: intNumber, i: 1}, true
+		}
+		return number{kind: intNumber}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return number{kind: intNumber, i: val.Int()}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return number{kind: uintNumber, u: val.Uint()}, true
+	case reflect.Float32, reflect.Float64:
+		return number{kind: floatNumber, f: val.Float()}, true
+	}
+	return number{}, false
+}
+
+// bigToNumber converts b to number, as int64 or uint64 if it fits.
+func bigToNumber(b *big.Int) number {
+	switch {
+	case b.IsInt64():
+		return number{kind: intNumber, i: b.Int64()}
+	case b.IsUint64():
+		return number{kind: uintNumber, u: b.Uint64()}
+	}
+	return number{kind: bigNumber, b: b}
+}
+
+// toString returns value as string if it's kind is string.
+func toString(value interface{}) (string, bool) {
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	if value == nil {
+		return "", false
+	}
+	val := reflect.ValueOf(value)
+	if val.Kind() == reflect.String {
+		return val.String(), true
+	}
+	return "", false
+}
+
+// compareNumbers compares numbers exactly, without losing precision
+// when mixing integers and floats.
+func compareNumbers(a, b number) int {
+	switch {
+	case a.kind == intNumber && b.kind == intNumber:
+		return compareInts(a.i, b.i)
+	case a.kind == uintNumber && b.kind == uintNumber:
+		return compareUints(a.u, b.u)
+	case a.kind == intNumber && b.kind == uintNumber:
+		if a.i < 0 {
+			return -1
+		}
+		return compareUints(uint64(a.i), b.u)
+	case a.kind == uintNumber && b.kind == intNumber:
+		if b.i < 0 {
+			return 1
+		}
+		return compareUints(a.u, uint64(b.i))
+	}
+
+	if (a.kind == floatNumber && math.IsNaN(a.f)) ||
+		(b.kind == floatNumber && math.IsNaN(b.f)) {
+		return 0
+	}
+	if a.kind == floatNumber && b.kind == floatNumber {
+		switch {
+		case a.f < b.f:
+			return -1
+		case a.f > b.f:
+			return 1
+		}
+		return 0
+	}
+	return a.bigFloat().Cmp(b.bigFloat())
+}
+
+// bigFloat returns exact representation of number, it must not be NaN.
+func (n number) bigFloat() *big.Float {
+	switch n.kind {
+	case intNumber:
+		return new(big.Float).SetInt64(n.i)
+	case uintNumber:
+		return new(big.Float).SetUint64(n.u)
+	case bigNumber:
+		return new(big.Float).SetInt(n.b)
+	}
+	return new(big.Float).SetFloat64(n.f)
+}
+
+// bigInt returns integer number as *big.Int.
+func (n number) bigInt() *big.Int {
+	switch n.kind {
+	case intNumber:
+		return big.NewInt(n.i)
+	case uintNumber:
+		return new(big.Int).SetUint64(n.u)
+	}
+	return n.b
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// typeName returns Python name of value type, or Go name if there is
+// no Python equivalent.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "NoneType"
+	case *big.Int:
+		return "int"
+	case List:
+		return "list"
+	case Tuple:
+		return "tuple"
+	case Dict, *HashDict:
+		return "dict"
+	case *Set:
+		return "set"
+	case FrozenSet:
+		return "frozenset"
+	case KeysView:
+		return "dict_keys"
+	case ValuesView:
+		return "dict_values"
+	case ItemsView:
+		return "dict_items"
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "str"
+	}
+	return reflect.TypeOf(value).String()
+}
@@ -0,0 +1,112 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+//=============================================================================
+
+var compareTests = []struct {
+	a       interface{}
+	b       interface{}
+	out     int
+	isError bool
+}{
+	{1, 2, -1, false},
+	{2, 1, 1, false},
+	{1, 1.0, 0, false},
+	{int8(-1), uint64(math.MaxUint64), -1, false},
+	{uint64(math.MaxUint64), -1, 1, false},
+	{int64(1 << 53), float64(1<<53) + 1, 0, false},
+	{int64(1<<53 + 1), float64(1 << 53), 1, false},
+	{true, 0.5, 1, false},
+	{math.Inf(-1), math.MinInt64, -1, false},
+	{math.NaN(), 1, 0, false},
+	{"a", "b", -1, false},
+	{"ab", "a", 1, false},
+	{List{1, 2}, List{1, 3}, -1, false},
+	{List{1, 2}, List{1}, 1, false},
+	{List{1, "a"}, List{1, "a"}, 0, false},
+	{List{1, "a"}, List{2, 3}, -1, false},
+	{"1", 1, 0, true},
+	{nil, nil, 0, true},
+	{List{1}, 1, 0, true},
+	{Dict{}, Dict{}, 0, true},
+	{List{Dict{}}, List{Dict{}}, 0, false},
+	{List{Dict{}, 1}, List{Dict{}, 2}, -1, false},
+}
+
+func TestCompare(t *testing.T) {
+	for index, ct := range compareTests {
+		out, err := Compare(ct.a, ct.b)
+		if ct.isError {
+			if !errors.Is(err, ErrUnorderable) {
+				t.Errorf("%d. Compare(%v, %v) => error %v, want %v",
+					index, ct.a, ct.b, err, ErrUnorderable)
+			}
+			continue
+		}
+		if out != ct.out || err != nil {
+			t.Errorf("%d. Compare(%v, %v) => %d, %v, want %d, nil",
+				index, ct.a, ct.b, out, err, ct.out)
+		}
+	}
+}
+
+//=============================================================================
+
+var equalTests = []struct {
+	a   interface{}
+	b   interface{}
+	out bool
+}{
+	{1, 1, true},
+	{1, 1.0, true},
+	{int64(2), uint8(2), true},
+	{true, 1, true},
+	{1, 2, false},
+	{math.NaN(), math.NaN(), false},
+	{"one", "one", true},
+	{"1", 1, false},
+	{nil, nil, true},
+	{nil, 0, false},
+	{List{1, List{2.0}}, List{1.0, List{2}}, true},
+	{List{1, 2}, List{1}, false},
+	{Dict{"a": List{1}}, Dict{"a": List{1.0}}, true},
+	{Dict{"a": 1}, Dict{"b": 1}, false},
+	{List{1, 2}, []interface{}{1.0, 2}, true},
+	{[]int{1, 2}, List{1, 2}, true},
+	{map[string]interface{}{"a": 1}, Dict{"a": 1.0}, true},
+	{[]byte("ab"), []byte("ab"), true},
+	{List{}, Dict{}, false},
+	{struct{ a int }{1}, struct{ a int }{1}, true},
+	{struct{ X interface{} }{List{1}}, struct{ X interface{} }{List{1}}, true},
+	{struct{ X interface{} }{List{1}}, struct{ X interface{} }{List{2}}, false},
+}
+
+func TestEqual(t *testing.T) {
+	for index, et := range equalTests {
+		if out := Equal(et.a, et.b); out != et.out {
+			t.Errorf("%d. Equal(%v, %v) => %v, want %v",
+				index, et.a, et.b, out, et.out)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestTypeErrorMessage(t *testing.T) {
+	_, err := Compare("one", 1)
+	want := "unorderable types: str < int"
+	if err == nil || err.Error() != want {
+		t.Errorf("Compare(\"one\", 1) => error %v, want %v", err, want)
+	}
+}
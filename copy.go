@@ -0,0 +1,210 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"reflect"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// DeepCopier is implemented by values that make their own deep copies,
+// same as Python's __deepcopy__. DeepCopyMemo must return a value of
+// the same type, nested values should be copied with memo.Copy.
+type DeepCopier interface {
+	DeepCopyMemo(memo *CopyMemo) interface{}
+}
+
+// CopyMemo remembers values already copied by a single deep copy, so shared
+// values are copied once and cycles don't loop forever, same as memo
+// dict of Python's copy.deepcopy. The zero value is ready to use.
+type CopyMemo struct {
+	copies map[container.ID]reflect.Value
+}
+
+//=============================================================================
+
+// DeepCopy returns a deep copy of value, same as Python's copy.deepcopy.
+// Lists, Dicts, Go slices, arrays, maps, exported struct fields and
+// containers of this package are copied recursively, DeepCopier values
+// copy themselves. Other values, like pointers, are shared.
+func DeepCopy(value interface{}) interface{} {
+	return new(CopyMemo).Copy(value)
+}
+
+// Copy returns a deep copy of value using the memo, for use in DeepCopier
+// implementations.
+func (memo *CopyMemo) Copy(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return memo.copyValue(reflect.ValueOf(value)).Interface()
+}
+
+// Store remembers that copy is the deep copy of original. DeepCopier
+// implementations of containers should call it before copying their
+// elements, so a container holding itself is copied correctly.
+func (memo *CopyMemo) Store(original, copy interface{}) {
+	if id, ok := container.Identity(reflect.ValueOf(original)); ok {
+		memo.store(id, reflect.ValueOf(copy))
+	}
+}
+
+//=============================================================================
+
+// Copy returns a shallow copy of the list, same as Python's l.copy().
+func (list List) Copy() List {
+	out := make(List, len(list))
+	copy(out, list)
+	return out
+}
+
+// DeepCopy returns a deep copy of the list, see DeepCopy.
+func (list List) DeepCopy() List {
+	return DeepCopy(list).(List)
+}
+
+// Copy returns a shallow copy of the dictionary, same as Python's d.copy().
+func (dict Dict) Copy() Dict {
+	out := make(Dict, len(dict))
+	out.Update(dict)
+	return out
+}
+
+// DeepCopy returns a deep copy of the dictionary, see DeepCopy.
+func (dict Dict) DeepCopy() Dict {
+	return DeepCopy(dict).(Dict)
+}
+
+//=============================================================================
+
+// DeepCopyMemo returns a deep copy of the counter as Counter.
+func (counter Counter) DeepCopyMemo(memo *CopyMemo) interface{} {
+	return Counter{memo.Copy(counter.HashDict).(*HashDict)}
+}
+
+// DeepCopyMemo returns a deep copy of the deque as *Deque.
+func (deque *Deque) DeepCopyMemo(memo *CopyMemo) interface{} {
+	out := NewDeque(deque.MaxLen())
+	memo.Store(deque, out)
+	for _, val := range deque.All() {
+		out.Append(memo.Copy(val))
+	}
+	return out
+}
+
+// DeepCopyMemo returns a deep copy of the dictionary as *HashDict,
+// keys are hashable so they are not copied.
+func (dict *HashDict) DeepCopyMemo(memo *CopyMemo) interface{} {
+	out := NewHashDict()
+	memo.Store(dict, out)
+	for key, val := range dict.All() {
+		out.Set(key, memo.Copy(val))
+	}
+	return out
+}
+
+// DeepCopyMemo returns a deep copy of the dictionary as *OrderedDict.
+func (dict *OrderedDict) DeepCopyMemo(memo *CopyMemo) interface{} {
+	out := NewOrderedDict()
+	memo.Store(dict, out)
+	for key, val := range dict.All() {
+		out.Set(key, memo.Copy(val))
+	}
+	return out
+}
+
+// DeepCopyMemo returns a copy of the set as *Set, elements are hashable
+// so they are not copied.
+func (set *Set) DeepCopyMemo(memo *CopyMemo) interface{} {
+	return set.Copy()
+}
+
+//=============================================================================
+
+func (memo *CopyMemo) store(id container.ID, val reflect.Value) {
+	if memo.copies == nil {
+		memo.copies = make(map[container.ID]reflect.Value)
+	}
+	memo.copies[id] = val
+}
+
+// copyValue returns a deep copy of val with the same type.
+func (memo *CopyMemo) copyValue(val reflect.Value) reflect.Value {
+	id, hasID := container.Identity(val)
+	if hasID {
+		if out, ok := memo.copies[id]; ok {
+			return out
+		}
+	}
+
+	if val.Kind() == reflect.Pointer && val.IsNil() {
+		return reflect.Zero(val.Type())
+	}
+	// Interfaces are unwrapped first, so nil pointers in them are found
+	if val.Kind() != reflect.Interface && val.CanInterface() {
+		if copier, ok := val.Interface().(DeepCopier); ok {
+			out := reflect.New(val.Type()).Elem()
+			if copied := copier.DeepCopyMemo(memo); copied != nil {
+				out.Set(reflect.ValueOf(copied))
+			}
+			if hasID {
+				memo.store(id, out)
+			}
+			return out
+		}
+	}
+
+	switch val.Kind() {
+	case reflect.Interface:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.New(val.Type()).Elem()
+		out.Set(memo.copyValue(val.Elem()))
+		return out
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		if hasID {
+			memo.store(id, out)
+		}
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(memo.copyValue(val.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(val.Type()).Elem()
+		for i := 0; i < val.Len(); i++ {
+			out.Index(i).Set(memo.copyValue(val.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		out := reflect.MakeMapWithSize(val.Type(), val.Len())
+		memo.store(id, out)
+		iter := val.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), memo.copyValue(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(val.Type()).Elem()
+		out.Set(val)
+		for i := 0; i < val.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(memo.copyValue(val.Field(i)))
+			}
+		}
+		return out
+	}
+	return val
+}
@@ -0,0 +1,134 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"testing"
+)
+
+//=============================================================================
+
+func TestCopy(t *testing.T) {
+	inner := List{1}
+	list := List{inner, "a"}
+	dict := Dict{"list": inner}
+
+	listCopy, dictCopy := list.Copy(), dict.Copy()
+	listCopy[1] = "b"
+	dictCopy["new"] = 1
+	inner[0] = 2
+
+	if list[1] != "a" || dict.HasKey("new") {
+		t.Errorf("changing copy changed original: %v, %v", list, dict)
+	}
+	if listCopy[0].(List)[0] != 2 || dictCopy["list"].(List)[0] != 2 {
+		t.Errorf("Copy() copied nested List: %v, %v", listCopy, dictCopy)
+	}
+	if out := (List{}).Copy(); out == nil || len(out) != 0 {
+		t.Errorf("List{}.Copy() => %#v, want List{}", out)
+	}
+}
+
+type copyCounter struct {
+	copies *int
+	value  List
+}
+
+func (c copyCounter) DeepCopyMemo(memo *CopyMemo) interface{} {
+	*c.copies++
+	return copyCounter{c.copies, memo.Copy(c.value).(List)}
+}
+
+func TestDeepCopy(t *testing.T) {
+	shared := List{1}
+	nums := []int{1, 2}
+	copies := 0
+	dict := Dict{
+		"a":      shared,
+		"b":      shared,
+		"nums":   nums,
+		"map":    map[string][]int{"x": nums},
+		"nested": Dict{"c": List{Dict{"d": 1}}},
+		"custom": copyCounter{&copies, shared},
+		"struct": struct{ L List }{shared},
+		"tuple":  Tuple{shared},
+	}
+
+	out := dict.DeepCopy()
+	shared[0] = 2
+	nums[0] = 2
+	dict["nested"].(Dict)["c"].(List)[0].(Dict)["d"] = 2
+
+	if !Equal(out, Dict{"a": List{1}, "b": List{1}, "nums": []int{1, 2},
+		"map":    map[string][]int{"x": {1, 2}},
+		"nested": Dict{"c": List{Dict{"d": 1}}},
+		"custom": copyCounter{&copies, List{1}},
+		"struct": struct{ L List }{List{1}}, "tuple": Tuple{List{1}}}) {
+		t.Errorf("DeepCopy() => %v, changed with original", out)
+	}
+
+	out["a"].(List)[0] = 3
+	if out["b"].(List)[0] != 3 || out["struct"].(struct{ L List }).L[0] != 3 {
+		t.Errorf("DeepCopy() didn't keep shared List: %v", out)
+	}
+	out["nums"].([]int)[1] = 3
+	if out["map"].(map[string][]int)["x"][1] != 3 {
+		t.Errorf("DeepCopy() didn't keep shared slice: %v", out)
+	}
+	if copies != 1 {
+		t.Errorf("DeepCopier called %d times, want 1", copies)
+	}
+}
+
+func TestDeepCopyCycle(t *testing.T) {
+	list := List{1, nil}
+	list[1] = list
+	dict := Dict{"self": nil, "list": list}
+	dict["self"] = dict
+
+	listCopy := list.DeepCopy()
+	listCopy[0] = 2
+	if list[0] != 1 || listCopy[1].(List)[0] != 2 {
+		t.Errorf("DeepCopy() of cyclic List didn't keep the cycle")
+	}
+
+	dictCopy := dict.DeepCopy()
+	dictCopy["new"] = 1
+	if !dictCopy["self"].(Dict).HasKey("new") || dict.HasKey("new") {
+		t.Errorf("DeepCopy() of cyclic Dict didn't keep the cycle")
+	}
+
+	hashDict := NewHashDict()
+	hashDict.Set(Tuple{1}, hashDict)
+	hashCopy := DeepCopy(hashDict).(*HashDict)
+	if self, _ := hashCopy.Get(Tuple{1}, nil); self != hashCopy {
+		t.Errorf("DeepCopy() of cyclic HashDict didn't keep the cycle")
+	}
+
+	deque := DequeFromList(List{List{1}}, Omit)
+	dequeCopy := DeepCopy(deque).(*Deque)
+	val, _ := dequeCopy.At(0)
+	val.(List)[0] = 2
+	if orig, _ := deque.At(0); orig.(List)[0] != 1 {
+		t.Errorf("DeepCopy() of Deque shares elements: %v", deque)
+	}
+}
+
+func TestDeepCopyNilPointers(t *testing.T) {
+	list := List{(*HashDict)(nil), (*Deque)(nil), (*OrderedDict)(nil),
+		(*Set)(nil)}
+	out := DeepCopy(list).(List)
+	for index, val := range out {
+		if val != list[index] {
+			t.Errorf("%d. DeepCopy(%#v) => %#v, want the same nil pointer",
+				index, list[index], val)
+		}
+	}
+	if out := DeepCopy((*Deque)(nil)); out != (*Deque)(nil) {
+		t.Errorf("DeepCopy((*Deque)(nil)) => %#v, want nil *Deque", out)
+	}
+}
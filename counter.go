@@ -0,0 +1,248 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// Counter is a HashDict for counting hashable values, same as Python's
+// collections.Counter. Keys are counted values and values are int counts.
+// Missing keys count as zero. Same as in Python 1, 1.0 and true are
+// counted as the same key and "1" as a different one. All HashDict
+// methods, like Keys, Items and IsEqual, work on Counter. Use NewCounter
+// to create a Counter, the zero Counter has no HashDict.
+//
+//	c, _ := listdict.CounterFromList(listdict.List{"a", 1, "a"})
+//	c.Count("a")       // 2
+//	c.Count("x")       // 0
+//	c.MostCommon(1)    // [{a 2}]
+type Counter struct {
+	*HashDict
+}
+
+// NewCounter returns new empty Counter.
+func NewCounter() Counter {
+	return Counter{HashDict: NewHashDict()}
+}
+
+// CounterFromList returns new Counter with counts of the list elements.
+// It returns *TypeError if any element is unhashable.
+func CounterFromList(list List) (Counter, error) {
+	counter := NewCounter()
+	if err := counter.Update(list); err != nil {
+		return Counter{}, err
+	}
+	return counter, nil
+}
+
+// CounterFromSeq returns new Counter with counts of the values from seq.
+// It returns *TypeError if any value is unhashable.
+//
+//	words, _ := listdict.CounterFromSeq(strings.FieldsSeq(text))
+func CounterFromSeq[T any](seq iter.Seq[T]) (Counter, error) {
+	list := List{}
+	for value := range seq {
+		list = append(list, value)
+	}
+	return CounterFromList(list)
+}
+
+//=============================================================================
+
+// Add returns a new Counter with counts of both counters added,
+// same as Python's c + other. Keys with count <= 0 are left out.
+func (counter Counter) Add(other Counter) Counter {
+	return counter.combine(other, func(a, b int) int { return a + b })
+}
+
+// And returns a new Counter with minimum of counts, same as Python's
+// c & other. Keys with count <= 0 are left out.
+func (counter Counter) And(other Counter) Counter {
+	return counter.combine(other, func(a, b int) int { return min(a, b) })
+}
+
+// Count returns count of the given key, 0 if key is NOT in the counter
+// or is unhashable. Counts of other numeric types, e.g. float64 from JSON,
+// are converted to int, fractions are truncated.
+func (counter Counter) Count(key interface{}) int {
+	value, _ := counter.Get(key, 0)
+	if count, ok := value.(int); ok {
+		return count
+	}
+	n, ok := toNumber(value)
+	if !ok {
+		return 0
+	}
+	switch n.kind {
+	case intNumber:
+		return int(n.i)
+	case uintNumber:
+		return int(n.u)
+	}
+	return int(n.float())
+}
+
+// Elements returns a list with each key repeated as many times as its
+// count, keys with count <= 0 are left out. Keys are in insertion order.
+//
+//	c, _ := listdict.CounterFromList(listdict.List{"b", "a", "b"})
+//	c.Elements() => [b b a]
+func (counter Counter) Elements() List {
+	list := List{}
+	for key := range counter.IterKeys() {
+		for i := 0; i < counter.Count(key); i++ {
+			list = append(list, key)
+		}
+	}
+	return list
+}
+
+// MarshalJSON returns the counter as JSON object with keys in insertion
+// order, keys are converted to strings as in Dumps.
+func (counter Counter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(counter)
+}
+
+// MostCommon returns n keys with the largest counts, from the most common
+// to the least. Keys with equal counts are in insertion order, same as in
+// Python. Use Omit as n to get all keys.
+func (counter Counter) MostCommon(n int) []Pair[interface{}, int] {
+	pairs := make([]Pair[interface{}, int], 0, counter.Len())
+	for key := range counter.IterKeys() {
+		pairs = append(pairs, Pair[interface{}, int]{key, counter.Count(key)})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Value > pairs[j].Value
+	})
+	if n != Omit && n < len(pairs) {
+		pairs = pairs[:max(n, 0)]
+	}
+	return pairs
+}
+
+// Or returns a new Counter with maximum of counts, same as Python's
+// c | other. Keys with count <= 0 are left out.
+func (counter Counter) Or(other Counter) Counter {
+	return counter.combine(other, func(a, b int) int { return max(a, b) })
+}
+
+// String returns key-count pairs as string, from the most common key to
+// the least.
+//
+//	c.String() => "a: 2, b: 1"
+func (counter Counter) String() string {
+	out := make([]string, 0, counter.Len())
+	for _, pair := range counter.MostCommon(Omit) {
+		out = append(out, fmt.Sprintf("%v: %v", pair.Key, pair.Value))
+	}
+	return strings.Join(out, ", ")
+}
+
+// Sub returns a new Counter with counts of other subtracted,
+// same as Python's c - other. Keys with count <= 0 are left out.
+func (counter Counter) Sub(other Counter) Counter {
+	return counter.combine(other, func(a, b int) int { return a - b })
+}
+
+// Subtract subtracts one from the count of each list element. Counts can
+// become zero or negative. It returns *TypeError, without changing
+// the counter, if any element is unhashable.
+func (counter Counter) Subtract(list List) error {
+	return counter.update(list, -1)
+}
+
+// SubtractCounter subtracts counts of other from the counter. Counts can
+// become zero or negative.
+func (counter Counter) SubtractCounter(other Counter) {
+	for key := range other.IterKeys() {
+		counter.add(key, -other.Count(key))
+	}
+}
+
+// Total returns sum of all counts.
+func (counter Counter) Total() int {
+	total := 0
+	for key := range counter.IterKeys() {
+		total += counter.Count(key)
+	}
+	return total
+}
+
+// UnmarshalJSON sets the counter from JSON object with number values,
+// keys are added in sorted order.
+func (counter *Counter) UnmarshalJSON(data []byte) error {
+	var counts map[string]int
+	if err := json.Unmarshal(data, &counts); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	*counter = NewCounter()
+	for _, key := range keys {
+		counter.Set(key, counts[key])
+	}
+	return nil
+}
+
+// Update adds one to the count of each list element. It returns
+// *TypeError, without changing the counter, if any element is
+// unhashable.
+func (counter Counter) Update(list List) error {
+	return counter.update(list, 1)
+}
+
+// UpdateCounter adds counts of other to the counter.
+func (counter Counter) UpdateCounter(other Counter) {
+	for key := range other.IterKeys() {
+		counter.add(key, other.Count(key))
+	}
+}
+
+//=============================================================================
+
+// add adds n to the count of hashable key.
+func (counter Counter) add(key interface{}, n int) {
+	counter.Set(key, counter.Count(key)+n)
+}
+
+// update adds n to the count of each list element.
+func (counter Counter) update(list List, n int) error {
+	for _, value := range list {
+		if _, err := hashKey(value); err != nil {
+			return err
+		}
+	}
+	for _, value := range list {
+		counter.add(value, n)
+	}
+	return nil
+}
+
+// combine returns a new Counter with f applied to counts of every key from
+// both counters, only positive results are kept.
+func (counter Counter) combine(other Counter, f func(a, b int) int) Counter {
+	out := NewCounter()
+	for _, keys := range []iter.Seq[interface{}]{counter.IterKeys(),
+		other.IterKeys()} {
+
+		for key := range keys {
+			if count := f(counter.Count(key), other.Count(key)); count > 0 {
+				out.Set(key, count)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// counterOf returns Counter with keys and counts given in pairs,
+// in given order.
+func counterOf(pairs ...interface{}) Counter {
+	counter := NewCounter()
+	for i := 0; i < len(pairs); i += 2 {
+		counter.Set(pairs[i], pairs[i+1])
+	}
+	return counter
+}
+
+//=============================================================================
+
+func TestCounterFromList(t *testing.T) {
+	counter, err := CounterFromList(List{"a", "b", "a", 1, 1.0, "1", "a",
+		Tuple{1, 2}})
+
+	want := counterOf("a", 3, "b", 1, 1, 2, "1", 1, Tuple{1, 2}, 1)
+	if !counter.IsEqual(want.HashDict) || err != nil {
+		t.Errorf("CounterFromList() => %v, %v, want %v", counter, err, want)
+	}
+	if n := counter.Count("x"); n != 0 {
+		t.Errorf("Count(x) => %d, want 0", n)
+	}
+	if n := counter.Count(true); n != 2 {
+		t.Errorf("Count(true) => %d, want 2", n)
+	}
+	if n := counter.Count(List{1}); n != 0 {
+		t.Errorf("Count([1]) => %d, want 0", n)
+	}
+	if n := counter.Total(); n != 8 {
+		t.Errorf("Total() => %d, want 8", n)
+	}
+	if keys := counter.Keys(); !reflect.DeepEqual(keys,
+		List{"a", "b", 1, "1", Tuple{1, 2}}) {
+		t.Errorf("Keys() => %v, want [a b 1 1 (1, 2)]", keys)
+	}
+
+	seq, err := CounterFromSeq(slices.Values([]int{1, 2, 1}))
+	if !seq.IsEqual(counterOf(1, 2, 2, 1).HashDict) || err != nil {
+		t.Errorf("CounterFromSeq() => %v, %v, want 1: 2, 2: 1", seq, err)
+	}
+
+	_, err = CounterFromList(List{1, List{2}})
+	if !errors.Is(err, ErrUnhashable) {
+		t.Errorf("CounterFromList([1 [2]]) => %v, want %v", err,
+			ErrUnhashable)
+	}
+	if err := counter.Update(List{"a", Dict{}}); !errors.Is(err,
+		ErrUnhashable) || counter.Count("a") != 3 {
+		t.Errorf("Update([a {}]) => %v, count of a %d, want %v, 3", err,
+			counter.Count("a"), ErrUnhashable)
+	}
+}
+
+func TestCounterCount(t *testing.T) {
+	counter := counterOf("a", int64(2), "b", 3.0, "c", uint8(1), "d", "x")
+	want := []int{2, 3, 1, 0}
+	for index, key := range []string{"a", "b", "c", "d"} {
+		if n := counter.Count(key); n != want[index] {
+			t.Errorf("%d. Count(%s) => %d, want %d", index, key, n,
+				want[index])
+		}
+	}
+	if n := counter.Total(); n != 6 {
+		t.Errorf("Total() => %d, want 6", n)
+	}
+}
+
+var mostCommonTests = []struct {
+	n   int
+	out []Pair[interface{}, int]
+}{
+	{1, []Pair[interface{}, int]{{"a", 3}}},
+	{3, []Pair[interface{}, int]{{"a", 3}, {2, 2}, {"b", 2}}},
+	{0, []Pair[interface{}, int]{}},
+	{Omit, []Pair[interface{}, int]{{"a", 3}, {2, 2}, {"b", 2}, {"d", 1}}},
+}
+
+func TestCounterMostCommon(t *testing.T) {
+	counter, _ := CounterFromList(List{"d", 2, "b", "a", 2, "b", "a", "a"})
+	for index, mt := range mostCommonTests {
+		if out := counter.MostCommon(mt.n); !reflect.DeepEqual(out, mt.out) {
+			t.Errorf("%d. MostCommon(%d) => %v, want %v",
+				index, mt.n, out, mt.out)
+		}
+	}
+	if s := counter.String(); s != "a: 3, 2: 2, b: 2, d: 1" {
+		t.Errorf("String() => %v, want a: 3, 2: 2, b: 2, d: 1", s)
+	}
+}
+
+func TestCounterUpdate(t *testing.T) {
+	counter, _ := CounterFromList(List{"a", "b"})
+	counter.Update(List{"a", 3})
+	counter.Subtract(List{"b", "b"})
+	counter.UpdateCounter(counterOf(3.0, 2))
+	counter.SubtractCounter(counterOf("d", 1))
+
+	want := counterOf("a", 2, "b", -1, 3, 3, "d", -1)
+	if !counter.IsEqual(want.HashDict) {
+		t.Errorf("counter => %v, want %v", counter, want)
+	}
+	if elements := counter.Elements(); !reflect.DeepEqual(elements,
+		List{"a", "a", 3, 3, 3}) {
+		t.Errorf("Elements() => %v, want [a a 3 3 3]", elements)
+	}
+}
+
+var counterOperationTests = []struct {
+	name string
+	op   func(a, b Counter) Counter
+	out  Counter
+}{
+	{"Add", Counter.Add, counterOf("a", 4, "b", 1, "c", 2)},
+	{"Sub", Counter.Sub, counterOf("a", 2, "b", 1, "d", 1)},
+	{"And", Counter.And, counterOf("a", 1)},
+	{"Or", Counter.Or, counterOf("a", 3, "b", 1, "c", 2)},
+}
+
+func TestCounterOperations(t *testing.T) {
+	a := counterOf("a", 3, "b", 1, "c", 0)
+	b := counterOf("a", 1, "b", 0, "c", 2, "d", -1)
+	for index, ct := range counterOperationTests {
+		out := ct.op(a, b)
+		if !out.IsEqual(ct.out.HashDict) ||
+			!reflect.DeepEqual(out.Keys(), ct.out.Keys()) {
+			t.Errorf("%d. %s => %v, want %v", index, ct.name, out, ct.out)
+		}
+	}
+}
+
+func TestCounterJSON(t *testing.T) {
+	counter, _ := CounterFromList(List{"b", "a", "a", 1})
+	data, err := json.Marshal(counter)
+	if err != nil || string(data) != `{"b":1,"a":2,"1":1}` {
+		t.Errorf("json.Marshal() => %s, %v, want {\"b\":1,\"a\":2,\"1\":1}",
+			data, err)
+	}
+
+	var decoded Counter
+	want := counterOf("1", 1, "a", 2, "b", 1)
+	if err := json.Unmarshal(data, &decoded); err != nil ||
+		!decoded.IsEqual(want.HashDict) {
+		t.Errorf("json.Unmarshal(%s) => %v, %v, want %v", data, decoded,
+			err, want)
+	}
+}
+
+func TestCounterCopy(t *testing.T) {
+	counter := counterOf("a", 2, 1, 1)
+	copied, ok := DeepCopy(counter).(Counter)
+	if !ok || !Equal(copied, counter) {
+		t.Fatalf("DeepCopy(%v) => %v, want equal Counter", counter, copied)
+	}
+	copied.Update(List{"a"})
+	if counter.Count("a") != 2 || Equal(copied, counter) {
+		t.Errorf("Update() of copy changed %v", counter)
+	}
+}
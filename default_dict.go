@@ -0,0 +1,128 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+)
+
+// DefaultDict is a Dict that creates values for missing keys, same as
+// Python's collections.defaultdict. At calls Factory for a missing key and
+// stores the result, all Dict methods (Get, HasKey, ...) don't.
+//
+//	d := listdict.NewDefaultDict(listdict.ListFactory)
+//	d.Append("fruits", "apple")
+//	d.Append("fruits", "pear")
+//	d["fruits"] // [apple pear]
+type DefaultDict struct {
+	Dict
+	Factory func() interface{} // creates value for a missing key, can be nil
+}
+
+// NewDefaultDict returns new empty DefaultDict using factory to create
+// values for missing keys.
+func NewDefaultDict(factory func() interface{}) DefaultDict {
+	return DefaultDict{Dict: NewDict(), Factory: factory}
+}
+
+// ListFactory returns new empty List, for use as DefaultDict.Factory.
+func ListFactory() interface{} {
+	return List{}
+}
+
+// DictFactory returns new empty Dict, for use as DefaultDict.Factory.
+func DictFactory() interface{} {
+	return NewDict()
+}
+
+// IntFactory returns 0, for use as DefaultDict.Factory to count values.
+func IntFactory() interface{} {
+	return 0
+}
+
+//=============================================================================
+
+// Append adds values to the end of the List stored under key, a missing
+// key is created with Factory first. It returns *TypeError if the value
+// is not a List.
+func (dict DefaultDict) Append(key string, values ...interface{}) error {
+	val, err := dict.At(key)
+	if err != nil {
+		return err
+	}
+	list, ok := val.(List)
+	if !ok {
+		return &TypeError{Values: []interface{}{val}, Err: ErrWrongType}
+	}
+	dict.Dict[key] = append(list, values...)
+	return nil
+}
+
+// At returns value for the given key. If key is NOT in the dictionary
+// it is set to the result of Factory, same as Python's d[key].
+// It returns *KeyError if key is missing and Factory is nil.
+func (dict DefaultDict) At(key string) (interface{}, error) {
+	if val, ok := dict.Dict[key]; ok {
+		return val, nil
+	}
+	if dict.Factory == nil {
+		return nil, &KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+	val := dict.Factory()
+	dict.Dict[key] = val
+	return val, nil
+}
+
+// Increment adds n to the int stored under key, a missing key is created
+// with Factory first. It returns *TypeError if the value is not an int.
+func (dict DefaultDict) Increment(key string, n int) error {
+	val, err := dict.At(key)
+	if err != nil {
+		return err
+	}
+	count, ok := val.(int)
+	if !ok {
+		return &TypeError{Values: []interface{}{val}, Err: ErrWrongType}
+	}
+	dict.Dict[key] = count + n
+	return nil
+}
+
+// MarshalJSON returns the dictionary as JSON object, same as its Dict.
+func (dict DefaultDict) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dict.Dict)
+}
+
+// Path returns the Dict found by following keys from the dictionary,
+// missing keys on the way are set to new empty Dicts (whatever Factory is).
+// It returns *TypeError if a value on the way is not a Dict.
+//
+//	d := listdict.NewDefaultDict(nil)
+//	d.Path("a", "b")      // d = {a: {b: {}}}
+//	p, _ := d.Path("a")
+//	p["c"] = 1            // d = {a: {b: {}, c: 1}}
+func (dict DefaultDict) Path(keys ...string) (Dict, error) {
+	current := dict.Dict
+	for _, key := range keys {
+		val, ok := current[key]
+		if !ok {
+			next := NewDict()
+			current[key] = next
+			current = next
+			continue
+		}
+		switch next := val.(type) {
+		case Dict:
+			current = next
+		case DefaultDict:
+			current = next.Dict
+		default:
+			return nil, &TypeError{Values: []interface{}{val}, Err: ErrWrongType}
+		}
+	}
+	return current, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+//=============================================================================
+
+func TestDefaultDictAt(t *testing.T) {
+	dict := NewDefaultDict(ListFactory)
+
+	if dict.HasKey("a") || dict.Get("a", nil) != nil || len(dict.Dict) != 0 {
+		t.Errorf("HasKey/Get triggered factory, dict => %v", dict.Dict)
+	}
+	if val, err := dict.At("a"); !Equal(val, List{}) || err != nil {
+		t.Errorf("At(a) => %v, %v, want [], nil", val, err)
+	}
+	if !dict.HasKey("a") {
+		t.Errorf("At(a) didn't set key a")
+	}
+
+	dict.Append("b", 1)
+	dict.Append("b", 2, 3)
+	if !Equal(dict.Dict["b"], List{1, 2, 3}) {
+		t.Errorf("Append(b, ...) => %v, want [1 2 3]", dict.Dict["b"])
+	}
+
+	dict.Dict["c"] = "text"
+	if err := dict.Append("c", 1); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Append(c) => %v, want %v", err, ErrWrongType)
+	}
+
+	noFactory := NewDefaultDict(nil)
+	if _, err := noFactory.At("a"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("At(a) without factory => %v, want %v", err,
+			ErrKeyNotFound)
+	}
+}
+
+func TestDefaultDictIncrement(t *testing.T) {
+	dict := NewDefaultDict(IntFactory)
+	for _, word := range []string{"a", "b", "a"} {
+		if err := dict.Increment(word, 1); err != nil {
+			t.Errorf("Increment(%v) => %v", word, err)
+		}
+	}
+	if !dict.IsEqual(Dict{"a": 2, "b": 1}) {
+		t.Errorf("Increment() => %v, want map[a:2 b:1]", dict.Dict)
+	}
+
+	lists := NewDefaultDict(DictFactory)
+	if err := lists.Increment("a", 1); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Increment(a) on Dict value => %v, want %v", err,
+			ErrWrongType)
+	}
+}
+
+func TestDefaultDictPath(t *testing.T) {
+	dict := NewDefaultDict(ListFactory)
+
+	leaf, err := dict.Path("a", "b", "c")
+	if err != nil {
+		t.Fatalf("Path(a, b, c) => %v", err)
+	}
+	leaf["x"] = 1
+	if same, _ := dict.Path("a", "b"); !Equal(same,
+		Dict{"c": Dict{"x": 1}}) {
+		t.Errorf("Path(a, b) => %v, want map[c:map[x:1]]", same)
+	}
+
+	dict.Dict["list"] = List{}
+	if _, err := dict.Path("list", "a"); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Path(list, a) => %v, want %v", err, ErrWrongType)
+	}
+
+	data, err := json.Marshal(dict)
+	if want := `{"a":{"b":{"c":{"x":1}}},"list":[]}`; err != nil ||
+		string(data) != want {
+		t.Errorf("json.Marshal() => %s, %v, want %s", data, err, want)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"iter"
+)
+
+// Deque is a double-ended queue backed by a ring buffer, same as Python's
+// collections.deque. Adding and removing elements at both ends is O(1).
+// If the deque has a maximum length, adding elements to a full deque
+// silently removes elements from the opposite end.
+//
+//	history := listdict.NewDeque(3)
+//	history.Extend(listdict.List{1, 2, 3, 4}) // history = [2 3 4]
+//
+// The zero value is an empty deque without limit.
+type Deque struct {
+	buf     []interface{}
+	head    int // index of the first element in buf
+	length  int
+	maxLen  int // maximum length, used if bounded
+	bounded bool
+}
+
+var (
+	// ErrRemoveFromEmptyDeque is returned when user want to remove element
+	// from empty deque
+	ErrRemoveFromEmptyDeque = errors.
+		New("Trying to remove element from empty deque")
+)
+
+// NewDeque returns new empty Deque with maximum length maxLen.
+// Use Omit (or any negative number) for a deque without limit.
+func NewDeque(maxLen int) *Deque {
+	if maxLen < 0 {
+		return &Deque{}
+	}
+	return &Deque{maxLen: maxLen, bounded: true}
+}
+
+// DequeFromList returns new Deque with elements of the list and maximum
+// length maxLen, see NewDeque.
+func DequeFromList(list List, maxLen int) *Deque {
+	deque := NewDeque(maxLen)
+	deque.Extend(list)
+	return deque
+}
+
+//=============================================================================
+
+// All returns an iterator over index-value pairs of the deque.
+func (deque *Deque) All() iter.Seq2[int, interface{}] {
+	return func(yield func(int, interface{}) bool) {
+		for i := 0; i < deque.length; i++ {
+			if !yield(i, deque.buf[deque.pos(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// Append adds elements to the right end of the deque.
+func (deque *Deque) Append(values ...interface{}) {
+	for _, val := range values {
+		if deque.bounded && deque.maxLen == 0 {
+			return
+		}
+		if deque.bounded && deque.length == deque.maxLen {
+			deque.PopLeft()
+		}
+		deque.grow()
+		deque.buf[deque.pos(deque.length)] = val
+		deque.length++
+	}
+}
+
+// AppendLeft adds elements to the left end of the deque, one after another.
+func (deque *Deque) AppendLeft(values ...interface{}) {
+	for _, val := range values {
+		if deque.bounded && deque.maxLen == 0 {
+			return
+		}
+		if deque.bounded && deque.length == deque.maxLen {
+			deque.Pop()
+		}
+		deque.grow()
+		deque.head = deque.pos(len(deque.buf) - 1)
+		deque.buf[deque.head] = val
+		deque.length++
+	}
+}
+
+// At returns element with given index. Negative index counts from the end
+// of the deque.
+func (deque *Deque) At(index int) (interface{}, error) {
+	i, ok := normIndex(index, deque.length)
+	if !ok {
+		return nil, &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	return deque.buf[deque.pos(i)], nil
+}
+
+// Clear removes all elements from the deque.
+func (deque *Deque) Clear() {
+	deque.buf, deque.head, deque.length = nil, 0, 0
+}
+
+// Count returns the number of times value appears in the deque.
+// Elements are compared with Equal.
+func (deque *Deque) Count(value interface{}) int {
+	return deque.ToList().Count(value)
+}
+
+// Extend adds elements of the list to the right end of the deque.
+func (deque *Deque) Extend(list List) {
+	deque.Append(list...)
+}
+
+// ExtendLeft adds elements of the list to the left end of the deque.
+// Same as in Python the elements end up in reversed order.
+func (deque *Deque) ExtendLeft(list List) {
+	deque.AppendLeft(list...)
+}
+
+// Index returns the index of the first element of the deque whose value is
+// val. It is -1 and *ValueError if there is no such element.
+func (deque *Deque) Index(val interface{}) (int, error) {
+	return deque.ToList().Index(val)
+}
+
+// IsEqual returns true if deques have the same elements, see List.IsEqual.
+func (deque *Deque) IsEqual(otherDeque *Deque) bool {
+	return deque.ToList().IsEqual(otherDeque.ToList())
+}
+
+// Len returns the number of elements in the deque.
+func (deque *Deque) Len() int {
+	return deque.length
+}
+
+// MaxLen returns the maximum length of the deque or Omit if there
+// is no limit.
+func (deque *Deque) MaxLen() int {
+	if !deque.bounded {
+		return Omit
+	}
+	return deque.maxLen
+}
+
+// Pop removes and returns the rightmost element of the deque.
+func (deque *Deque) Pop() (interface{}, error) {
+	if deque.length <= 0 {
+		return nil, &IndexError{Index: -1, Err: ErrRemoveFromEmptyDeque}
+	}
+	last := deque.pos(deque.length - 1)
+	val := deque.buf[last]
+	deque.buf[last] = nil
+	deque.length--
+	return val, nil
+}
+
+// PopLeft removes and returns the leftmost element of the deque.
+func (deque *Deque) PopLeft() (interface{}, error) {
+	if deque.length <= 0 {
+		return nil, &IndexError{Index: 0, Err: ErrRemoveFromEmptyDeque}
+	}
+	val := deque.buf[deque.head]
+	deque.buf[deque.head] = nil
+	deque.head = deque.pos(1)
+	deque.length--
+	return val, nil
+}
+
+// Remove the first element from the deque whose value matches the given
+// value. *ValueError if no match is found.
+func (deque *Deque) Remove(val interface{}) error {
+	list := deque.ToList()
+	if err := list.Remove(val); err != nil {
+		return err
+	}
+	deque.Clear()
+	deque.Extend(list)
+	return nil
+}
+
+// Reverse the elements of the deque in place.
+func (deque *Deque) Reverse() {
+	for i, j := 0, deque.length-1; i < j; i, j = i+1, j-1 {
+		pi, pj := deque.pos(i), deque.pos(j)
+		deque.buf[pi], deque.buf[pj] = deque.buf[pj], deque.buf[pi]
+	}
+}
+
+// Rotate the deque n steps to the right. If n is negative rotate to
+// the left. Rotating one step to the right is the same as
+// d.AppendLeft(d.Pop()).
+func (deque *Deque) Rotate(n int) {
+	if deque.length <= 1 {
+		return
+	}
+	n %= deque.length
+	if n < 0 {
+		n += deque.length
+	}
+	if n == 0 {
+		return
+	}
+	if deque.length == len(deque.buf) {
+		// Full buffer, moving the head is enough
+		deque.head = deque.pos(deque.length - n)
+		return
+	}
+	for ; n > 0; n-- {
+		last := deque.pos(deque.length - 1)
+		val := deque.buf[last]
+		deque.buf[last] = nil
+		deque.head = deque.pos(len(deque.buf) - 1)
+		deque.buf[deque.head] = val
+	}
+}
+
+// Set replaces element with given index. Negative index counts from the end
+// of the deque.
+func (deque *Deque) Set(index int, value interface{}) error {
+	i, ok := normIndex(index, deque.length)
+	if !ok {
+		return &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	deque.buf[deque.pos(i)] = value
+	return nil
+}
+
+// String returns deque values as string, same as List.String.
+func (deque *Deque) String() string {
+	return deque.ToList().String()
+}
+
+// ToList returns elements of the deque as a new List.
+func (deque *Deque) ToList() List {
+	list := NewList(deque.length)
+	for i := range list {
+		list[i] = deque.buf[deque.pos(i)]
+	}
+	return list
+}
+
+//=============================================================================
+
+// pos returns position in buf of element with given index.
+func (deque *Deque) pos(index int) int {
+	return (deque.head + index) % len(deque.buf)
+}
+
+// grow makes sure there is space for one more element.
+func (deque *Deque) grow() {
+	if deque.length < len(deque.buf) {
+		return
+	}
+	size := 2 * len(deque.buf)
+	if size == 0 {
+		size = 8
+	}
+	if deque.bounded && size > deque.maxLen {
+		size = deque.maxLen
+	}
+	buf := make([]interface{}, size)
+	for i := 0; i < deque.length; i++ {
+		buf[i] = deque.buf[deque.pos(i)]
+	}
+	deque.buf, deque.head = buf, 0
+}
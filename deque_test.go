@@ -0,0 +1,139 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestDequeAppendPop(t *testing.T) {
+	deque := NewDeque(Omit)
+	for i := 0; i < 20; i++ {
+		deque.Append(i)
+		deque.AppendLeft(-i)
+	}
+	if deque.Len() != 40 {
+		t.Fatalf("Len() => %d, want 40", deque.Len())
+	}
+	for i := 19; i >= 0; i-- {
+		right, err := deque.Pop()
+		if right != i || err != nil {
+			t.Fatalf("Pop() => %v, %v, want %v, nil", right, err, i)
+		}
+		left, err := deque.PopLeft()
+		if left != -i || err != nil {
+			t.Fatalf("PopLeft() => %v, %v, want %v, nil", left, err, -i)
+		}
+	}
+	if _, err := deque.Pop(); !errors.Is(err, ErrRemoveFromEmptyDeque) {
+		t.Errorf("Pop() on empty deque => %v, want %v",
+			err, ErrRemoveFromEmptyDeque)
+	}
+	if _, err := deque.PopLeft(); !errors.Is(err, ErrRemoveFromEmptyDeque) {
+		t.Errorf("PopLeft() on empty deque => %v, want %v",
+			err, ErrRemoveFromEmptyDeque)
+	}
+}
+
+var dequeMaxLenTests = []struct {
+	maxLen int
+	right  List
+	left   List
+	out    List
+}{
+	{3, List{1, 2, 3, 4, 5}, nil, List{3, 4, 5}},
+	{3, List{1, 2}, List{0, -1}, List{-1, 0, 1}},
+	{3, nil, List{1, 2, 3, 4}, List{4, 3, 2}},
+	{0, List{1, 2}, List{3}, List{}},
+	{Omit, List{1, 2}, List{0}, List{0, 1, 2}},
+}
+
+func TestDequeMaxLen(t *testing.T) {
+	for index, dmt := range dequeMaxLenTests {
+		deque := NewDeque(dmt.maxLen)
+		deque.Extend(dmt.right)
+		deque.ExtendLeft(dmt.left)
+		if out := deque.ToList(); !reflect.DeepEqual(out, dmt.out) {
+			t.Errorf("%d. maxLen %d, Extend(%v), ExtendLeft(%v) => %v, want %v",
+				index, dmt.maxLen, dmt.right, dmt.left, out, dmt.out)
+		}
+	}
+
+	var deque Deque
+	deque.Append(1, 2)
+	deque.AppendLeft(0)
+	if out := deque.ToList(); !reflect.DeepEqual(out, List{0, 1, 2}) ||
+		deque.MaxLen() != Omit {
+		t.Errorf("zero Deque Append(1, 2), AppendLeft(0) => %v, maxLen %d, "+
+			"want [0 1 2] without limit", out, deque.MaxLen())
+	}
+}
+
+var dequeRotateTests = []struct {
+	in  List
+	n   int
+	out List
+}{
+	{List{1, 2, 3, 4, 5}, 1, List{5, 1, 2, 3, 4}},
+	{List{1, 2, 3, 4, 5}, 2, List{4, 5, 1, 2, 3}},
+	{List{1, 2, 3, 4, 5}, -1, List{2, 3, 4, 5, 1}},
+	{List{1, 2, 3, 4, 5}, 12, List{4, 5, 1, 2, 3}},
+	{List{1, 2, 3, 4, 5}, 0, List{1, 2, 3, 4, 5}},
+	{List{1, 2, 3, 4, 5, 6, 7, 8}, 3, List{6, 7, 8, 1, 2, 3, 4, 5}},
+	{List{}, 3, List{}},
+}
+
+func TestDequeRotate(t *testing.T) {
+	for index, drt := range dequeRotateTests {
+		deque := DequeFromList(drt.in, Omit)
+		deque.Rotate(drt.n)
+		if out := deque.ToList(); !reflect.DeepEqual(out, drt.out) {
+			t.Errorf("%d. %v.Rotate(%d) => %v, want %v",
+				index, drt.in, drt.n, out, drt.out)
+		}
+	}
+}
+
+func TestDequeIndexing(t *testing.T) {
+	deque := DequeFromList(List{1, 2, 3}, Omit)
+	deque.AppendLeft(0)
+
+	if val, err := deque.At(-1); val != 3 || err != nil {
+		t.Errorf("%v.At(-1) => %v, %v, want 3, nil", deque, val, err)
+	}
+	if _, err := deque.At(4); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("%v.At(4) => %v, want %v", deque, err, ErrIndexOutOfRange)
+	}
+	if err := deque.Set(0, "zero"); err != nil {
+		t.Errorf("%v.Set(0, zero) => %v", deque, err)
+	}
+	if index, err := deque.Index(2.0); index != 2 || err != nil {
+		t.Errorf("%v.Index(2.0) => %v, %v, want 2, nil", deque, index, err)
+	}
+	if err := deque.Remove(2); err != nil {
+		t.Errorf("%v.Remove(2) => %v", deque, err)
+	}
+	deque.Reverse()
+	if want := DequeFromList(List{3, 1, "zero"}, 5); !deque.IsEqual(want) {
+		t.Errorf("deque => %v, want %v", deque, want)
+	}
+	if deque.String() != "3, 1, zero" || deque.Count(1) != 1 {
+		t.Errorf("%v.String() => %q", deque, deque.String())
+	}
+
+	var indexes []int
+	for i := range deque.All() {
+		indexes = append(indexes, i)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 1, 2}) {
+		t.Errorf("%v.All() indexes => %v", deque, indexes)
+	}
+}
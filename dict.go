@@ -9,9 +9,8 @@ package listdict
 import (
 	"errors"
 	"fmt"
-	"math/rand"
 	"reflect"
-	"time"
+	"strings"
 )
 
 // Simple dict.
@@ -29,14 +28,11 @@ var (
 		New("Trying to remove element from empty dict")
 )
 
-func init() {
-	rand.Seed(time.Now().UTC().UnixNano())
-}
-
 //=============================================================================
 
 // DictFromKeys creates a new dictionary with keys from list and values set
-// to defaultVal.
+// to defaultVal. Keys are converted to strings, so 1 and "1" are the same
+// key, use HashDictFromKeys to keep them.
 func DictFromKeys(list List, defaultVal interface{}) Dict {
 	newDict := NewDict()
 	for _, value := range list {
@@ -92,6 +88,7 @@ func (dict Dict) Items() []List {
 }
 
 // Keys returns a list of the dictionary's keys, unordered.
+// Use KeysView for a live view that works as a set.
 func (dict Dict) Keys() List {
 	list := NewList(len(dict))
 	i := 0
@@ -107,7 +104,7 @@ func (dict Dict) Keys() List {
 // defaultVal should be same type as you expect to get.
 func (dict Dict) Pop(key string, defaultVal interface{}) (interface{}, error) {
 	if len(dict) <= 0 {
-		return defaultVal, ErrRemoveFromEmptyDict
+		return defaultVal, &KeyError{Key: key, Err: ErrRemoveFromEmptyDict}
 	}
 	if dict.HasKey(key) {
 		val := dict[key]
@@ -120,14 +117,24 @@ func (dict Dict) Pop(key string, defaultVal interface{}) (interface{}, error) {
 // PopItem return and remove a random key-value pair as List from
 // the dictionary.
 func (dict Dict) PopItem() (List, error) {
+	return dict.PopItemRand(nil)
+}
+
+// PopItemRand is the same as PopItem but the key is chosen using r.
+// If r is nil the default source is used (see Rand).
+func (dict Dict) PopItemRand(r Rand) (List, error) {
 	if len(dict) <= 0 {
-		return List{}, ErrRemoveFromEmptyDict
+		return List{}, &KeyError{Err: ErrRemoveFromEmptyDict}
 	}
 
-	// Get dict keys
-	dictKeys := dict.Keys()
-	// Return random key as string
-	randKey := fmt.Sprintf("%v", dictKeys[rand.Intn(len(dictKeys))])
+	// Get dict keys and take the n-th smallest so the same r gives the
+	// same key
+	dictKeys := make([]string, 0, len(dict))
+	for key := range dict {
+		dictKeys = append(dictKeys, key)
+	}
+	randKey := selectNth(dictKeys, randOrDefault(r).Intn(len(dictKeys)),
+		strings.Compare)
 
 	list := NewList(2)
 	list = List{randKey, dict[randKey]}
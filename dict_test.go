@@ -7,6 +7,7 @@
 package listdict
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -92,7 +93,7 @@ func TestDictGet(t *testing.T) {
 
 		switch {
 		case myValue != dgt.out:
-			t.Errorf("%d. %v.Get(%v, %v) => %v, want 0",
+			t.Errorf("%d. %v.Get(%v, %v) => %v, want %v",
 				index, dgt.in, dgt.key, dgt.defaultVal, myValue, dgt.out)
 		case dict.HasKey(dgt.key) && !dgt.in.HasKey(dgt.key):
 			t.Errorf("%d. %v.Get(%v, %v) => out dict should not have '%v' key",
@@ -262,7 +263,7 @@ func TestDictPop(t *testing.T) {
 		val, err := dict.Pop(dpt.key, dpt.defaultVal)
 
 		switch {
-		case val != dpt.out || err != dpt.outError:
+		case val != dpt.out || !errors.Is(err, dpt.outError):
 			t.Errorf("%d. %v.Pop(%v, %v) => %v, %v, want %v, %v",
 				index, dpt.in, dpt.key, dpt.defaultVal,
 				val, err, dpt.out, dpt.outError)
@@ -294,9 +295,9 @@ func TestDictPopItem(t *testing.T) {
 		list, err := dict.PopItem()
 
 		switch {
-		case !reflect.DeepEqual(list, dpit.out) || err != dpit.outError:
+		case !reflect.DeepEqual(list, dpit.out) || !errors.Is(err, dpit.outError):
 			t.Errorf("%d. %v.PopItem() => %v, %v, want %v, %v",
-				index, dpit.in, list, dpit.out, dpit.outError)
+				index, dpit.in, list, err, dpit.out, dpit.outError)
 		case !reflect.DeepEqual(dict, dpit.outDict):
 			t.Errorf("%d. %v.PopItem() => out dict = %v, want %v",
 				index, dpit.in, dict, dpit.outDict)
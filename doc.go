@@ -20,6 +20,40 @@ in Python.
 	val := dict.Values()	// val = [3 1 2]
 	// Keys() and Values() are unordered, same as in Python
 
+TypedList and TypedDict have the same methods for values of a single type:
+
+	d := listdict.TypedDict[string, int]{"one": 1}
+	n := d.Get("two", 2)	// n is int
+
+HashDict accepts keys of any hashable type, like numbers and Tuples,
+compared by Python rules:
+
+	d := listdict.NewHashDict()
+	d.Set(listdict.Tuple{"x", 1}, "point")
+	d.Set(1, "one")
+	v, _ := d.Get(true, nil)	// v = "one", same as in Python
+
+Set and FrozenSet hold unique hashable elements, FrozenSet is hashable
+itself so it can be a HashDict key.
+
+Dumps and Loads work as Python's json.dumps and json.loads, output is
+byte-for-byte the same for the same options:
+
+	s, _ := listdict.Dumps(listdict.List{1, 2.0, nil}, nil)	// s = "[1, 2.0, null]"
+	v, _ := listdict.Loads(`{"a": [1]}`, nil)	// v = Dict{"a": List{1}}
+
+Repr and ParseLiteral work as Python's repr and ast.literal_eval:
+
+	s := listdict.List{"a, b", nil}.Repr()	// s = "['a, b', None]"
+	v, _ := listdict.ParseLiteral("('x', {'k': 1.5})")	// v = Tuple{"x", Dict{"k": 1.5}}
+
+Package github.com/gosimple/listdict/itertools has lazy versions of
+Python's itertools working with List, package
+github.com/gosimple/listdict/pickle reads and writes Python's pickles,
+packages github.com/gosimple/listdict/yaml and
+github.com/gosimple/listdict/toml read and write YAML and TOML documents
+as List and Dict.
+
 Requests or bugs?
 https://github.com/gosimple/listdict/issues
 */
@@ -0,0 +1,132 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"iter"
+)
+
+// entryList is a map of entries kept in a doubly linked list in insertion
+// order, used by OrderedDict and HashDict. Entries are found by hash H
+// and keep the key K they were added with. The zero value is an empty
+// list, entryList must not be copied after first use.
+//
+// Entries are never relinked: removed entries are unlinked but keep their
+// links, and moving a key replaces its entry with a new one, so an
+// iterator standing at any entry continues with the entries after it.
+type entryList[H comparable, K any] struct {
+	byHash map[H]*listEntry[K]
+	root   listEntry[K] // root.next is the first entry, root.prev the last
+}
+
+// listEntry is an element of entryList.
+type listEntry[K any] struct {
+	key        K
+	value      interface{}
+	prev, next *listEntry[K]
+	removed    bool // removed entries keep links for iterators
+}
+
+// init makes the zero entryList ready to use.
+func (list *entryList[H, K]) init() {
+	if list.byHash == nil {
+		list.byHash = make(map[H]*listEntry[K])
+		list.root.prev, list.root.next = &list.root, &list.root
+	}
+}
+
+// all returns an iterator over entries in list order.
+func (list *entryList[H, K]) all() iter.Seq[*listEntry[K]] {
+	return func(yield func(*listEntry[K]) bool) {
+		if list.byHash == nil {
+			return
+		}
+		for e := list.root.next; e != &list.root; e = e.next {
+			if !e.removed && !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// clear removes all entries.
+func (list *entryList[H, K]) clear() {
+	for _, e := range list.byHash {
+		e.removed = true
+	}
+	clear(list.byHash)
+	list.root.prev, list.root.next = &list.root, &list.root
+}
+
+// first returns the first entry, or the last one if last is true.
+// The list must not be empty.
+func (list *entryList[H, K]) first(last bool) *listEntry[K] {
+	if last {
+		return list.root.prev
+	}
+	return list.root.next
+}
+
+// get returns entry for hash or nil if there is none.
+func (list *entryList[H, K]) get(hash H) *listEntry[K] {
+	return list.byHash[hash]
+}
+
+// len returns the number of entries.
+func (list *entryList[H, K]) len() int {
+	return len(list.byHash)
+}
+
+// move moves entry for hash to the end of the list, or to the beginning
+// if last is false. It returns false if there is no such entry.
+func (list *entryList[H, K]) move(hash H, last bool) bool {
+	e := list.remove(hash)
+	if e == nil {
+		return false
+	}
+	moved := &listEntry[K]{key: e.key, value: e.value}
+	list.byHash[hash] = moved
+	if last {
+		list.link(moved, list.root.prev)
+	} else {
+		list.link(moved, &list.root)
+	}
+	return true
+}
+
+// remove removes and returns entry for hash, or nil if there is none.
+func (list *entryList[H, K]) remove(hash H) *listEntry[K] {
+	e, ok := list.byHash[hash]
+	if !ok {
+		return nil
+	}
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	e.removed = true
+	delete(list.byHash, hash)
+	return e
+}
+
+// set sets value of entry for hash, a new entry with key is added at
+// the end of the list.
+func (list *entryList[H, K]) set(hash H, key K, value interface{}) {
+	if e, ok := list.byHash[hash]; ok {
+		e.value = value
+		return
+	}
+	list.init()
+	e := &listEntry[K]{key: key, value: value}
+	list.byHash[hash] = e
+	list.link(e, list.root.prev)
+}
+
+// link inserts e after entry at.
+func (list *entryList[H, K]) link(e, at *listEntry[K]) {
+	e.prev, e.next = at, at.next
+	at.next.prev = e
+	at.next = e
+}
@@ -0,0 +1,116 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnorderable is returned when user want to order values that
+	// can't be compared, like a string and a number
+	ErrUnorderable = errors.New("unorderable types")
+	// ErrNotInList is returned when user want to find or remove value
+	// that is not in the list
+	ErrNotInList = errors.New("value not in list")
+	// ErrKeyNotFound is returned when user want to use key that is not
+	// in the dictionary
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrEmptySequence is returned when user want to get a value
+	// computed from elements of empty sequence, like its minimum
+	ErrEmptySequence = errors.New("empty sequence")
+	// ErrSampleSize is returned when user want to take a sample larger
+	// than the population or with negative size
+	ErrSampleSize = errors.New("sample larger than population or negative")
+	// ErrInvalidWeights is returned when user want to use weights that
+	// are negative, don't match the population or sum up to zero
+	ErrInvalidWeights = errors.New("invalid weights")
+	// ErrUnsupportedOperand is returned when user want to do arithmetic
+	// on values that are not numbers
+	ErrUnsupportedOperand = errors.New("unsupported operand types")
+)
+
+//=============================================================================
+
+// TypeError is returned when an operation is applied to values of
+// the wrong type, same as Python's TypeError.
+type TypeError struct {
+	Op     string        // operation, e.g. "<"
+	Values []interface{} // values the operation was applied to
+	Err    error         // kind of error, e.g. ErrUnorderable
+}
+
+func (e *TypeError) Error() string {
+	names := make([]string, len(e.Values))
+	for i, val := range e.Values {
+		names[i] = typeName(val)
+	}
+	switch {
+	case len(names) == 2 && e.Op != "":
+		return fmt.Sprintf("%v: %s %s %s", e.Err, names[0], e.Op, names[1])
+	case len(names) == 0 && e.Op != "":
+		return fmt.Sprintf("%v: %s", e.Err, e.Op)
+	}
+	return fmt.Sprintf("%v: %s", e.Err, strings.Join(names, ", "))
+}
+
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+// IndexError is returned when a sequence index is out of range,
+// same as Python's IndexError.
+type IndexError struct {
+	Index int   // index that was used
+	Err   error // kind of error, e.g. ErrIndexOutOfRange
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("%v: %d", e.Err, e.Index)
+}
+
+func (e *IndexError) Unwrap() error {
+	return e.Err
+}
+
+// KeyError is returned when a key is not found in a dictionary,
+// same as Python's KeyError.
+type KeyError struct {
+	Key interface{} // key that was used
+	Err error       // kind of error, e.g. ErrRemoveFromEmptyDict
+}
+
+func (e *KeyError) Error() string {
+	if e.Key == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Err, e.Key)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// ValueError is returned when an operation gets a value of the right type
+// but wrong content, same as Python's ValueError.
+type ValueError struct {
+	Value interface{} // value that was used
+	Err   error       // kind of error, e.g. ErrNotInList
+}
+
+func (e *ValueError) Error() string {
+	if e.Value == nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Err, e.Value)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
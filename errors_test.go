@@ -0,0 +1,73 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"testing"
+)
+
+//=============================================================================
+
+func TestIndexError(t *testing.T) {
+	list := List{1, 2}
+	_, err := list.PopItem(5)
+
+	var indexErr *IndexError
+	switch {
+	case !errors.As(err, &indexErr):
+		t.Fatalf("%v.PopItem(5) => %T, want *IndexError", list, err)
+	case indexErr.Index != 5:
+		t.Errorf("IndexError.Index => %d, want 5", indexErr.Index)
+	case !errors.Is(err, ErrIndexOutOfRange):
+		t.Errorf("errors.Is(%v, ErrIndexOutOfRange) => false", err)
+	case err.Error() != "list index out of range: 5":
+		t.Errorf("IndexError.Error() => %q", err.Error())
+	}
+
+	_, err = (&List{}).Pop()
+	if !errors.As(err, &indexErr) || !errors.Is(err, ErrRemoveFromEmptyList) {
+		t.Errorf("List{}.Pop() => %v, want IndexError for empty list", err)
+	}
+}
+
+func TestKeyError(t *testing.T) {
+	_, err := Dict{}.Pop("one", nil)
+
+	var keyErr *KeyError
+	switch {
+	case !errors.As(err, &keyErr):
+		t.Fatalf("Dict{}.Pop(one) => %T, want *KeyError", err)
+	case keyErr.Key != "one":
+		t.Errorf("KeyError.Key => %v, want one", keyErr.Key)
+	case !errors.Is(err, ErrRemoveFromEmptyDict):
+		t.Errorf("errors.Is(%v, ErrRemoveFromEmptyDict) => false", err)
+	}
+}
+
+func TestValueError(t *testing.T) {
+	list := List{1, 2}
+	err := list.Remove("one")
+
+	var valueErr *ValueError
+	switch {
+	case !errors.As(err, &valueErr):
+		t.Fatalf("%v.Remove(one) => %T, want *ValueError", list, err)
+	case valueErr.Value != "one":
+		t.Errorf("ValueError.Value => %v, want one", valueErr.Value)
+	case !errors.Is(err, ErrNotInList):
+		t.Errorf("errors.Is(%v, ErrNotInList) => false", err)
+	case err.Error() != "value not in list: one":
+		t.Errorf("ValueError.Error() => %q", err.Error())
+	}
+
+	_, err = list.Slice(Omit, Omit, 0)
+	if !errors.As(err, &valueErr) || !errors.Is(err, ErrZeroSliceStep) {
+		t.Errorf("%v.Slice(::0) => %v, want ValueError for zero step",
+			list, err)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"cmp"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FrozenSet is an immutable Set, same as Python's frozenset. Unlike Set
+// it is hashable, so it can be an element of a Set or a HashDict key.
+// The zero value is an empty FrozenSet.
+//
+//	fs, _ := listdict.FrozenSetFromList(listdict.List{1, 2})
+//	d.Set(fs, "pair")
+type FrozenSet struct {
+	items *HashDict   // elements are keys, values are not used
+	hash  interface{} // hash key, same for sets with equal elements
+}
+
+// frozenSetKey is the hash key of a FrozenSet, elements keys sorted by
+// compareHashKeys in a chain of tupleKeys.
+type frozenSetKey struct {
+	elems interface{}
+}
+
+// FrozenSetFromList returns new FrozenSet with unique elements of the list.
+// It returns *TypeError if any element is unhashable.
+func FrozenSetFromList(list List) (FrozenSet, error) {
+	set, err := SetFromList(list)
+	if err != nil {
+		return FrozenSet{}, err
+	}
+	return newFrozenSet(set.items), nil
+}
+
+// FrozenSetFromDict returns new FrozenSet with keys of the dict.
+func FrozenSetFromDict(dict Dict) FrozenSet {
+	return newFrozenSet(SetFromDict(dict).items)
+}
+
+// newFrozenSet returns FrozenSet with keys of items, items must not be
+// changed later.
+func newFrozenSet(items *HashDict) FrozenSet {
+	// Order of elements can differ between equal sets, so the hash key is
+	// built from sorted element keys.
+	keys := make([]interface{}, 0, items.Len())
+	for key := range items.entries.byHash {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, compareHashKeys)
+
+	var elems interface{} = tupleEnd{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		elems = tupleKey{head: keys[i], tail: elems}
+	}
+	return FrozenSet{items: items, hash: frozenSetKey{elems}}
+}
+
+//=============================================================================
+
+// Contains returns true if value is an element of the set.
+// Set value is looked up as FrozenSet, same as in Python.
+func (set FrozenSet) Contains(value interface{}) bool {
+	return set.items != nil && set.items.HasKey(setElement(value))
+}
+
+// Difference returns a new set with elements of the set that are not
+// in the others.
+func (set FrozenSet) Difference(others ...AbstractSet) FrozenSet {
+	return newFrozenSet(setDifference(set, others))
+}
+
+// Intersection returns a new set with elements common to the set and
+// all the others.
+func (set FrozenSet) Intersection(others ...AbstractSet) FrozenSet {
+	return newFrozenSet(setIntersection(set, others))
+}
+
+// IsDisjoint returns true if the set has no elements in common with other.
+func (set FrozenSet) IsDisjoint(other AbstractSet) bool {
+	return isDisjoint(set, other)
+}
+
+// IsEqual returns true if the set and other have the same elements.
+func (set FrozenSet) IsEqual(other AbstractSet) bool {
+	return set.Len() == other.Len() && isSubset(set, other)
+}
+
+// IsSubset returns true if every element of the set is in other.
+func (set FrozenSet) IsSubset(other AbstractSet) bool {
+	return isSubset(set, other)
+}
+
+// IsSuperset returns true if every element of other is in the set.
+func (set FrozenSet) IsSuperset(other AbstractSet) bool {
+	return isSubset(other, set)
+}
+
+// Iter returns an iterator over elements of the set.
+func (set FrozenSet) Iter() iter.Seq[interface{}] {
+	if set.items == nil {
+		return func(yield func(interface{}) bool) {}
+	}
+	return set.items.IterKeys()
+}
+
+// Len returns the number of elements in the set.
+func (set FrozenSet) Len() int {
+	if set.items == nil {
+		return 0
+	}
+	return set.items.Len()
+}
+
+// String returns set elements as string in braces, same as in Python.
+func (set FrozenSet) String() string {
+	return setString(set)
+}
+
+// SymmetricDifference returns a new set with elements in either the set
+// or other but not in both.
+func (set FrozenSet) SymmetricDifference(other AbstractSet) FrozenSet {
+	return newFrozenSet(setSymmetricDifference(set, other))
+}
+
+// Thaw returns elements of the set as a new Set.
+func (set FrozenSet) Thaw() *Set {
+	return &Set{items: setUnion(set)}
+}
+
+// ToList returns elements of the set as a new List.
+func (set FrozenSet) ToList() List {
+	return CollectList(set.Iter())
+}
+
+// Union returns a new set with elements from the set and all the others.
+func (set FrozenSet) Union(others ...AbstractSet) FrozenSet {
+	return newFrozenSet(setUnion(set, others...))
+}
+
+//=============================================================================
+
+// hashKey returns hash key of the set.
+func (set FrozenSet) hashKey() interface{} {
+	if set.items == nil {
+		return frozenSetKey{tupleEnd{}}
+	}
+	return set.hash
+}
+
+// compareHashKeys orders hash keys by type, then by value. Types are
+// ordered by their address, so the order is the same only within
+// a process, same as hash keys.
+func compareHashKeys(a, b interface{}) int {
+	return compareHashValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func compareHashValues(a, b reflect.Value) int {
+	if !a.IsValid() || !b.IsValid() {
+		return cmp.Compare(boolToInt(a.IsValid()), boolToInt(b.IsValid()))
+	}
+	if a.Type() != b.Type() {
+		return cmp.Compare(reflect.ValueOf(a.Type()).Pointer(),
+			reflect.ValueOf(b.Type()).Pointer())
+	}
+
+	switch a.Kind() {
+	case reflect.Bool:
+		return cmp.Compare(boolToInt(a.Bool()), boolToInt(b.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := cmp.Compare(real(a.Complex()), real(b.Complex())); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a.Complex()), imag(b.Complex()))
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return cmp.Compare(a.Pointer(), b.Pointer())
+	case reflect.Interface:
+		return compareHashValues(a.Elem(), b.Elem())
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if c := compareHashValues(a.Index(i), b.Index(i)); c != 0 {
+				return c
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if c := compareHashValues(a.Field(i), b.Field(i)); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestFrozenSetHash(t *testing.T) {
+	a, _ := FrozenSetFromList(List{1, "a", Tuple{2, 3}})
+	b, _ := FrozenSetFromList(List{Tuple{2.0, 3}, "a", true})
+	c := FrozenSetFromDict(Dict{"a": 1})
+
+	dict := NewHashDict()
+	dict.Set(a, "first")
+	dict.Set(b, "second")
+	dict.Set(c, "third")
+	dict.Set(FrozenSet{}, "empty")
+
+	if dict.Len() != 3 {
+		t.Errorf("HashDict with FrozenSet keys => %v, want 3 keys", dict)
+	}
+	if val, _ := dict.Get(a, nil); val != "second" {
+		t.Errorf("Get(%v) => %v, want second", a, val)
+	}
+	empty, _ := FrozenSetFromList(List{})
+	if val, _ := dict.Get(empty, nil); val != "empty" {
+		t.Errorf("Get(%v) => %v, want empty", empty, val)
+	}
+
+	set := NewSet()
+	set.Add(a)
+	set.Add(b)
+	if set.Len() != 1 {
+		t.Errorf("Set of equal FrozenSets => %v, want 1 element", set)
+	}
+
+	type point struct{ X, Y interface{} }
+	ptr := new(int)
+	elems := List{nil, 1, 2.5, "a", []byte("a"), Tuple{1, "b"}, c,
+		point{1, "y"}, point{1, 2}, ptr, int8(-3), uint64(math.MaxUint64)}
+	first, _ := FrozenSetFromList(elems)
+	for i := range elems {
+		reversed := append(List{}, elems[i:]...)
+		reversed = append(reversed, elems[:i]...)
+		reversed.Reverse()
+		other, _ := FrozenSetFromList(reversed)
+		if first.hashKey() != other.hashKey() {
+			t.Errorf("%d. FrozenSetFromList(%v) hash differs from %v",
+				i, reversed, elems)
+		}
+	}
+}
+
+func TestFrozenSetOperations(t *testing.T) {
+	a, _ := FrozenSetFromList(List{1, 2, 3})
+	b, _ := FrozenSetFromList(List{3, 4})
+
+	if out := a.Union(b).ToList(); !reflect.DeepEqual(out,
+		List{1, 2, 3, 4}) {
+		t.Errorf("Union() => %v, want [1 2 3 4]", out)
+	}
+	if out := a.Intersection(b).ToList(); !reflect.DeepEqual(out, List{3}) {
+		t.Errorf("Intersection() => %v, want {3}", out)
+	}
+	if out := a.Difference(b).ToList(); !reflect.DeepEqual(out, List{1, 2}) {
+		t.Errorf("Difference() => %v, want [1 2]", out)
+	}
+	if out := a.SymmetricDifference(b).Len(); out != 3 {
+		t.Errorf("SymmetricDifference().Len() => %v, want 3", out)
+	}
+	if !a.IsSuperset(a.Difference(b)) || a.IsDisjoint(b) {
+		t.Errorf("IsSuperset/IsDisjoint(%v, %v) wrong", a, b)
+	}
+
+	thawed := a.Thaw()
+	thawed.Add(5)
+	if a.Contains(5) || a.Len() != 3 {
+		t.Errorf("changing Thaw() result changed FrozenSet %v", a)
+	}
+	var zero FrozenSet
+	if zero.String() != "{}" || zero.Len() != 0 {
+		t.Errorf("zero FrozenSet => %v, want {}", zero)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+)
+
+// Tuple is an immutable sequence, same as Python's tuple. Unlike List
+// it can be used as a HashDict key if all its elements are hashable.
+// Tuple is never equal to List, same as (1, 2) != [1, 2] in Python.
+//
+//	key := listdict.Tuple{"x", 1}
+type Tuple []interface{}
+
+var (
+	// ErrUnhashable is returned when user want to use value that can't be
+	// hashed, like List or Dict, as a dictionary key
+	ErrUnhashable = errors.New("unhashable type")
+)
+
+//=============================================================================
+
+// Hashable returns true if value can be used as a HashDict key: nil,
+// numbers, bools, strings, byte slices, Tuples of hashable values,
+// FrozenSets and other comparable Go values. List, Dict, Set, slices, maps
+// and funcs are not hashable.
+func Hashable(value interface{}) bool {
+	_, err := hashKey(value)
+	return err == nil
+}
+
+//=============================================================================
+
+// At returns element with given index. Negative index counts from the end
+// of the tuple.
+func (tuple Tuple) At(index int) (interface{}, error) {
+	return List(tuple).At(index)
+}
+
+// Count returns the number of times value appears in the tuple.
+// Elements are compared with Equal.
+func (tuple Tuple) Count(value interface{}) int {
+	return List(tuple).Count(value)
+}
+
+// Index returns the index of the first item in the tuple whose value is val.
+// It is -1 and *ValueError if there is no such item.
+func (tuple Tuple) Index(val interface{}) (int, error) {
+	return List(tuple).Index(val)
+}
+
+// String returns tuple values as string in parentheses, a tuple with one
+// element has a trailing comma, same as in Python.
+//
+//	listdict.Tuple{"a", 2}.String() => "(a, 2)"
+//	listdict.Tuple{"a"}.String()    => "(a,)"
+func (tuple Tuple) String() string {
+	out := make([]string, len(tuple))
+	for i, val := range tuple {
+		out[i] = fmt.Sprintf("%v", val)
+	}
+	if len(out) == 1 {
+		return "(" + out[0] + ",)"
+	}
+	return "(" + strings.Join(out, ", ") + ")"
+}
+
+// ToList returns elements of the tuple as a new List.
+func (tuple Tuple) ToList() List {
+	return append(List{}, tuple...)
+}
+
+//=============================================================================
+
+// tupleKey is the hash key of a non-empty Tuple: key of its first element
+// followed by the key of the rest of the tuple.
+type tupleKey struct {
+	head, tail interface{}
+}
+
+// tupleEnd ends a chain of tupleKeys, it's also the key of an empty Tuple.
+type tupleEnd struct{}
+
+// bytesKey is the hash key of a byte slice.
+type bytesKey string
+
+// hashKey returns a comparable Go value that is the same for keys equal
+// under Python rules, so 1, 1.0 and true have the same hash key.
+// It returns *TypeError wrapping ErrUnhashable if key can't be hashed.
+func hashKey(key interface{}) (interface{}, error) {
+	switch k := key.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return k, nil
+	case []byte:
+		return bytesKey(k), nil
+	case Tuple:
+		var out interface{} = tupleEnd{}
+		for i := len(k) - 1; i >= 0; i-- {
+			head, err := hashKey(k[i])
+			if err != nil {
+				return nil, err
+			}
+			out = tupleKey{head: head, tail: out}
+		}
+		return out, nil
+	case FrozenSet:
+		return k.hashKey(), nil
+	case *Set, *HashDict, *OrderedDict, *Deque:
+		// Mutable containers, unhashable same as in Python
+		return nil, &TypeError{Values: []interface{}{key}, Err: ErrUnhashable}
+	}
+
+	if n, ok := toNumber(key); ok {
+		return n.hashKey(), nil
+	}
+	if s, ok := toString(key); ok {
+		return s, nil
+	}
+	if reflect.ValueOf(key).Comparable() {
+		return key, nil
+	}
+	return nil, &TypeError{Values: []interface{}{key}, Err: ErrUnhashable}
+}
+
+// bigKey is the hash key of an integer too large for uint64 and float64.
+type bigKey string
+
+// hashKey returns number as int64 if it's an integer that fits, as uint64
+// if it's a larger integer and as float64 otherwise. Integers larger than
+// uint64 are float64 if float64 can hold them exactly.
+func (n number) hashKey() interface{} {
+	switch n.kind {
+	case bigNumber:
+		if f, acc := n.bigFloat().Float64(); acc == big.Exact {
+			return f
+		}
+		return bigKey(n.b.String())
+	case intNumber:
+		return n.i
+	case uintNumber:
+		if n.u <= math.MaxInt64 {
+			return int64(n.u)
+		}
+		return n.u
+	}
+
+	if !math.IsInf(n.f, 0) && n.f == math.Trunc(n.f) {
+		switch {
+		case n.f >= math.MinInt64 && n.f < math.MaxInt64:
+			return int64(n.f)
+		case n.f > 0 && n.f < math.MaxUint64:
+			return uint64(n.f)
+		}
+	}
+	return n.f
+}
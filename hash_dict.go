@@ -0,0 +1,287 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// HashDict is a dictionary with keys of any hashable type (see Hashable),
+// same as Python's dict. Keys are compared by Python rules, so 1, 1.0 and
+// true are the same key and "1" is a different one. When an existing key
+// is set again the first inserted key is kept, only the value changes.
+// HashDict remembers insertion order of keys, same as dict since
+// Python 3.7. The zero value is an empty HashDict ready to use.
+//
+//	d := listdict.NewHashDict()
+//	d.Set(1, "one")
+//	d.Set(listdict.Tuple{"x", 2}, "point")
+//	d.Get(1.0, nil) // "one"
+type HashDict struct {
+	entries entryList[interface{}, interface{}] // by hashKey of key
+}
+
+// NewHashDict returns new empty HashDict.
+func NewHashDict() *HashDict {
+	dict := &HashDict{}
+	dict.entries.init()
+	return dict
+}
+
+// HashDictFromDict returns new HashDict with key-value pairs of the dict.
+// Dict has no order so keys are added in sorted order.
+func HashDictFromDict(dict Dict) *HashDict {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hashDict := NewHashDict()
+	for _, key := range keys {
+		hashDict.Set(key, dict[key])
+	}
+	return hashDict
+}
+
+// HashDictFromKeys creates a new HashDict with keys from list, in list
+// order, and values set to defaultVal. Unlike DictFromKeys keys are not
+// converted to strings. It returns *TypeError if any key is unhashable.
+//
+//	listdict.HashDictFromKeys(listdict.List{1, "1", 1.0}, 0) // {1: 0, "1": 0}
+func HashDictFromKeys(list List, defaultVal interface{}) (*HashDict, error) {
+	dict := NewHashDict()
+	for _, value := range list {
+		if err := dict.Set(value, defaultVal); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+//=============================================================================
+
+// All returns an iterator over key-value pairs of the dictionary,
+// in insertion order.
+func (dict *HashDict) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		for e := range dict.entries.all() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the dictionary.
+func (dict *HashDict) Clear() {
+	dict.entries.clear()
+}
+
+// Delete removes the given key from the dictionary.
+// It returns *KeyError if key is NOT in the dictionary and *TypeError if
+// key is unhashable.
+func (dict *HashDict) Delete(key interface{}) error {
+	hash, err := hashKey(key)
+	if err != nil {
+		return err
+	}
+	if dict.entries.remove(hash) == nil {
+		return &KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+	return nil
+}
+
+// Get returns value for the given key or defaultVal if key is NOT in
+// the dictionary. It returns *TypeError if key is unhashable.
+func (dict *HashDict) Get(key interface{}, defaultVal interface{}) (
+	interface{}, error) {
+
+	e, err := dict.entry(key)
+	if err != nil {
+		return defaultVal, err
+	}
+	if e == nil {
+		return defaultVal, nil
+	}
+	return e.value, nil
+}
+
+// HasKey returns true if key is in the dictionary, false otherwise.
+// Unhashable key is never in the dictionary.
+func (dict *HashDict) HasKey(key interface{}) bool {
+	e, _ := dict.entry(key)
+	return e != nil
+}
+
+// IsEqual returns true if dicts have the same keys with equal values
+// (compared with Equal), order doesn't matter, same as in Python.
+func (dict *HashDict) IsEqual(otherDict *HashDict) bool {
+	if dict.Len() != otherDict.Len() {
+		return false
+	}
+	for key, value := range dict.All() {
+		other, _ := otherDict.entry(key)
+		if other == nil || !Equal(value, other.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Items returns a list of the dictionary's [key, value] pairs,
+// in insertion order.
+func (dict *HashDict) Items() []List {
+	list := make([]List, 0, dict.Len())
+	for key, value := range dict.All() {
+		list = append(list, List{key, value})
+	}
+	return list
+}
+
+// IterKeys returns an iterator over keys of the dictionary,
+// in insertion order.
+func (dict *HashDict) IterKeys() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for key := range dict.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// IterValues returns an iterator over values of the dictionary,
+// in insertion order.
+func (dict *HashDict) IterValues() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, value := range dict.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a list of the dictionary's keys, in insertion order.
+func (dict *HashDict) Keys() List {
+	return CollectList(dict.IterKeys())
+}
+
+// Len returns the number of keys in the dictionary.
+func (dict *HashDict) Len() int {
+	return dict.entries.len()
+}
+
+// Pop returns value and remove the given key from the dictionary.
+// If the given key is NOT in the dictionary return defaultVal.
+// It returns *TypeError if key is unhashable.
+func (dict *HashDict) Pop(key interface{}, defaultVal interface{}) (
+	interface{}, error) {
+
+	if dict.Len() <= 0 {
+		return defaultVal, &KeyError{Key: key, Err: ErrRemoveFromEmptyDict}
+	}
+	e, err := dict.entry(key)
+	if err != nil {
+		return defaultVal, err
+	}
+	if e == nil {
+		return defaultVal, nil
+	}
+	dict.Delete(key)
+	return e.value, nil
+}
+
+// PopItem removes and returns the last added [key, value] pair,
+// same as Python's dict.popitem.
+func (dict *HashDict) PopItem() (List, error) {
+	if dict.Len() <= 0 {
+		return List{}, &KeyError{Err: ErrRemoveFromEmptyDict}
+	}
+	e := dict.entries.first(true)
+	dict.Delete(e.key)
+	return List{e.key, e.value}, nil
+}
+
+// Set sets value for the given key. New keys are added at the end,
+// existing keys keep their position and the key first used.
+// It returns *TypeError if key is unhashable.
+//
+//	d.Set(1, "a")
+//	d.Set(true, "b") // d = {1: b}
+func (dict *HashDict) Set(key interface{}, value interface{}) error {
+	hash, err := hashKey(key)
+	if err != nil {
+		return err
+	}
+	dict.entries.set(hash, key, value)
+	return nil
+}
+
+// SetDefault is like Get but will set key to defaultVal if key is not
+// already in the dictionary. It returns *TypeError if key is unhashable.
+func (dict *HashDict) SetDefault(key interface{}, defaultVal interface{}) (
+	interface{}, error) {
+
+	e, err := dict.entry(key)
+	if err != nil {
+		return defaultVal, err
+	}
+	if e != nil {
+		return e.value, nil
+	}
+	return defaultVal, dict.Set(key, defaultVal)
+}
+
+// String returns dictionary key-value pairs as string, in insertion order.
+//
+//	d.String() => "1: one, (x, 2): point"
+func (dict *HashDict) String() string {
+	out := make([]string, 0, dict.Len())
+	for key, value := range dict.All() {
+		out = append(out, fmt.Sprintf("%v: %v", key, value))
+	}
+	return strings.Join(out, ", ")
+}
+
+// Update updates the dictionary with the key-value pairs in the otherDict
+// dictionary replacing current values and adding new keys, in otherDict
+// order, at the end.
+func (dict *HashDict) Update(otherDict *HashDict) {
+	for key, value := range otherDict.All() {
+		dict.Set(key, value)
+	}
+}
+
+// UpdateDict is the same as Update for Dict, new keys are added in sorted
+// order.
+func (dict *HashDict) UpdateDict(otherDict Dict) {
+	dict.Update(HashDictFromDict(otherDict))
+}
+
+// Values returns a list of the dictionary's values, in insertion order.
+func (dict *HashDict) Values() List {
+	return CollectList(dict.IterValues())
+}
+
+//=============================================================================
+
+// entry returns entry for the given key or nil if key is NOT in
+// the dictionary.
+func (dict *HashDict) entry(key interface{}) (*listEntry[interface{}],
+	error) {
+
+	hash, err := hashKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return dict.entries.get(hash), nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestHashDictKeys(t *testing.T) {
+	dict := NewHashDict()
+	dict.Set(1, "int")
+	dict.Set("1", "str")
+	dict.Set(1.0, "float")
+	dict.Set(true, "bool")
+	dict.Set(Tuple{"a", "b"}, "tuple")
+	dict.Set(nil, "none")
+
+	if keys := dict.Keys(); !reflect.DeepEqual(keys,
+		List{1, "1", Tuple{"a", "b"}, nil}) {
+		t.Errorf("Keys() => %v, want [1 1 (a, b) <nil>]", keys)
+	}
+	if val, err := dict.Get(1, nil); val != "bool" || err != nil {
+		t.Errorf("Get(1) => %v, %v, want bool, nil", val, err)
+	}
+	if val, err := dict.Get(Tuple{"a", "b"}, nil); val != "tuple" ||
+		err != nil {
+		t.Errorf("Get((a, b)) => %v, %v, want tuple, nil", val, err)
+	}
+	if dict.HasKey("a, b") {
+		t.Errorf("HasKey(\"a, b\") => true, want false")
+	}
+	if dict.HasKey(List{"a", "b"}) {
+		t.Errorf("HasKey([a b]) => true, want false")
+	}
+
+	if err := dict.Set(List{1}, 0); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Set([1]) => %v, want %v", err, ErrUnhashable)
+	}
+	if _, err := dict.Get(Dict{}, 0); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Get({}) => %v, want %v", err, ErrUnhashable)
+	}
+	if err := dict.Delete(2); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete(2) => %v, want %v", err, ErrKeyNotFound)
+	}
+	if err := dict.Delete(uint8(1)); err != nil || dict.HasKey(1) {
+		t.Errorf("Delete(uint8(1)) => %v, key 1 still in dict", err)
+	}
+}
+
+func TestHashDictFromKeys(t *testing.T) {
+	dict, err := HashDictFromKeys(List{1, "1", 1.0, Tuple{1, 2}}, 0)
+	if err != nil || dict.Len() != 3 {
+		t.Errorf("HashDictFromKeys() => %v, %v, want 3 keys", dict, err)
+	}
+	if _, err := HashDictFromKeys(List{1, List{}}, 0); !errors.Is(err,
+		ErrUnhashable) {
+		t.Errorf("HashDictFromKeys([1 []]) => %v, want %v", err,
+			ErrUnhashable)
+	}
+}
+
+func TestHashDictPop(t *testing.T) {
+	dict, _ := HashDictFromKeys(List{"a", 2, 3.5}, 0)
+
+	if item, err := dict.PopItem(); !reflect.DeepEqual(item,
+		List{3.5, 0}) || err != nil {
+		t.Errorf("PopItem() => %v, %v, want [3.5 0], nil", item, err)
+	}
+	if val, err := dict.Pop(2.0, nil); val != 0 || err != nil {
+		t.Errorf("Pop(2.0) => %v, %v, want 0, nil", val, err)
+	}
+	if val, err := dict.Pop("x", "none"); val != "none" || err != nil {
+		t.Errorf("Pop(x) => %v, %v, want none, nil", val, err)
+	}
+	if val, err := dict.SetDefault("b", 1); val != 1 || err != nil ||
+		!dict.HasKey("b") {
+		t.Errorf("SetDefault(b, 1) => %v, %v, want 1, nil", val, err)
+	}
+
+	dict.Clear()
+	if _, err := dict.PopItem(); !errors.Is(err, ErrRemoveFromEmptyDict) {
+		t.Errorf("PopItem() on empty dict => %v, want %v", err,
+			ErrRemoveFromEmptyDict)
+	}
+}
+
+func TestHashDictDeleteWhileIterating(t *testing.T) {
+	dict, _ := HashDictFromKeys(List{1, "b", 3, 4.5}, 0)
+	var keys List
+	for key := range dict.All() {
+		keys = append(keys, key)
+		dict.Delete(key)
+		if key == "b" {
+			dict.Delete(3)
+		}
+	}
+	if !reflect.DeepEqual(keys, List{1, "b", 4.5}) || dict.Len() != 0 {
+		t.Errorf("All() deleting keys => %v, %v, want [1 b 4.5], empty",
+			keys, dict)
+	}
+}
+
+func TestHashDictZeroValue(t *testing.T) {
+	var dict HashDict
+	if dict.Len() != 0 || dict.HasKey(1) || len(dict.Keys()) != 0 {
+		t.Errorf("zero HashDict => %v, want empty", dict.Keys())
+	}
+	if err := dict.Delete(1); err == nil {
+		t.Errorf("Delete(1) on zero HashDict => nil, want error")
+	}
+	if err := dict.Set(1, "a"); err != nil || dict.String() != "1: a" {
+		t.Errorf("zero HashDict Set(1, a) => %v, %v", err, dict.String())
+	}
+}
+
+func TestHashDictIsEqual(t *testing.T) {
+	a := HashDictFromDict(Dict{"x": 1, "y": List{2}})
+	b := NewHashDict()
+	b.Set("y", List{2.0})
+	b.Set("x", true)
+
+	if !a.IsEqual(b) || !Equal(a, b) {
+		t.Errorf("%v IsEqual %v => false, want true", a, b)
+	}
+	b.Set("z", 3)
+	if a.IsEqual(b) {
+		t.Errorf("%v IsEqual %v => true, want false", a, b)
+	}
+
+	a.UpdateDict(Dict{"z": 3.0})
+	if !a.IsEqual(b) {
+		t.Errorf("%v IsEqual %v after update => false, want true", a, b)
+	}
+	if s := b.String(); s != "y: 2, x: true, z: 3" {
+		t.Errorf("String() => %v, want y: 2, x: true, z: 3", s)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+//=============================================================================
+
+var hashKeyTests = []struct {
+	a    interface{}
+	b    interface{}
+	same bool
+}{
+	{1, 1.0, true},
+	{1, true, true},
+	{0, false, true},
+	{0, math.Copysign(0, -1), true},
+	{int8(5), uint64(5), true},
+	{uint64(math.MaxUint64), float64(math.MaxUint64), false},
+	{uint64(1 << 63), float64(1 << 63), true},
+	{1.5, 1.5, true},
+	{1, "1", false},
+	{"a", []byte("a"), false},
+	{nil, Tuple{}, false},
+	{Tuple{1, "a"}, Tuple{1.0, "a"}, true},
+	{Tuple{1, Tuple{2}}, Tuple{true, Tuple{2.0}}, true},
+	{Tuple{1, 2}, Tuple{Tuple{1, 2}}, false},
+	{Tuple{1}, 1, false},
+	{[2]int{1, 2}, [2]int{1, 2}, true},
+}
+
+func TestHashKey(t *testing.T) {
+	for index, ht := range hashKeyTests {
+		a, errA := hashKey(ht.a)
+		b, errB := hashKey(ht.b)
+		if errA != nil || errB != nil {
+			t.Errorf("%d. hashKey(%v), hashKey(%v) => errors %v, %v",
+				index, ht.a, ht.b, errA, errB)
+			continue
+		}
+		if same := a == b; same != ht.same {
+			t.Errorf("%d. hashKey(%v) == hashKey(%v) => %v, want %v",
+				index, ht.a, ht.b, same, ht.same)
+		}
+	}
+}
+
+var hashableTests = []struct {
+	in  interface{}
+	out bool
+}{
+	{1, true},
+	{"a", true},
+	{nil, true},
+	{Tuple{1, Tuple{"a"}}, true},
+	{struct{ a int }{1}, true},
+	{List{1}, false},
+	{Dict{}, false},
+	{Tuple{1, List{}}, false},
+	{[]int{1}, false},
+	{map[int]int{}, false},
+	{func() {}, false},
+	{struct{ a interface{} }{List{}}, false},
+}
+
+func TestHashable(t *testing.T) {
+	for index, ht := range hashableTests {
+		if out := Hashable(ht.in); out != ht.out {
+			t.Errorf("%d. Hashable(%v) => %v, want %v",
+				index, ht.in, out, ht.out)
+		}
+	}
+
+	_, err := hashKey(Tuple{1, List{}})
+	want := "unhashable type: list"
+	if !errors.Is(err, ErrUnhashable) || err.Error() != want {
+		t.Errorf("hashKey((1, [])) => error %v, want %v", err, want)
+	}
+}
+
+//=============================================================================
+
+func TestTuple(t *testing.T) {
+	tuple := Tuple{"a", 1, 1.0}
+
+	if Equal(tuple, tuple.ToList()) {
+		t.Errorf("Equal(%v, %v) => true, want false", tuple, tuple.ToList())
+	}
+	if !Equal(tuple, Tuple{"a", true, 1}) {
+		t.Errorf("Equal(%v, (a, true, 1)) => false, want true", tuple)
+	}
+	if c, err := Compare(Tuple{1, 2}, Tuple{1, 3}); c != -1 || err != nil {
+		t.Errorf("Compare((1, 2), (1, 3)) => %d, %v, want -1, nil", c, err)
+	}
+	if _, err := Compare(Tuple{1}, List{1}); !errors.Is(err, ErrUnorderable) {
+		t.Errorf("Compare((1,), [1]) => error %v, want %v", err,
+			ErrUnorderable)
+	}
+	if n := tuple.Count(1); n != 2 {
+		t.Errorf("Count(1) => %d, want 2", n)
+	}
+	if i, err := tuple.Index(1.0); i != 1 || err != nil {
+		t.Errorf("Index(1.0) => %d, %v, want 1, nil", i, err)
+	}
+	if val, err := tuple.At(-1); val != 1.0 || err != nil {
+		t.Errorf("At(-1) => %v, %v, want 1, nil", val, err)
+	}
+
+	var stringTests = []struct {
+		in  Tuple
+		out string
+	}{
+		{Tuple{}, "()"},
+		{Tuple{"a"}, "(a,)"},
+		{Tuple{"a", 2}, "(a, 2)"},
+	}
+	for index, st := range stringTests {
+		if out := st.in.String(); out != st.out {
+			t.Errorf("%d. String() => %v, want %v", index, out, st.out)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"iter"
+)
+
+// Heap methods work the same as Python's heapq module: the list is a binary
+// min-heap where list[k] <= list[2*k+1] and list[k] <= list[2*k+2], so
+// list[0] is always the smallest element. Elements are compared with
+// Compare, a comparison error is returned as is and may leave the heap
+// partially ordered.
+//
+//	h := listdict.List{5, 1, 3}
+//	h.Heapify()
+//	h.HeapPush(2)
+//	smallest, _ := h.HeapPop() // 1
+
+// Heapify transforms the list into a heap, in place, in linear time.
+func (list List) Heapify() error {
+	for i := len(list)/2 - 1; i >= 0; i-- {
+		if err := siftUp(list, i, lessValues); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HeapPop removes and returns the smallest element of the heap.
+func (list *List) HeapPop() (interface{}, error) {
+	if len(*list) <= 0 {
+		return nil, &IndexError{Index: 0, Err: ErrRemoveFromEmptyList}
+	}
+	last, _ := list.Pop()
+	if len(*list) == 0 {
+		return last, nil
+	}
+	smallest := (*list)[0]
+	(*list)[0] = last
+	return smallest, siftUp(*list, 0, lessValues)
+}
+
+// HeapPush adds value to the heap keeping the heap invariant.
+func (list *List) HeapPush(value interface{}) error {
+	*list = append(*list, value)
+	return siftDown(*list, 0, len(*list)-1, lessValues)
+}
+
+// HeapPushPop adds value to the heap, then removes and returns the smallest
+// element. It's faster than HeapPush followed by HeapPop.
+func (list List) HeapPushPop(value interface{}) (interface{}, error) {
+	if len(list) == 0 {
+		return value, nil
+	}
+	less, err := lessValues(list[0], value)
+	if err != nil || !less {
+		return value, err
+	}
+	value, list[0] = list[0], value
+	return value, siftUp(list, 0, lessValues)
+}
+
+// HeapReplace removes and returns the smallest element of the heap, then
+// adds value. The returned value may be larger than the value added.
+func (list List) HeapReplace(value interface{}) (interface{}, error) {
+	if len(list) <= 0 {
+		return nil, &IndexError{Index: 0, Err: ErrRemoveFromEmptyList}
+	}
+	smallest := list[0]
+	list[0] = value
+	return smallest, siftUp(list, 0, lessValues)
+}
+
+// NLargest returns a list with the n largest elements of the list,
+// same as Python's heapq.nlargest(n, list, key). It's equivalent to
+// list.Sorted(key, true) truncated to n elements.
+func (list List) NLargest(n int, key func(interface{}) interface{}) (
+	List, error) {
+
+	return firstSorted(list, n, key, true)
+}
+
+// NSmallest returns a list with the n smallest elements of the list,
+// same as Python's heapq.nsmallest(n, list, key). It's equivalent to
+// list.Sorted(key, false) truncated to n elements.
+func (list List) NSmallest(n int, key func(interface{}) interface{}) (
+	List, error) {
+
+	return firstSorted(list, n, key, false)
+}
+
+// Merge lazily merges sorted lists into a single sorted sequence, same as
+// Python's heapq.merge. If key is not nil elements are compared by
+// key(element), reverse means the lists are sorted from largest to smallest.
+// A comparison error is returned with nil value and ends the sequence.
+//
+//	for val, err := range listdict.Merge(nil, false, a, b) {
+//		...
+//	}
+func Merge(key func(interface{}) interface{}, reverse bool,
+	lists ...List) iter.Seq2[interface{}, error] {
+
+	type entry struct {
+		key   interface{}
+		list  int // index of the list, keeps merge stable
+		index int // index of the element in the list
+	}
+	keyOf := func(val interface{}) interface{} {
+		if key != nil {
+			return key(val)
+		}
+		return val
+	}
+	less := func(a, b entry) (bool, error) {
+		x, y := a.key, b.key
+		if reverse {
+			x, y = y, x
+		}
+		c, err := Compare(x, y)
+		if err != nil || c != 0 {
+			return c < 0, err
+		}
+		return a.list < b.list, nil
+	}
+
+	return func(yield func(interface{}, error) bool) {
+		heap := []entry{}
+		for i, list := range lists {
+			if len(list) == 0 {
+				continue
+			}
+			heap = append(heap, entry{keyOf(list[0]), i, 0})
+			if err := siftDown(heap, 0, len(heap)-1, less); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
+		for len(heap) > 0 {
+			top := heap[0]
+			list := lists[top.list]
+			if !yield(list[top.index], nil) {
+				return
+			}
+			if top.index+1 < len(list) {
+				heap[0] = entry{keyOf(list[top.index+1]), top.list, top.index + 1}
+			} else {
+				heap[0] = heap[len(heap)-1]
+				heap = heap[:len(heap)-1]
+			}
+			if len(heap) > 0 {
+				if err := siftUp(heap, 0, less); err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func lessValues(a, b interface{}) (bool, error) {
+	c, err := Compare(a, b)
+	return c < 0, err
+}
+
+// firstSorted returns first n elements of sorted list.
+func firstSorted(list List, n int, key func(interface{}) interface{},
+	reverse bool) (List, error) {
+
+	if n <= 0 {
+		return List{}, nil
+	}
+	sorted, err := sortedBy(list, key, reverse)
+	if err != nil {
+		return nil, err
+	}
+	if n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted, nil
+}
+
+// siftDown moves element at pos up towards start until its parent is not
+// larger, same as Python's heapq._siftdown.
+func siftDown[T any](heap []T, start, pos int,
+	less func(a, b T) (bool, error)) error {
+
+	item := heap[pos]
+	for pos > start {
+		parentPos := (pos - 1) / 2
+		parent := heap[parentPos]
+		isLess, err := less(item, parent)
+		if err != nil {
+			heap[pos] = item
+			return err
+		}
+		if !isLess {
+			break
+		}
+		heap[pos] = parent
+		pos = parentPos
+	}
+	heap[pos] = item
+	return nil
+}
+
+// siftUp moves element at pos down to a leaf following smaller children,
+// then sifts it back up, same as Python's heapq._siftup.
+func siftUp[T any](heap []T, pos int, less func(a, b T) (bool, error)) error {
+	end, start := len(heap), pos
+	item := heap[pos]
+	child := 2*pos + 1
+	for child < end {
+		if right := child + 1; right < end {
+			isLess, err := less(heap[child], heap[right])
+			if err != nil {
+				heap[pos] = item
+				return err
+			}
+			if !isLess {
+				child = right
+			}
+		}
+		heap[pos] = heap[child]
+		pos = child
+		child = 2*pos + 1
+	}
+	heap[pos] = item
+	return siftDown(heap, start, pos, less)
+}
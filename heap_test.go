@@ -0,0 +1,118 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestHeap(t *testing.T) {
+	heap := List{5, 1.5, 8, int8(3), 9, 2, uint(7)}
+	if err := heap.Heapify(); err != nil {
+		t.Fatalf("Heapify() => %v", err)
+	}
+	if err := heap.HeapPush(4); err != nil {
+		t.Fatalf("HeapPush(4) => %v", err)
+	}
+
+	var out List
+	for len(heap) > 0 {
+		val, err := heap.HeapPop()
+		if err != nil {
+			t.Fatalf("HeapPop() => %v", err)
+		}
+		out = append(out, val)
+	}
+	want := List{1.5, 2, int8(3), 4, 5, uint(7), 8, 9}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("heap sort => %v, want %v", out, want)
+	}
+
+	if _, err := heap.HeapPop(); !errors.Is(err, ErrRemoveFromEmptyList) {
+		t.Errorf("HeapPop() on empty heap => %v, want %v",
+			err, ErrRemoveFromEmptyList)
+	}
+	if err := (&List{1}).HeapPush("one"); !errors.Is(err, ErrUnorderable) {
+		t.Errorf("HeapPush(\"one\") => %v, want %v", err, ErrUnorderable)
+	}
+}
+
+func TestHeapPushPopReplace(t *testing.T) {
+	heap := List{1, 3, 5}
+
+	if val, err := heap.HeapPushPop(0); val != 0 || err != nil {
+		t.Errorf("HeapPushPop(0) => %v, %v, want 0, nil", val, err)
+	}
+	if val, err := heap.HeapPushPop(4); val != 1 || err != nil {
+		t.Errorf("HeapPushPop(4) => %v, %v, want 1, nil", val, err)
+	}
+	if val, err := heap.HeapReplace(0); val != 3 || err != nil {
+		t.Errorf("HeapReplace(0) => %v, %v, want 3, nil", val, err)
+	}
+	if heap[0] != 0 {
+		t.Errorf("heap[0] => %v, want 0", heap[0])
+	}
+	if _, err := (List{}).HeapReplace(1); !errors.Is(err, ErrRemoveFromEmptyList) {
+		t.Errorf("HeapReplace() on empty heap => %v", err)
+	}
+}
+
+func TestNLargestNSmallest(t *testing.T) {
+	list := List{5, 1, 8, 3, 9, 2}
+
+	if out, _ := list.NLargest(3, nil); !reflect.DeepEqual(out, List{9, 8, 5}) {
+		t.Errorf("%v.NLargest(3) => %v, want [9 8 5]", list, out)
+	}
+	if out, _ := list.NSmallest(2, nil); !reflect.DeepEqual(out, List{1, 2}) {
+		t.Errorf("%v.NSmallest(2) => %v, want [1 2]", list, out)
+	}
+	if out, _ := list.NSmallest(10, nil); len(out) != len(list) {
+		t.Errorf("%v.NSmallest(10) => %v, want all elements", list, out)
+	}
+
+	words := List{"ccc", "a", "bb", "dd"}
+	length := func(v interface{}) interface{} { return len(v.(string)) }
+	if out, _ := words.NLargest(2, length); !reflect.DeepEqual(out,
+		List{"ccc", "bb"}) {
+		t.Errorf("%v.NLargest(2, len) => %v, want [ccc bb]", words, out)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var out List
+	for val, err := range Merge(nil, false,
+		List{1, 4, 7}, List{}, List{2, 5.0, 8}, List{3, 5, 9}) {
+		if err != nil {
+			t.Fatalf("Merge() => %v", err)
+		}
+		out = append(out, val)
+	}
+	want := List{1, 2, 3, 4, 5.0, 5, 7, 8, 9}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Merge() => %v, want %v", out, want)
+	}
+
+	out = nil
+	for val := range Merge(nil, true, List{9, 3}, List{8, 1}) {
+		out = append(out, val)
+	}
+	if want := (List{9, 8, 3, 1}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Merge(reverse) => %v, want %v", out, want)
+	}
+
+	var lastErr error
+	for _, err := range Merge(nil, false, List{1}, List{"a"}) {
+		lastErr = err
+	}
+	if !errors.Is(lastErr, ErrUnorderable) {
+		t.Errorf("Merge([1], [a]) => %v, want %v", lastErr, ErrUnorderable)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package container has helpers shared by listdict and its encoding
+// packages for walking nested containers.
+package container
+
+import (
+	"reflect"
+)
+
+// MaxDepth is the maximum nesting of decoded containers, same as in
+// encoding/json. Decoders return an error for deeper input instead of
+// overflowing the stack.
+const MaxDepth = 10000
+
+// ID identifies a slice, map or pointer by its type and memory, same as
+// Python's id(). Use it to find shared containers and cycles.
+type ID struct {
+	typ    reflect.Type
+	ptr    uintptr
+	length int
+}
+
+// Identity returns ID of a non-empty slice or a non-nil map or pointer.
+// Other values have no identity. Empty slices can share memory, so they
+// have none either.
+func Identity(val reflect.Value) (ID, bool) {
+	switch val.Kind() {
+	case reflect.Slice:
+		if val.Len() > 0 {
+			return ID{val.Type(), val.Pointer(), val.Len()}, true
+		}
+	case reflect.Map, reflect.Pointer:
+		if !val.IsNil() {
+			return ID{val.Type(), val.Pointer(), 0}, true
+		}
+	}
+	return ID{}, false
+}
@@ -0,0 +1,39 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentity(t *testing.T) {
+	list := []int{1, 2, 3}
+	dict := map[string]int{}
+	n := 1
+	values := []interface{}{list, list[:2], dict, &n, []int{}, 1, "a",
+		map[string]int(nil), (*int)(nil)}
+	want := []bool{true, true, true, true, false, false, false, false, false}
+
+	ids := map[ID]int{}
+	for index, value := range values {
+		id, ok := Identity(reflect.ValueOf(value))
+		if ok != want[index] {
+			t.Errorf("%d. Identity(%v) => %v, want %v", index, value, ok,
+				want[index])
+		}
+		if ok {
+			ids[id]++
+		}
+	}
+	if len(ids) != 4 {
+		t.Errorf("Identity() gave %d different ids, want 4", len(ids))
+	}
+	if id, _ := Identity(reflect.ValueOf(dict)); ids[id] != 1 {
+		t.Errorf("Identity(map) => %v, want the same id", id)
+	}
+}
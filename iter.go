@@ -0,0 +1,211 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"iter"
+)
+
+// CollectList returns a new List with all values from seq.
+//
+//	keys := listdict.CollectList(dict.IterKeys())
+func CollectList[T any](seq iter.Seq[T]) List {
+	list := List{}
+	for val := range seq {
+		list = append(list, val)
+	}
+	return list
+}
+
+// CollectTypedList returns a new TypedList with all values from seq.
+func CollectTypedList[T any](seq iter.Seq[T]) TypedList[T] {
+	list := TypedList[T]{}
+	for val := range seq {
+		list = append(list, val)
+	}
+	return list
+}
+
+// CollectDict returns a new Dict with all key-value pairs from seq.
+// If a key repeats the last value wins.
+func CollectDict[V any](seq iter.Seq2[string, V]) Dict {
+	dict := NewDict()
+	for key, val := range seq {
+		dict[key] = val
+	}
+	return dict
+}
+
+// CollectTypedDict returns a new TypedDict with all key-value pairs
+// from seq. If a key repeats the last value wins.
+func CollectTypedDict[K comparable, V any](seq iter.Seq2[K, V]) TypedDict[K, V] {
+	dict := NewTypedDict[K, V]()
+	for key, val := range seq {
+		dict[key] = val
+	}
+	return dict
+}
+
+//=============================================================================
+
+// All returns an iterator over index-value pairs of the list.
+//
+//	for i, val := range list.All() {
+//		...
+//	}
+func (list List) All() iter.Seq2[int, interface{}] {
+	return all(list)
+}
+
+// Backward returns an iterator over index-value pairs of the list,
+// from the last element to the first one.
+func (list List) Backward() iter.Seq2[int, interface{}] {
+	return backward(list)
+}
+
+// Enumerate returns an iterator over values of the list numbered from
+// start, same as Python's enumerate(l, start).
+func (list List) Enumerate(start int) iter.Seq2[int, interface{}] {
+	return enumerate(list, start)
+}
+
+// Iter returns an iterator over values of the list, same as Python's iter(l).
+func (list List) Iter() iter.Seq[interface{}] {
+	return values(list)
+}
+
+// All returns an iterator over index-value pairs of the list.
+func (list TypedList[T]) All() iter.Seq2[int, T] {
+	return all(list)
+}
+
+// Backward returns an iterator over index-value pairs of the list,
+// from the last element to the first one.
+func (list TypedList[T]) Backward() iter.Seq2[int, T] {
+	return backward(list)
+}
+
+// Enumerate returns an iterator over values of the list numbered from
+// start, same as Python's enumerate(l, start).
+func (list TypedList[T]) Enumerate(start int) iter.Seq2[int, T] {
+	return enumerate(list, start)
+}
+
+// Iter returns an iterator over values of the list, same as Python's iter(l).
+func (list TypedList[T]) Iter() iter.Seq[T] {
+	return values(list)
+}
+
+//=============================================================================
+
+// All returns an iterator over key-value pairs of the dictionary,
+// unordered. It's the same as Items but without allocating new lists.
+//
+//	for key, val := range dict.All() {
+//		...
+//	}
+func (dict Dict) All() iter.Seq2[string, interface{}] {
+	return dictAll(dict)
+}
+
+// IterKeys returns an iterator over keys of the dictionary, unordered.
+func (dict Dict) IterKeys() iter.Seq[string] {
+	return dictKeys(dict)
+}
+
+// IterValues returns an iterator over values of the dictionary, unordered.
+func (dict Dict) IterValues() iter.Seq[interface{}] {
+	return dictValues(dict)
+}
+
+// All returns an iterator over key-value pairs of the dictionary,
+// unordered.
+func (dict TypedDict[K, V]) All() iter.Seq2[K, V] {
+	return dictAll(dict)
+}
+
+// IterKeys returns an iterator over keys of the dictionary, unordered.
+func (dict TypedDict[K, V]) IterKeys() iter.Seq[K] {
+	return dictKeys(dict)
+}
+
+// IterValues returns an iterator over values of the dictionary, unordered.
+func (dict TypedDict[K, V]) IterValues() iter.Seq[V] {
+	return dictValues(dict)
+}
+
+//=============================================================================
+
+func all[S ~[]T, T any](s S) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, val := range s {
+			if !yield(i, val) {
+				return
+			}
+		}
+	}
+}
+
+func backward[S ~[]T, T any](s S) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := len(s) - 1; i >= 0; i-- {
+			if !yield(i, s[i]) {
+				return
+			}
+		}
+	}
+}
+
+func enumerate[S ~[]T, T any](s S, start int) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, val := range s {
+			if !yield(start+i, val) {
+				return
+			}
+		}
+	}
+}
+
+func values[S ~[]T, T any](s S) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, val := range s {
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+func dictAll[M ~map[K]V, K comparable, V any](m M) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, val := range m {
+			if !yield(key, val) {
+				return
+			}
+		}
+	}
+}
+
+func dictKeys[M ~map[K]V, K comparable, V any](m M) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range m {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+func dictValues[M ~map[K]V, K comparable, V any](m M) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, val := range m {
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
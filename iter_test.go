@@ -0,0 +1,89 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+//=============================================================================
+
+func TestListIterators(t *testing.T) {
+	list := List{"a", "b", "c"}
+
+	var indexes []int
+	var vals List
+	for i, val := range list.All() {
+		indexes = append(indexes, i)
+		vals = append(vals, val)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 1, 2}) ||
+		!reflect.DeepEqual(vals, list) {
+		t.Errorf("%v.All() => %v %v", list, indexes, vals)
+	}
+
+	indexes, vals = nil, nil
+	for i, val := range list.Backward() {
+		indexes = append(indexes, i)
+		vals = append(vals, val)
+	}
+	if !reflect.DeepEqual(indexes, []int{2, 1, 0}) ||
+		!reflect.DeepEqual(vals, List{"c", "b", "a"}) {
+		t.Errorf("%v.Backward() => %v %v", list, indexes, vals)
+	}
+
+	indexes = nil
+	for i, val := range list.Enumerate(1) {
+		if val == "b" {
+			break
+		}
+		indexes = append(indexes, i)
+	}
+	if !reflect.DeepEqual(indexes, []int{1}) {
+		t.Errorf("%v.Enumerate(1) with break => %v, want [1]", list, indexes)
+	}
+
+	if out := CollectList(list.Iter()); !reflect.DeepEqual(out, list) {
+		t.Errorf("CollectList(%v.Iter()) => %v", list, out)
+	}
+
+	typed := TypedList[int]{1, 2}
+	if out := CollectTypedList(typed.Iter()); !out.IsEqual(typed) {
+		t.Errorf("CollectTypedList(%v.Iter()) => %v", typed, out)
+	}
+}
+
+//=============================================================================
+
+func TestDictIterators(t *testing.T) {
+	dict := Dict{"one": 1, "two": 2, "three": 3}
+
+	if out := CollectDict(dict.All()); !out.IsEqual(dict) {
+		t.Errorf("CollectDict(%v.All()) => %v", dict, out)
+	}
+
+	var keys []string
+	for key := range dict.IterKeys() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"one", "three", "two"}) {
+		t.Errorf("%v.IterKeys() => %v", dict, keys)
+	}
+
+	values, err := CollectList(dict.IterValues()).Sorted(nil, false)
+	if err != nil || !reflect.DeepEqual(values, List{1, 2, 3}) {
+		t.Errorf("%v.IterValues() => %v, %v", dict, values, err)
+	}
+
+	typed := TypedDict[int, string]{1: "one"}
+	if out := CollectTypedDict(typed.All()); !out.IsEqual(typed) {
+		t.Errorf("CollectTypedDict(%v.All()) => %v", typed, out)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package itertools
+
+import (
+	"iter"
+
+	"github.com/gosimple/listdict"
+)
+
+// Combinations returns r length subsequences of elements from the list,
+// in lexicographic order of positions. Elements are treated as unique
+// based on their position, not on their value.
+// It panics if r is negative.
+//
+//	itertools.Combinations(listdict.List{"A", "B", "C"}, 2) => AB AC BC
+func Combinations(list listdict.List, r int) iter.Seq[listdict.List] {
+	if r < 0 {
+		panic(&listdict.ValueError{Value: r, Err: ErrNegativeArgument})
+	}
+	pool := append(listdict.List{}, list...)
+	n := len(pool)
+	return func(yield func(listdict.List) bool) {
+		if r > n {
+			return
+		}
+		indices := make([]int, r)
+		for i := range indices {
+			indices[i] = i
+		}
+		if !yield(pick(pool, indices)) {
+			return
+		}
+		for {
+			i := r - 1
+			for ; i >= 0 && indices[i] == i+n-r; i-- {
+			}
+			if i < 0 {
+				return
+			}
+			indices[i]++
+			for j := i + 1; j < r; j++ {
+				indices[j] = indices[j-1] + 1
+			}
+			if !yield(pick(pool, indices)) {
+				return
+			}
+		}
+	}
+}
+
+// CombinationsWithReplacement returns r length subsequences of elements
+// from the list allowing individual elements to be repeated.
+// It panics if r is negative.
+//
+//	itertools.CombinationsWithReplacement(listdict.List{"A", "B"}, 2)
+//		=> AA AB BB
+func CombinationsWithReplacement(list listdict.List,
+	r int) iter.Seq[listdict.List] {
+
+	if r < 0 {
+		panic(&listdict.ValueError{Value: r, Err: ErrNegativeArgument})
+	}
+	pool := append(listdict.List{}, list...)
+	n := len(pool)
+	return func(yield func(listdict.List) bool) {
+		if n == 0 && r > 0 {
+			return
+		}
+		indices := make([]int, r)
+		if !yield(pick(pool, indices)) {
+			return
+		}
+		for {
+			i := r - 1
+			for ; i >= 0 && indices[i] == n-1; i-- {
+			}
+			if i < 0 {
+				return
+			}
+			val := indices[i] + 1
+			for j := i; j < r; j++ {
+				indices[j] = val
+			}
+			if !yield(pick(pool, indices)) {
+				return
+			}
+		}
+	}
+}
+
+// Permutations returns r length permutations of elements from the list.
+// Use listdict.Omit as r to get full length permutations.
+// It panics if r is negative.
+//
+//	itertools.Permutations(listdict.List{"A", "B", "C"}, 2)
+//		=> AB AC BA BC CA CB
+func Permutations(list listdict.List, r int) iter.Seq[listdict.List] {
+	pool := append(listdict.List{}, list...)
+	n := len(pool)
+	if r == listdict.Omit {
+		r = n
+	}
+	if r < 0 {
+		panic(&listdict.ValueError{Value: r, Err: ErrNegativeArgument})
+	}
+	return func(yield func(listdict.List) bool) {
+		if r > n {
+			return
+		}
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		cycles := make([]int, r)
+		for i := range cycles {
+			cycles[i] = n - i
+		}
+		if !yield(pick(pool, indices[:r])) {
+			return
+		}
+		for n > 0 {
+			i := r - 1
+			for ; i >= 0; i-- {
+				cycles[i]--
+				if cycles[i] == 0 {
+					first := indices[i]
+					copy(indices[i:], indices[i+1:])
+					indices[n-1] = first
+					cycles[i] = n - i
+					continue
+				}
+				j := cycles[i]
+				indices[i], indices[n-j] = indices[n-j], indices[i]
+				if !yield(pick(pool, indices[:r])) {
+					return
+				}
+				break
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// Product returns cartesian product of the lists, same as nested for-loops.
+// To compute the product of a list with itself pass it many times.
+//
+//	itertools.Product(listdict.List{"A", "B"}, listdict.List{1, 2})
+//		=> A1 A2 B1 B2
+func Product(lists ...listdict.List) iter.Seq[listdict.List] {
+	pools := make([]listdict.List, len(lists))
+	for i, list := range lists {
+		pools[i] = append(listdict.List{}, list...)
+	}
+	return func(yield func(listdict.List) bool) {
+		for _, pool := range pools {
+			if len(pool) == 0 {
+				return
+			}
+		}
+		indices := make([]int, len(pools))
+		for {
+			row := make(listdict.List, len(pools))
+			for i, index := range indices {
+				row[i] = pools[i][index]
+			}
+			if !yield(row) {
+				return
+			}
+
+			i := len(indices) - 1
+			for ; i >= 0; i-- {
+				indices[i]++
+				if indices[i] < len(pools[i]) {
+					break
+				}
+				indices[i] = 0
+			}
+			if i < 0 {
+				return
+			}
+		}
+	}
+}
+
+// pick returns a new list with pool elements at given indices.
+func pick(pool listdict.List, indices []int) listdict.List {
+	out := make(listdict.List, len(indices))
+	for i, index := range indices {
+		out[i] = pool[index]
+	}
+	return out
+}
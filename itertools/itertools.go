@@ -0,0 +1,397 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package itertools brings Python's itertools to listdict.List.
+
+All functions return lazy iterators, nothing is computed until the result
+is ranged over. Use listdict.CollectList to get the result as a List.
+
+	pairs := itertools.Combinations(listdict.List{1, 2, 3}, 2)
+	for pair := range pairs {
+		// pair is listdict.List{1, 2}, then {1, 3}, then {2, 3}
+	}
+	all := listdict.CollectList(itertools.Chain(a.Iter(), b.Iter()))
+
+Same as in Go's slices.Chunk, invalid arguments like a negative size
+cause a panic (with *listdict.ValueError), not an error.
+*/
+package itertools
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/gosimple/listdict"
+)
+
+var (
+	// ErrNegativeArgument is used when user want to pass negative size
+	// or index where it's not allowed
+	ErrNegativeArgument = errors.New("argument must be non-negative")
+	// ErrZeroArgument is used when user want to pass zero size or step
+	// where it's not allowed
+	ErrZeroArgument = errors.New("argument must be greater than zero")
+)
+
+type seq = iter.Seq[interface{}]
+
+//=============================================================================
+
+// Accumulate returns running results of f applied to the elements of seq,
+// same as Python's accumulate(seq, f, initial=initial).
+// If initial is given it's the first returned value.
+//
+//	add := func(a, b interface{}) interface{} { return a.(int) + b.(int) }
+//	itertools.Accumulate(listdict.List{1, 2, 3}.Iter(), add) => 1 3 6
+func Accumulate(values seq, f func(acc, val interface{}) interface{},
+	initial ...interface{}) seq {
+
+	return func(yield func(interface{}) bool) {
+		var acc interface{}
+		started := false
+		if len(initial) > 0 {
+			acc, started = initial[0], true
+			if !yield(acc) {
+				return
+			}
+		}
+		for val := range values {
+			if started {
+				acc = f(acc, val)
+			} else {
+				acc, started = val, true
+			}
+			if !yield(acc) {
+				return
+			}
+		}
+	}
+}
+
+// Batched returns elements of seq in lists of size n, the last list
+// can be shorter. It panics if n is less than 1.
+func Batched(values seq, n int) iter.Seq[listdict.List] {
+	if n < 1 {
+		panic(&listdict.ValueError{Value: n, Err: ErrZeroArgument})
+	}
+	return func(yield func(listdict.List) bool) {
+		batch := make(listdict.List, 0, n)
+		for val := range values {
+			batch = append(batch, val)
+			if len(batch) == n {
+				if !yield(batch) {
+					return
+				}
+				batch = make(listdict.List, 0, n)
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// Chain returns elements of the first sequence, then of the second one
+// and so on.
+func Chain(seqs ...seq) seq {
+	return func(yield func(interface{}) bool) {
+		for _, values := range seqs {
+			for val := range values {
+				if !yield(val) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Compress returns elements of data for which the corresponding selector
+// is true (see listdict.Truth). It stops when either sequence ends.
+func Compress(data, selectors seq) seq {
+	return func(yield func(interface{}) bool) {
+		next, stop := iter.Pull(selectors)
+		defer stop()
+		for val := range data {
+			selector, ok := next()
+			if !ok {
+				return
+			}
+			if listdict.Truth(selector) && !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// Cycle returns elements of seq, then repeats them indefinitely.
+// Elements are saved during the first pass, seq is ranged over once.
+func Cycle(values seq) seq {
+	return func(yield func(interface{}) bool) {
+		var saved listdict.List
+		for val := range values {
+			if !yield(val) {
+				return
+			}
+			saved = append(saved, val)
+		}
+		if len(saved) == 0 {
+			return
+		}
+		for {
+			for _, val := range saved {
+				if !yield(val) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// DropWhile skips elements of seq as long as pred is true, then returns
+// every remaining element.
+func DropWhile(pred func(interface{}) bool, values seq) seq {
+	return func(yield func(interface{}) bool) {
+		dropping := true
+		for val := range values {
+			if dropping && pred(val) {
+				continue
+			}
+			dropping = false
+			if !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// GroupBy returns consecutive keys and groups of elements with that key.
+// If key is nil the element itself is the key. Keys are compared with
+// listdict.Equal. Same as in Python only consecutive elements are grouped,
+// so seq usually needs to be sorted by the same key.
+func GroupBy(values seq, key func(interface{}) interface{}) iter.Seq2[
+	interface{}, listdict.List] {
+
+	if key == nil {
+		key = func(val interface{}) interface{} { return val }
+	}
+	return func(yield func(interface{}, listdict.List) bool) {
+		var groupKey interface{}
+		var group listdict.List
+		for val := range values {
+			valKey := key(val)
+			if group != nil && !listdict.Equal(valKey, groupKey) {
+				if !yield(groupKey, group) {
+					return
+				}
+				group = nil
+			}
+			if group == nil {
+				groupKey = valKey
+			}
+			group = append(group, val)
+		}
+		if group != nil {
+			yield(groupKey, group)
+		}
+	}
+}
+
+// ISlice returns selected elements of seq, same as Python's
+// islice(seq, start, stop, step). Use listdict.Omit for stop to go until
+// the end of seq and for step to take every element. It panics if start
+// or stop is negative or step is less than 1.
+func ISlice(values seq, start, stop, step int) seq {
+	if step == listdict.Omit {
+		step = 1
+	}
+	switch {
+	case start < 0:
+		panic(&listdict.ValueError{Value: start, Err: ErrNegativeArgument})
+	case stop < 0 && stop != listdict.Omit:
+		panic(&listdict.ValueError{Value: stop, Err: ErrNegativeArgument})
+	case step < 1:
+		panic(&listdict.ValueError{Value: step, Err: ErrZeroArgument})
+	}
+	return func(yield func(interface{}) bool) {
+		if stop != listdict.Omit && start >= stop {
+			return
+		}
+		index := 0
+		for val := range values {
+			if index >= start && (index-start)%step == 0 && !yield(val) {
+				return
+			}
+			index++
+			if stop != listdict.Omit && index >= stop {
+				return
+			}
+		}
+	}
+}
+
+// Pairwise returns successive overlapping pairs of elements of seq.
+//
+//	itertools.Pairwise(listdict.List{1, 2, 3}.Iter()) => (1, 2) (2, 3)
+func Pairwise(values seq) iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		var prev interface{}
+		started := false
+		for val := range values {
+			if started && !yield(prev, val) {
+				return
+			}
+			prev, started = val, true
+		}
+	}
+}
+
+// TakeWhile returns elements of seq as long as pred is true.
+func TakeWhile(pred func(interface{}) bool, values seq) seq {
+	return func(yield func(interface{}) bool) {
+		for val := range values {
+			if !pred(val) || !yield(val) {
+				return
+			}
+		}
+	}
+}
+
+// Tee returns n independent iterators over seq. seq is ranged over once,
+// elements are buffered until every iterator has returned them. seq is
+// stopped when it ends or every iterator was stopped early, iterators
+// ranged over again after that return only buffered elements.
+//
+// seq is read with iter.Pull, so until it is stopped its goroutine and
+// the buffer are kept alive. Range every returned iterator to the end or
+// break out of it; an iterator that is never ranged keeps them forever.
+// The iterators must not be used from many goroutines at the same time.
+func Tee(values seq, n int) []seq {
+	if n < 0 {
+		panic(&listdict.ValueError{Value: n, Err: ErrNegativeArgument})
+	}
+	shared := &teeBuffer{source: values, positions: make([]int, n),
+		released: make([]bool, n)}
+	out := make([]seq, n)
+	for i := range out {
+		out[i] = shared.iterator(i)
+	}
+	return out
+}
+
+// ZipLongest returns lists with one element from every sequence until
+// the longest one ends, missing elements are set to fill.
+func ZipLongest(fill interface{}, seqs ...seq) iter.Seq[listdict.List] {
+	return func(yield func(listdict.List) bool) {
+		nexts := make([]func() (interface{}, bool), len(seqs))
+		for i, values := range seqs {
+			next, stop := iter.Pull(values)
+			defer stop()
+			nexts[i] = next
+		}
+		active := len(seqs)
+		for active > 0 {
+			row := make(listdict.List, len(nexts))
+			for i, next := range nexts {
+				if next == nil {
+					row[i] = fill
+					continue
+				}
+				val, ok := next()
+				if !ok {
+					nexts[i] = nil
+					active--
+					val = fill
+				}
+				row[i] = val
+			}
+			if active == 0 || !yield(row) {
+				return
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+// teeBuffer keeps elements of source that weren't returned by all
+// iterators yet.
+type teeBuffer struct {
+	source    seq
+	next      func() (interface{}, bool)
+	stop      func()
+	done      bool
+	buffer    listdict.List
+	offset    int    // position of buffer[0] in source
+	positions []int  // position of every iterator in source
+	released  []bool // iterators stopped early
+}
+
+func (tee *teeBuffer) iterator(id int) seq {
+	return func(yield func(interface{}) bool) {
+		tee.released[id] = false
+		for {
+			val, ok := tee.get(id)
+			if !ok {
+				return
+			}
+			if !yield(val) {
+				tee.release(id)
+				return
+			}
+		}
+	}
+}
+
+// release marks iterator id as stopped early, source is stopped when
+// every iterator is.
+func (tee *teeBuffer) release(id int) {
+	tee.released[id] = true
+	for _, released := range tee.released {
+		if !released {
+			return
+		}
+	}
+	if tee.next != nil && !tee.done {
+		tee.done = true
+		tee.stop()
+	}
+}
+
+// get returns next element for iterator id.
+func (tee *teeBuffer) get(id int) (interface{}, bool) {
+	pos := tee.positions[id]
+	if pos-tee.offset >= len(tee.buffer) {
+		if tee.done {
+			return nil, false
+		}
+		if tee.next == nil {
+			tee.next, tee.stop = iter.Pull(tee.source)
+		}
+		val, ok := tee.next()
+		if !ok {
+			tee.done = true
+			tee.stop()
+			return nil, false
+		}
+		tee.buffer = append(tee.buffer, val)
+	}
+	val := tee.buffer[pos-tee.offset]
+	tee.positions[id]++
+
+	// Drop elements every iterator already returned
+	min := tee.positions[0]
+	for _, p := range tee.positions {
+		if p < min {
+			min = p
+		}
+	}
+	if drop := min - tee.offset; drop > 0 {
+		tee.buffer = tee.buffer[drop:]
+		tee.offset = min
+	}
+	return val, true
+}
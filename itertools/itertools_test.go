@@ -0,0 +1,267 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package itertools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gosimple/listdict"
+)
+
+type L = listdict.List
+
+//=============================================================================
+
+func TestAccumulate(t *testing.T) {
+	add := func(a, b interface{}) interface{} { return a.(int) + b.(int) }
+
+	out := listdict.CollectList(Accumulate(L{1, 2, 3, 4}.Iter(), add))
+	if want := (L{1, 3, 6, 10}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Accumulate([1 2 3 4], add) => %v, want %v", out, want)
+	}
+	out = listdict.CollectList(Accumulate(L{1, 2}.Iter(), add, 100))
+	if want := (L{100, 101, 103}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Accumulate([1 2], add, 100) => %v, want %v", out, want)
+	}
+}
+
+func TestBatched(t *testing.T) {
+	out := listdict.CollectList(Batched(L{1, 2, 3, 4, 5}.Iter(), 2))
+	if want := (L{L{1, 2}, L{3, 4}, L{5}}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Batched([1 2 3 4 5], 2) => %v, want %v", out, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Batched(seq, 0) should panic")
+		}
+	}()
+	Batched(L{}.Iter(), 0)
+}
+
+func TestChainCompress(t *testing.T) {
+	out := listdict.CollectList(Chain(L{1, 2}.Iter(), L{}.Iter(), L{3}.Iter()))
+	if want := (L{1, 2, 3}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Chain([1 2], [], [3]) => %v, want %v", out, want)
+	}
+
+	out = listdict.CollectList(
+		Compress(L{"A", "B", "C", "D"}.Iter(), L{1, 0, true}.Iter()))
+	if want := (L{"A", "C"}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Compress(ABCD, [1 0 true]) => %v, want %v", out, want)
+	}
+
+	stopped := false
+	selectors := func(yield func(interface{}) bool) {
+		defer func() { stopped = true }()
+		for yield(true) {
+		}
+	}
+	for range Compress(L{1, 2}.Iter(), selectors) {
+		break
+	}
+	if !stopped {
+		t.Errorf("Compress() didn't stop selectors after break")
+	}
+}
+
+func TestCycle(t *testing.T) {
+	out := listdict.CollectList(ISlice(Cycle(L{1, 2}.Iter()), 0, 5, 1))
+	if want := (L{1, 2, 1, 2, 1}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Cycle([1 2])[:5] => %v, want %v", out, want)
+	}
+	if out := listdict.CollectList(Cycle(L{}.Iter())); len(out) != 0 {
+		t.Errorf("Cycle([]) => %v, want []", out)
+	}
+}
+
+func TestTakeDropWhile(t *testing.T) {
+	small := func(v interface{}) bool { return v.(int) < 3 }
+	in := L{1, 2, 3, 1, 4}
+
+	out := listdict.CollectList(TakeWhile(small, in.Iter()))
+	if want := (L{1, 2}); !reflect.DeepEqual(out, want) {
+		t.Errorf("TakeWhile(<3, %v) => %v, want %v", in, out, want)
+	}
+	out = listdict.CollectList(DropWhile(small, in.Iter()))
+	if want := (L{3, 1, 4}); !reflect.DeepEqual(out, want) {
+		t.Errorf("DropWhile(<3, %v) => %v, want %v", in, out, want)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	var keys, groups L
+	for key, group := range GroupBy(L{"a", "a", "b", "a", 1.0, 1}.Iter(), nil) {
+		keys = append(keys, key)
+		groups = append(groups, group)
+	}
+	if want := (L{"a", "b", "a", 1.0}); !reflect.DeepEqual(keys, want) {
+		t.Errorf("GroupBy() keys => %v, want %v", keys, want)
+	}
+	want := L{L{"a", "a"}, L{"b"}, L{"a"}, L{1.0, 1}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupBy() groups => %v, want %v", groups, want)
+	}
+}
+
+var isliceTests = []struct {
+	start, stop, step int
+	out               L
+}{
+	{0, 2, 1, L{0, 1}},
+	{2, 4, listdict.Omit, L{2, 3}},
+	{2, listdict.Omit, listdict.Omit, L{2, 3, 4, 5}},
+	{0, listdict.Omit, 2, L{0, 2, 4}},
+	{1, 5, 2, L{1, 3}},
+	{4, 2, 1, L{}},
+}
+
+func TestISlice(t *testing.T) {
+	in := L{0, 1, 2, 3, 4, 5}
+	for index, it := range isliceTests {
+		out := listdict.CollectList(ISlice(in.Iter(), it.start, it.stop, it.step))
+		if !reflect.DeepEqual(out, it.out) {
+			t.Errorf("%d. ISlice(%v, %d, %d, %d) => %v, want %v",
+				index, in, it.start, it.stop, it.step, out, it.out)
+		}
+	}
+}
+
+func TestPairwise(t *testing.T) {
+	var out L
+	for a, b := range Pairwise(L{1, 2, 3}.Iter()) {
+		out = append(out, L{a, b})
+	}
+	if want := (L{L{1, 2}, L{2, 3}}); !reflect.DeepEqual(out, want) {
+		t.Errorf("Pairwise([1 2 3]) => %v, want %v", out, want)
+	}
+}
+
+func TestTee(t *testing.T) {
+	pulled := 0
+	source := func(yield func(interface{}) bool) {
+		for i := 0; i < 3; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	its := Tee(source, 2)
+	first := listdict.CollectList(its[0])
+	second := listdict.CollectList(its[1])
+	if want := (L{0, 1, 2}); !reflect.DeepEqual(first, want) ||
+		!reflect.DeepEqual(second, want) {
+		t.Errorf("Tee() => %v, %v, want %v twice", first, second, want)
+	}
+	if pulled != 3 {
+		t.Errorf("Tee() ranged over source %d times, want 3", pulled)
+	}
+
+	stopped := false
+	source = func(yield func(interface{}) bool) {
+		defer func() { stopped = true }()
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	its = Tee(source, 2)
+	for range its[0] {
+		break
+	}
+	if stopped {
+		t.Errorf("Tee() stopped source before every iterator stopped")
+	}
+	for range its[1] {
+		break
+	}
+	if !stopped {
+		t.Errorf("Tee() didn't stop source after every iterator stopped")
+	}
+}
+
+func TestZipLongest(t *testing.T) {
+	out := listdict.CollectList(ZipLongest("-", L{1, 2, 3}.Iter(), L{"a"}.Iter()))
+	want := L{L{1, "a"}, L{2, "-"}, L{3, "-"}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("ZipLongest() => %v, want %v", out, want)
+	}
+	if out := listdict.CollectList(ZipLongest(nil)); len(out) != 0 {
+		t.Errorf("ZipLongest(nil) => %v, want []", out)
+	}
+}
+
+//=============================================================================
+
+var combinatoricsTests = []struct {
+	name string
+	seq  func() []L
+	out  []L
+}{
+	{"Combinations(ABC, 2)",
+		func() []L { return collect(Combinations(L{"A", "B", "C"}, 2)) },
+		[]L{{"A", "B"}, {"A", "C"}, {"B", "C"}}},
+	{"Combinations(AB, 3)",
+		func() []L { return collect(Combinations(L{"A", "B"}, 3)) },
+		nil},
+	{"Combinations(AB, 0)",
+		func() []L { return collect(Combinations(L{"A", "B"}, 0)) },
+		[]L{{}}},
+	{"CombinationsWithReplacement(AB, 2)",
+		func() []L { return collect(CombinationsWithReplacement(L{"A", "B"}, 2)) },
+		[]L{{"A", "A"}, {"A", "B"}, {"B", "B"}}},
+	{"Permutations(ABC, 2)",
+		func() []L { return collect(Permutations(L{"A", "B", "C"}, 2)) },
+		[]L{{"A", "B"}, {"A", "C"}, {"B", "A"}, {"B", "C"}, {"C", "A"},
+			{"C", "B"}}},
+	{"Permutations(123)",
+		func() []L { return collect(Permutations(L{1, 2, 3}, listdict.Omit)) },
+		[]L{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2},
+			{3, 2, 1}}},
+	{"Product(AB, 12)",
+		func() []L { return collect(Product(L{"A", "B"}, L{1, 2})) },
+		[]L{{"A", 1}, {"A", 2}, {"B", 1}, {"B", 2}}},
+	{"Product(AB, [])",
+		func() []L { return collect(Product(L{"A", "B"}, L{})) },
+		nil},
+	{"Product()",
+		func() []L { return collect(Product()) },
+		[]L{{}}},
+}
+
+func TestCombinatorics(t *testing.T) {
+	for _, ct := range combinatoricsTests {
+		if out := ct.seq(); !reflect.DeepEqual(out, ct.out) {
+			t.Errorf("%s => %v, want %v", ct.name, out, ct.out)
+		}
+	}
+}
+
+func TestCombinatoricsAreLazy(t *testing.T) {
+	big := make(L, 1000)
+	count := 0
+	for range Permutations(big, listdict.Omit) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Errorf("Permutations() stopped after %d results, want 3", count)
+	}
+}
+
+func collect(seq func(func(L) bool)) []L {
+	var out []L
+	for val := range seq {
+		out = append(out, val)
+	}
+	return out
+}
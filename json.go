@@ -0,0 +1,900 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// DumpsOptions are options of Dumps, same as keyword arguments of Python's
+// json.dumps. Use DefaultDumpsOptions to get Python defaults.
+type DumpsOptions struct {
+	// Indent is the number of spaces for each nesting level, Omit puts
+	// everything on one line (Python's indent=None)
+	Indent int
+	// SortKeys sorts keys of ordered dictionaries, like OrderedDict.
+	// Keys of Dict and Go maps are always sorted, they have no order.
+	SortKeys bool
+	// ItemSeparator and KeySeparator are Python's separators, empty
+	// values mean ", " (or "," with Indent) and ": "
+	ItemSeparator string
+	KeySeparator  string
+	// EnsureASCII escapes all non-ASCII characters
+	EnsureASCII bool
+	// AllowNaN writes NaN and infinities as NaN, Infinity and -Infinity,
+	// otherwise they return *ValueError
+	AllowNaN bool
+	// Default returns a serializable version of a value Dumps doesn't
+	// know, it can be nil
+	Default func(value interface{}) (interface{}, error)
+}
+
+// LoadsOptions are options of Loads, same as keyword arguments of Python's
+// json.loads. The zero value gives Python defaults.
+type LoadsOptions struct {
+	// ObjectPairsHook, if not nil, gets key-value pairs of every decoded
+	// object, in document order, and its result is used instead of Dict
+	ObjectPairsHook func(pairs []Pair[string, interface{}]) (
+		interface{}, error)
+	// ObjectHook, if not nil, gets every decoded object as Dict and its
+	// result is used instead
+	ObjectHook func(dict Dict) (interface{}, error)
+}
+
+var (
+	// ErrNotSerializable is returned when user want to encode value of
+	// a type JSON doesn't support
+	ErrNotSerializable = errors.New("object is not JSON serializable")
+	// ErrInvalidJSONKey is returned when user want to encode dictionary key
+	// that is not a string, number, bool or nil
+	ErrInvalidJSONKey = errors.New("keys must be str, int, float, bool or None")
+	// ErrFloatOutOfRange is returned when user want to encode NaN or
+	// infinity with AllowNaN disabled
+	ErrFloatOutOfRange = errors.New("float value is not JSON compliant")
+	// ErrCircularReference is returned when user want to encode value
+	// that contains itself
+	ErrCircularReference = errors.New("circular reference detected")
+	// ErrInvalidJSON is returned when user want to decode invalid JSON
+	ErrInvalidJSON = errors.New("invalid JSON")
+	// ErrInvalidNumber is returned when user want to encode json.Number
+	// that is not a valid JSON number
+	ErrInvalidNumber = errors.New("invalid number literal")
+)
+
+// JSONDecodeError is returned by Loads for invalid JSON, same as Python's
+// json.JSONDecodeError. It wraps ErrInvalidJSON.
+type JSONDecodeError struct {
+	Msg  string // what is wrong, e.g. "Expecting value"
+	Pos  int    // character (not byte) index where parsing failed
+	Line int    // line of Pos, from 1
+	Col  int    // column of Pos, from 1
+}
+
+func (e *JSONDecodeError) Error() string {
+	return fmt.Sprintf("%s: line %d column %d (char %d)", e.Msg, e.Line,
+		e.Col, e.Pos)
+}
+
+func (e *JSONDecodeError) Unwrap() error {
+	return ErrInvalidJSON
+}
+
+//=============================================================================
+
+// DefaultDumpsOptions returns options of Python's json.dumps with default
+// arguments.
+func DefaultDumpsOptions() *DumpsOptions {
+	return &DumpsOptions{Indent: Omit, EnsureASCII: true, AllowNaN: true}
+}
+
+// Dumps returns value encoded as JSON, byte-for-byte the same as Python's
+// json.dumps with the same options. If options is nil DefaultDumpsOptions
+// are used. Floats are written the same as Python's repr, big integers
+// can be *big.Int. json.Number and values implementing json.Marshaler or
+// encoding.TextMarshaler are written the same as by encoding/json.
+//
+//	listdict.Dumps(listdict.List{1, 2.0, "ż"}, nil) => `[1, 2.0, "ż"]`
+func Dumps(value interface{}, options *DumpsOptions) (string, error) {
+	if options == nil {
+		options = DefaultDumpsOptions()
+	}
+	e := &encoder{options: options, itemSep: options.ItemSeparator,
+		keySep: options.KeySeparator, active: make(map[container.ID]bool)}
+	if e.itemSep == "" {
+		e.itemSep = ", "
+		if options.Indent != Omit {
+			e.itemSep = ","
+		}
+	}
+	if e.keySep == "" {
+		e.keySep = ": "
+	}
+	if err := e.encode(value, 0); err != nil {
+		return "", err
+	}
+	return e.buf.String(), nil
+}
+
+// Loads decodes JSON document, same as Python's json.loads. Objects are
+// decoded as Dict, arrays as List, integers as int (or *big.Int if they
+// don't fit) and other numbers as float64. NaN, Infinity and -Infinity
+// are accepted. Options can be nil. Invalid JSON returns *JSONDecodeError.
+func Loads(data string, options *LoadsOptions) (interface{}, error) {
+	if options == nil {
+		options = &LoadsOptions{}
+	}
+	d := &decoder{data: data, options: options}
+	d.skipSpace()
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	d.skipSpace()
+	if d.pos < len(d.data) {
+		return nil, d.error("Extra data", d.pos)
+	}
+	return value, nil
+}
+
+//=============================================================================
+
+// MarshalJSON returns the list as compact JSON array, see Dumps.
+func (list List) MarshalJSON() ([]byte, error) {
+	return marshalJSON(list)
+}
+
+// UnmarshalJSON sets the list from JSON array, nested arrays and objects
+// are decoded as List and Dict, see Loads.
+func (list *List) UnmarshalJSON(data []byte) error {
+	value, err := Loads(string(data), nil)
+	if err != nil || value == nil {
+		return err
+	}
+	decoded, ok := value.(List)
+	if !ok {
+		return &TypeError{Values: []interface{}{value}, Err: ErrWrongType}
+	}
+	*list = decoded
+	return nil
+}
+
+// MarshalJSON returns the dictionary as compact JSON object with sorted
+// keys, see Dumps.
+func (dict Dict) MarshalJSON() ([]byte, error) {
+	return marshalJSON(dict)
+}
+
+// UnmarshalJSON sets the dictionary from JSON object, nested arrays and
+// objects are decoded as List and Dict, see Loads.
+func (dict *Dict) UnmarshalJSON(data []byte) error {
+	value, err := Loads(string(data), nil)
+	if err != nil || value == nil {
+		return err
+	}
+	decoded, ok := value.(Dict)
+	if !ok {
+		return &TypeError{Values: []interface{}{value}, Err: ErrWrongType}
+	}
+	*dict = decoded
+	return nil
+}
+
+// marshalJSON encodes value as compact JSON for MarshalJSON methods.
+// Values Dumps doesn't know are encoded with encoding/json.
+func marshalJSON(value interface{}) ([]byte, error) {
+	out, err := Dumps(value, &DumpsOptions{Indent: Omit, ItemSeparator: ",",
+		KeySeparator: ":", Default: func(value interface{}) (
+			interface{}, error) {
+
+			data, err := json.Marshal(value)
+			return json.RawMessage(data), err
+		}})
+	return []byte(out), err
+}
+
+//=============================================================================
+
+// floatRepr returns f formatted the same as Python's repr(f), the shortest
+// string that parses back to f.
+func floatRepr(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	sign := ""
+	if math.Signbit(f) {
+		sign, f = "-", -f
+	}
+	// Shortest digits and exponent, e.g. "1.2345e+06"
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	point := e + 1 // digits are 0.digits * 10^point
+
+	if point <= -4 || point > 16 {
+		out := digits[:1]
+		if len(digits) > 1 {
+			out += "." + digits[1:]
+		}
+		expSign := "+"
+		if e < 0 {
+			expSign, e = "-", -e
+		}
+		return fmt.Sprintf("%s%se%s%02d", sign, out, expSign, e)
+	}
+	switch {
+	case point <= 0:
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		return sign + digits + strings.Repeat("0", point-len(digits)) + ".0"
+	}
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+//=============================================================================
+
+// encoder holds state of a single Dumps.
+type encoder struct {
+	options *DumpsOptions
+	buf     strings.Builder
+	itemSep string
+	keySep  string
+	active  map[container.ID]bool // containers being encoded, to find cycles
+}
+
+func (e *encoder) encode(value interface{}, level int) error {
+	switch v := value.(type) {
+	case nil:
+		e.buf.WriteString("null")
+		return nil
+	case json.RawMessage:
+		e.buf.Write(v)
+		return nil
+	case *big.Int:
+		if v == nil {
+			e.buf.WriteString("null")
+		} else {
+			e.buf.WriteString(v.String())
+		}
+		return nil
+	case Counter:
+		return e.encode(v.HashDict, level)
+	case DefaultDict:
+		return e.encode(v.Dict, level)
+	case ChainMap:
+		return e.encode(v.ToDict(), level)
+	case *HashDict:
+		return e.enter(v, func() error {
+			return e.encodeObject(v.Keys(), v.Values(), !e.options.SortKeys,
+				level)
+		})
+	case *OrderedDict:
+		return e.enter(v, func() error {
+			return e.encodeObject(v.Keys(), v.Values(), !e.options.SortKeys,
+				level)
+		})
+	case *Deque:
+		return e.enter(v, func() error {
+			return e.encodeArray(v.ToList(), level)
+		})
+	case json.Number:
+		// Written as is, same as in encoding/json
+		if v == "" {
+			v = "0"
+		}
+		if !json.Valid([]byte(v)) ||
+			!strings.ContainsAny(string(v[:1]), "-0123456789") {
+			return &ValueError{Value: v, Err: ErrInvalidNumber}
+		}
+		e.buf.WriteString(string(v))
+		return nil
+	case List, Dict:
+		// Their MarshalJSON uses Dumps, they are encoded below
+	case json.Marshaler:
+		if isNilPointer(value) {
+			e.buf.WriteString("null")
+			return nil
+		}
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return err
+		}
+		e.buf.Write(compact.Bytes())
+		return nil
+	case encoding.TextMarshaler:
+		if isNilPointer(value) {
+			e.buf.WriteString("null")
+			return nil
+		}
+		text, err := v.MarshalText()
+		if err != nil {
+			return err
+		}
+		e.encodeString(string(text))
+		return nil
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		e.buf.WriteString(strconv.FormatBool(val.Bool()))
+		return nil
+	case reflect.String:
+		e.encodeString(val.String())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		e.buf.WriteString(strconv.FormatInt(val.Int(), 10))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		e.buf.WriteString(strconv.FormatUint(val.Uint(), 10))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		s, err := e.floatString(val.Float())
+		if err != nil {
+			return err
+		}
+		e.buf.WriteString(s)
+		return nil
+	case reflect.Slice:
+		if val.IsNil() {
+			// nil List is still a list, encoding/json writes null
+			if _, ok := value.(List); !ok {
+				e.buf.WriteString("null")
+				return nil
+			}
+		}
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		return e.enter(value, func() error {
+			return e.encodeArray(reflectList(val), level)
+		})
+	case reflect.Array:
+		return e.encodeArray(reflectList(val), level)
+	case reflect.Map:
+		return e.enter(value, func() error {
+			keys := make(List, 0, val.Len())
+			values := make(List, 0, val.Len())
+			iter := val.MapRange()
+			for iter.Next() {
+				keys = append(keys, iter.Key().Interface())
+				values = append(values, iter.Value().Interface())
+			}
+			return e.encodeObject(keys, values, false, level)
+		})
+	}
+
+	if e.options.Default == nil {
+		return &TypeError{Values: []interface{}{value}, Err: ErrNotSerializable}
+	}
+	var replaced interface{}
+	err := e.enter(value, func() error {
+		var err error
+		replaced, err = e.options.Default(value)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return e.encode(replaced, level)
+}
+
+// enter runs encode for container value, returning *ValueError if value
+// is already being encoded.
+func (e *encoder) enter(value interface{}, encode func() error) error {
+	id, ok := container.Identity(reflect.ValueOf(value))
+	if !ok {
+		return encode()
+	}
+	if e.active[id] {
+		return &ValueError{Err: ErrCircularReference}
+	}
+	e.active[id] = true
+	defer delete(e.active, id)
+	return encode()
+}
+
+func (e *encoder) encodeArray(list List, level int) error {
+	if len(list) == 0 {
+		e.buf.WriteString("[]")
+		return nil
+	}
+	e.buf.WriteByte('[')
+	for i, val := range list {
+		if i > 0 {
+			e.buf.WriteString(e.itemSep)
+		}
+		e.newLine(level + 1)
+		if err := e.encode(val, level+1); err != nil {
+			return err
+		}
+	}
+	e.newLine(level)
+	e.buf.WriteByte(']')
+	return nil
+}
+
+// encodeObject writes JSON object, keys are sorted unless ordered is true.
+func (e *encoder) encodeObject(keys, values List, ordered bool,
+	level int) error {
+
+	if len(keys) == 0 {
+		e.buf.WriteString("{}")
+		return nil
+	}
+
+	type item struct {
+		key   string
+		value interface{}
+	}
+	items := make([]item, len(keys))
+	for i, key := range keys {
+		s, err := e.keyString(key)
+		if err != nil {
+			return err
+		}
+		items[i] = item{s, values[i]}
+	}
+	if !ordered {
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sorted, err := sortedBy(order, func(i int) interface{} {
+			return keys[i]
+		}, false)
+		if err != nil {
+			if e.options.SortKeys {
+				return err
+			}
+			// Keys of different types, sort them as strings
+			sort.SliceStable(items, func(i, j int) bool {
+				return items[i].key < items[j].key
+			})
+		} else {
+			byKey := make([]item, len(items))
+			for i, index := range sorted {
+				byKey[i] = items[index]
+			}
+			items = byKey
+		}
+	}
+
+	e.buf.WriteByte('{')
+	for i, it := range items {
+		if i > 0 {
+			e.buf.WriteString(e.itemSep)
+		}
+		e.newLine(level + 1)
+		e.encodeString(it.key)
+		e.buf.WriteString(e.keySep)
+		if err := e.encode(it.value, level+1); err != nil {
+			return err
+		}
+	}
+	e.newLine(level)
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// keyString converts dictionary key to string the same way as Python.
+func (e *encoder) keyString(key interface{}) (string, error) {
+	if key == nil {
+		return "null", nil
+	}
+	if s, ok := toString(key); ok {
+		return s, nil
+	}
+	if m, ok := key.(encoding.TextMarshaler); ok && !isNilPointer(key) {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	val := reflect.ValueOf(key)
+	if val.Kind() == reflect.Bool {
+		return strconv.FormatBool(val.Bool()), nil
+	}
+	if n, ok := toNumber(key); ok {
+		switch n.kind {
+		case intNumber:
+			return strconv.FormatInt(n.i, 10), nil
+		case uintNumber:
+			return strconv.FormatUint(n.u, 10), nil
+		case bigNumber:
+			return n.b.String(), nil
+		}
+		return e.floatString(n.f)
+	}
+	return "", &TypeError{Values: []interface{}{key}, Err: ErrInvalidJSONKey}
+}
+
+// floatString returns f formatted as Python's json module does.
+func (e *encoder) floatString(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		if !e.options.AllowNaN {
+			return "", &ValueError{Value: f, Err: ErrFloatOutOfRange}
+		}
+		switch {
+		case math.IsNaN(f):
+			return "NaN", nil
+		case f > 0:
+			return "Infinity", nil
+		}
+		return "-Infinity", nil
+	}
+	return floatRepr(f), nil
+}
+
+func (e *encoder) encodeString(s string) {
+	e.buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			e.buf.WriteString(`\"`)
+		case '\\':
+			e.buf.WriteString(`\\`)
+		case '\n':
+			e.buf.WriteString(`\n`)
+		case '\r':
+			e.buf.WriteString(`\r`)
+		case '\t':
+			e.buf.WriteString(`\t`)
+		case '\b':
+			e.buf.WriteString(`\b`)
+		case '\f':
+			e.buf.WriteString(`\f`)
+		default:
+			switch {
+			case r < 0x20 || (e.options.EnsureASCII && r == 0x7f):
+				fmt.Fprintf(&e.buf, `\u%04x`, r)
+			case e.options.EnsureASCII && r > 0x7f:
+				if r > 0xffff {
+					r1, r2 := utf16.EncodeRune(r)
+					fmt.Fprintf(&e.buf, `\u%04x\u%04x`, r1, r2)
+				} else {
+					fmt.Fprintf(&e.buf, `\u%04x`, r)
+				}
+			default:
+				e.buf.WriteRune(r)
+			}
+		}
+	}
+	e.buf.WriteByte('"')
+}
+
+// newLine starts a new line indented for level, if Indent is set.
+func (e *encoder) newLine(level int) {
+	if e.options.Indent == Omit {
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(strings.Repeat(" ", e.options.Indent*level))
+}
+
+// isNilPointer returns true if value is a nil pointer, encoding/json
+// writes them as null even if they implement json.Marshaler.
+func isNilPointer(value interface{}) bool {
+	val := reflect.ValueOf(value)
+	return val.Kind() == reflect.Pointer && val.IsNil()
+}
+
+// reflectList returns elements of slice or array val as List.
+func reflectList(val reflect.Value) List {
+	list := make(List, val.Len())
+	for i := range list {
+		list[i] = val.Index(i).Interface()
+	}
+	return list
+}
+
+//=============================================================================
+
+// decoder holds state of a single Loads.
+type decoder struct {
+	data    string
+	pos     int // byte index in data
+	depth   int // number of arrays and objects being decoded
+	options *LoadsOptions
+}
+
+// error returns *JSONDecodeError for byte index pos.
+func (d *decoder) error(msg string, pos int) error {
+	before := d.data[:pos]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return &JSONDecodeError{Msg: msg, Pos: utf8.RuneCountInString(before),
+		Line: line, Col: col}
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// value decodes a value starting at d.pos, whitespace already skipped.
+func (d *decoder) value() (interface{}, error) {
+	if d.pos >= len(d.data) {
+		return nil, d.error("Expecting value", d.pos)
+	}
+	switch c := d.data[d.pos]; {
+	case c == '"':
+		return d.string()
+	case c == '{':
+		return d.nested(d.object)
+	case c == '[':
+		return d.nested(d.array)
+	case c == '-' || (c >= '0' && c <= '9'):
+		if strings.HasPrefix(d.data[d.pos:], "-Infinity") {
+			d.pos += len("-Infinity")
+			return math.Inf(-1), nil
+		}
+		return d.number()
+	}
+
+	for _, constant := range []struct {
+		text  string
+		value interface{}
+	}{
+		{"null", nil}, {"true", true}, {"false", false},
+		{"NaN", math.NaN()}, {"Infinity", math.Inf(1)},
+	} {
+		if strings.HasPrefix(d.data[d.pos:], constant.text) {
+			d.pos += len(constant.text)
+			return constant.value, nil
+		}
+	}
+	return nil, d.error("Expecting value", d.pos)
+}
+
+func (d *decoder) number() (interface{}, error) {
+	start := d.pos
+	digits := func() int {
+		from := d.pos
+		for d.pos < len(d.data) && d.data[d.pos] >= '0' &&
+			d.data[d.pos] <= '9' {
+			d.pos++
+		}
+		return d.pos - from
+	}
+
+	if d.data[d.pos] == '-' {
+		d.pos++
+	}
+	if d.pos < len(d.data) && d.data[d.pos] == '0' {
+		d.pos++
+	} else if digits() == 0 {
+		return nil, d.error("Expecting value", start)
+	}
+	isFloat := false
+	if d.pos+1 < len(d.data) && d.data[d.pos] == '.' &&
+		d.data[d.pos+1] >= '0' && d.data[d.pos+1] <= '9' {
+		d.pos++
+		digits()
+		isFloat = true
+	}
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		mark := d.pos
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' ||
+			d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if digits() == 0 {
+			d.pos = mark
+		} else {
+			isFloat = true
+		}
+	}
+
+	text := d.data[start:d.pos]
+	if isFloat {
+		// Too large numbers are infinite, same as Python's float()
+		f, _ := strconv.ParseFloat(text, 64)
+		return f, nil
+	}
+	if i, err := strconv.ParseInt(text, 10, 0); err == nil {
+		return int(i), nil
+	}
+	b, _ := new(big.Int).SetString(text, 10)
+	return b, nil
+}
+
+func (d *decoder) string() (string, error) {
+	start := d.pos
+	d.pos++ // opening quote
+	var sb strings.Builder
+	for {
+		if d.pos >= len(d.data) {
+			return "", d.error("Unterminated string starting at", start)
+		}
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", d.error("Invalid control character at", d.pos)
+		case c != '\\':
+			sb.WriteByte(c)
+			d.pos++
+			continue
+		}
+
+		// Escape sequence
+		if d.pos+1 >= len(d.data) {
+			return "", d.error("Unterminated string starting at", start)
+		}
+		esc := d.data[d.pos+1]
+		if simple, ok := map[byte]byte{'"': '"', '\\': '\\', '/': '/',
+			'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t'}[esc]; ok {
+			sb.WriteByte(simple)
+			d.pos += 2
+			continue
+		}
+		if esc != 'u' {
+			return "", d.error("Invalid \\escape", d.pos)
+		}
+		r, ok := d.hex4(d.pos + 2)
+		if !ok {
+			return "", d.error("Invalid \\uXXXX escape", d.pos+1)
+		}
+		d.pos += 6
+		if utf16.IsSurrogate(r) && strings.HasPrefix(d.data[d.pos:], `\u`) {
+			if r2, ok := d.hex4(d.pos + 2); ok {
+				if combined := utf16.DecodeRune(r, r2); combined !=
+					utf8.RuneError {
+					r = combined
+					d.pos += 6
+				}
+			}
+		}
+		sb.WriteRune(r)
+	}
+}
+
+// hex4 returns rune of 4 hex digits at index pos.
+func (d *decoder) hex4(pos int) (rune, bool) {
+	if pos+4 > len(d.data) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(d.data[pos:pos+4], 16, 32)
+	if err != nil || strings.ContainsAny(d.data[pos:pos+4], "+-") {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// nested returns result of parse for an array or object, or
+// *JSONDecodeError if it's nested deeper than container.MaxDepth.
+func (d *decoder) nested(parse func() (interface{}, error)) (interface{},
+	error) {
+
+	if d.depth >= container.MaxDepth {
+		return nil, d.error("Maximum nesting depth exceeded", d.pos)
+	}
+	d.depth++
+	val, err := parse()
+	d.depth--
+	return val, err
+}
+
+func (d *decoder) array() (interface{}, error) {
+	d.pos++ // [
+	list := List{}
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == ']' {
+		d.pos++
+		return list, nil
+	}
+	for {
+		d.skipSpace()
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, val)
+		d.skipSpace()
+		if d.pos < len(d.data) && d.data[d.pos] == ']' {
+			d.pos++
+			return list, nil
+		}
+		if d.pos >= len(d.data) || d.data[d.pos] != ',' {
+			return nil, d.error("Expecting ',' delimiter", d.pos)
+		}
+		d.pos++
+	}
+}
+
+func (d *decoder) object() (interface{}, error) {
+	d.pos++ // {
+	pairs := []Pair[string, interface{}]{}
+	d.skipSpace()
+	if d.pos < len(d.data) && d.data[d.pos] == '}' {
+		d.pos++
+		return d.objectResult(pairs)
+	}
+	for {
+		d.skipSpace()
+		if d.pos >= len(d.data) || d.data[d.pos] != '"' {
+			return nil, d.error(
+				"Expecting property name enclosed in double quotes", d.pos)
+		}
+		key, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		d.skipSpace()
+		if d.pos >= len(d.data) || d.data[d.pos] != ':' {
+			return nil, d.error("Expecting ':' delimiter", d.pos)
+		}
+		d.pos++
+		d.skipSpace()
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, Pair[string, interface{}]{key, val})
+
+		d.skipSpace()
+		if d.pos < len(d.data) && d.data[d.pos] == '}' {
+			d.pos++
+			return d.objectResult(pairs)
+		}
+		if d.pos >= len(d.data) || d.data[d.pos] != ',' {
+			return nil, d.error("Expecting ',' delimiter", d.pos)
+		}
+		d.pos++
+	}
+}
+
+// objectResult returns decoded object, as Dict or result of a hook.
+func (d *decoder) objectResult(pairs []Pair[string, interface{}]) (
+	interface{}, error) {
+
+	if d.options.ObjectPairsHook != nil {
+		return d.options.ObjectPairsHook(pairs)
+	}
+	dict := make(Dict, len(pairs))
+	for _, pair := range pairs {
+		dict[pair.Key] = pair.Value
+	}
+	if d.options.ObjectHook != nil {
+		return d.options.ObjectHook(dict)
+	}
+	return dict, nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+//=============================================================================
+
+var floatReprTests = []struct {
+	in  float64
+	out string
+}{
+	{0, "0.0"},
+	{math.Copysign(0, -1), "-0.0"},
+	{1, "1.0"},
+	{-2.5, "-2.5"},
+	{0.1, "0.1"},
+	{0.30000000000000004, "0.30000000000000004"},
+	{1e16, "1e+16"},
+	{1e15, "1000000000000000.0"},
+	{123456789012345680, "1.2345678901234568e+17"},
+	{0.0001, "0.0001"},
+	{0.00001, "1e-05"},
+	{1.5e-10, "1.5e-10"},
+	{1e100, "1e+100"},
+	{math.MaxFloat64, "1.7976931348623157e+308"},
+	{5e-324, "5e-324"},
+	{math.Inf(-1), "-inf"},
+}
+
+func TestFloatRepr(t *testing.T) {
+	for index, ft := range floatReprTests {
+		if out := floatRepr(ft.in); out != ft.out {
+			t.Errorf("%d. floatRepr(%v) => %s, want %s",
+				index, ft.in, out, ft.out)
+		}
+	}
+}
+
+var dumpsTests = []struct {
+	in      interface{}
+	options *DumpsOptions
+	out     string
+}{
+	{nil, nil, "null"},
+	{List{1, 2.0, "a", true, nil}, nil, `[1, 2.0, "a", true, null]`},
+	{Dict{"b": 1, "a": List{}}, nil, `{"a": [], "b": 1}`},
+	{List{"zażółć", "\U0001F600", "\x7f\n\"\\"}, nil,
+		`["za\u017c\u00f3\u0142\u0107", "\ud83d\ude00", "\u007f\n\"\\"]`},
+	{List{"zażółć", "\x01"}, &DumpsOptions{Indent: Omit},
+		`["zażółć", "\u0001"]`},
+	{List{math.NaN(), math.Inf(1), math.Inf(-1)}, nil,
+		`[NaN, Infinity, -Infinity]`},
+	{Dict{"a": List{1, Dict{"b": nil}}, "c": Dict{}},
+		&DumpsOptions{Indent: 2},
+		"{\n  \"a\": [\n    1,\n    {\n      \"b\": null\n    }\n  ],\n" +
+			"  \"c\": {}\n}"},
+	{Dict{"a": 1, "b": List{2, 3}},
+		&DumpsOptions{Indent: Omit, ItemSeparator: ",", KeySeparator: ":"},
+		`{"a":1,"b":[2,3]}`},
+	{map[interface{}]interface{}{10: "x", 2: "y", 1.5: "z", true: nil},
+		nil, `{"true": null, "1.5": "z", "2": "y", "10": "x"}`},
+	{Tuple{1, "a"}, nil, `[1, "a"]`},
+	{[]int{1, 2}, nil, `[1, 2]`},
+	{[]string(nil), nil, `null`},
+	{List(nil), nil, `[]`},
+	{new(big.Int).Lsh(big.NewInt(1), 70), nil, "1180591620717411303424"},
+	{json.RawMessage(`{"raw":1}`), nil, `{"raw":1}`},
+	{Counter{HashDictFromDict(Dict{"a": 2})}, nil, `{"a": 2}`},
+}
+
+func TestDumps(t *testing.T) {
+	for index, dt := range dumpsTests {
+		if out, err := Dumps(dt.in, dt.options); out != dt.out || err != nil {
+			t.Errorf("%d. Dumps(%v) => %s, %v, want %s",
+				index, dt.in, out, err, dt.out)
+		}
+	}
+}
+
+func TestDumpsOrdered(t *testing.T) {
+	dict := NewHashDict()
+	dict.Set("z", 1)
+	dict.Set(2, 2)
+	dict.Set("a", 3)
+	if out, _ := Dumps(dict, nil); out != `{"z": 1, "2": 2, "a": 3}` {
+		t.Errorf("Dumps(%v) => %s, want insertion order", dict, out)
+	}
+
+	// Python can't sort keys of different types either
+	_, err := Dumps(dict, &DumpsOptions{Indent: Omit, SortKeys: true})
+	if !errors.Is(err, ErrUnorderable) {
+		t.Errorf("Dumps(SortKeys) => %v, want ErrUnorderable", err)
+	}
+}
+
+func TestDumpsErrors(t *testing.T) {
+	if _, err := Dumps(List{math.NaN()}, &DumpsOptions{Indent: Omit}); !errors.
+		Is(err, ErrFloatOutOfRange) {
+		t.Errorf("Dumps(NaN) => %v, want ErrFloatOutOfRange", err)
+	}
+	if _, err := Dumps(List{time.Second}, nil); err != nil {
+		t.Errorf("Dumps(time.Duration) => %v, want no error", err)
+	}
+	if _, err := Dumps(List{struct{}{}}, nil); !errors.
+		Is(err, ErrNotSerializable) {
+		t.Errorf("Dumps(struct{}) => %v, want ErrNotSerializable", err)
+	}
+	if _, err := Dumps(map[[2]int]int{}, nil); err != nil {
+		t.Errorf("Dumps(empty map) => %v, want no error", err)
+	}
+	_, err := Dumps(map[[1]int]int{{1}: 1}, nil)
+	if !errors.Is(err, ErrInvalidJSONKey) {
+		t.Errorf("Dumps(array key) => %v, want ErrInvalidJSONKey", err)
+	}
+
+	list := List{1, nil}
+	list[1] = list
+	if _, err := Dumps(list, nil); !errors.Is(err, ErrCircularReference) {
+		t.Errorf("Dumps(cycle) => %v, want ErrCircularReference", err)
+	}
+	// The same List twice is not a cycle
+	shared := List{1}
+	if out, err := Dumps(List{shared, shared}, nil); out != "[[1], [1]]" ||
+		err != nil {
+		t.Errorf("Dumps(shared) => %s, %v, want [[1], [1]]", out, err)
+	}
+}
+
+func TestDumpsDefault(t *testing.T) {
+	type point struct{ X, Y int }
+	options := DefaultDumpsOptions()
+	options.Default = func(value interface{}) (interface{}, error) {
+		if p, ok := value.(point); ok {
+			return List{p.X, p.Y}, nil
+		}
+		return nil, errors.New("unknown")
+	}
+	if out, err := Dumps(Dict{"p": point{1, 2}}, options); out !=
+		`{"p": [1, 2]}` || err != nil {
+		t.Errorf("Dumps(Default) => %s, %v, want {\"p\": [1, 2]}", out, err)
+	}
+}
+
+// status is written as JSON string with MarshalText
+type status int
+
+func (s status) MarshalText() ([]byte, error) {
+	if s == 1 {
+		return []byte("ok"), nil
+	}
+	return nil, errors.New("unknown status")
+}
+
+// point is written as JSON array with MarshalJSON
+type point struct{ X, Y int }
+
+func (p *point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("[ %d, %d ]", p.X, p.Y)), nil
+}
+
+var marshalerTests = []struct {
+	in  interface{}
+	out string
+}{
+	{List{json.Number("12"), status(1)}, `[12,"ok"]`},
+	{Dict{"n": json.Number("12")}, `{"n":12}`},
+	{Dict{"p": &point{1, 2}, "nil": (*point)(nil)}, `{"nil":null,"p":[1,2]}`},
+	{List{map[status]int{1: 2}}, `[{"ok":2}]`},
+}
+
+func TestMarshaler(t *testing.T) {
+	for index, mt := range marshalerTests {
+		data, err := json.Marshal(mt.in)
+		if string(data) != mt.out || err != nil {
+			t.Errorf("%d. json.Marshal(%v) => %s, %v, want %s",
+				index, mt.in, data, err, mt.out)
+		}
+	}
+	if out, err := Dumps(List{json.Number("1.50"), status(1)}, nil); out !=
+		`[1.50, "ok"]` || err != nil {
+		t.Errorf("Dumps() => %s, %v, want [1.50, \"ok\"]", out, err)
+	}
+	if _, err := Dumps(List{status(2)}, nil); err == nil {
+		t.Errorf("Dumps(status(2)) => nil, want error")
+	}
+	if _, err := Dumps(json.Number("true"), nil); !errors.Is(err,
+		ErrInvalidNumber) {
+		t.Errorf("Dumps(Number(true)) => %v, want %v", err, ErrInvalidNumber)
+	}
+}
+
+//=============================================================================
+
+var loadsTests = []struct {
+	in  string
+	out interface{}
+}{
+	{` null `, nil},
+	{`[1, -2.5, "a", true, false, []]`, List{1, -2.5, "a", true, false, List{}}},
+	{`{"a": {"b": [1e2, 0]}}`, Dict{"a": Dict{"b": List{100.0, 0}}}},
+	{`"ż😀\n\/"`, "ż\U0001F600\n/"},
+	{`"zażółć"`, "zażółć"},
+	{`12345678901234567890123`, func() *big.Int {
+		b, _ := new(big.Int).SetString("12345678901234567890123", 10)
+		return b
+	}()},
+	{`[Infinity, -Infinity]`, List{math.Inf(1), math.Inf(-1)}},
+	{`{"a": 1, "a": 2}`, Dict{"a": 2}},
+}
+
+func TestLoads(t *testing.T) {
+	for index, lt := range loadsTests {
+		if out, err := Loads(lt.in, nil); !reflect.DeepEqual(out, lt.out) ||
+			err != nil {
+			t.Errorf("%d. Loads(%s) => %#v, %v, want %#v",
+				index, lt.in, out, err, lt.out)
+		}
+	}
+	if out, _ := Loads("NaN", nil); !math.IsNaN(out.(float64)) {
+		t.Errorf("Loads(NaN) => %v, want NaN", out)
+	}
+}
+
+var loadsErrorTests = []struct {
+	in  string
+	err string
+}{
+	{``, "Expecting value: line 1 column 1 (char 0)"},
+	{`[1,]`, "Expecting value: line 1 column 4 (char 3)"},
+	{`[1 2]`, "Expecting ',' delimiter: line 1 column 4 (char 3)"},
+	{"{\n\"ż\" 1}", "Expecting ':' delimiter: line 2 column 5 (char 6)"},
+	{`{1: 2}`, "Expecting property name enclosed in double quotes: " +
+		"line 1 column 2 (char 1)"},
+	{`"abc`, "Unterminated string starting at: line 1 column 1 (char 0)"},
+	{`"\x"`, "Invalid \\escape: line 1 column 2 (char 1)"},
+	{`"\u12"`, "Invalid \\uXXXX escape: line 1 column 3 (char 2)"},
+	{`[] x`, "Extra data: line 1 column 4 (char 3)"},
+	{"\"a\tb\"", "Invalid control character at: line 1 column 3 (char 2)"},
+}
+
+func TestLoadsErrors(t *testing.T) {
+	for index, lt := range loadsErrorTests {
+		_, err := Loads(lt.in, nil)
+		if err == nil || err.Error() != lt.err ||
+			!errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("%d. Loads(%s) => %v, want %s", index, lt.in, err, lt.err)
+		}
+	}
+	deep := strings.Repeat("[", container.MaxDepth+1) + strings.Repeat("]", container.MaxDepth+1)
+	var decodeErr *JSONDecodeError
+	if _, err := Loads(deep, nil); !errors.As(err, &decodeErr) ||
+		decodeErr.Msg != "Maximum nesting depth exceeded" {
+		t.Errorf("Loads(deeply nested) => %v, want %s", err,
+			"Maximum nesting depth exceeded")
+	}
+	nested := strings.Repeat(`{"a": [`, container.MaxDepth/2) +
+		strings.Repeat("]}", container.MaxDepth/2)
+	if _, err := Loads(nested, nil); err != nil {
+		t.Errorf("Loads(nested %d times) => %v, want nil", container.MaxDepth, err)
+	}
+}
+
+func TestLoadsHooks(t *testing.T) {
+	options := &LoadsOptions{ObjectPairsHook: func(
+		pairs []Pair[string, interface{}]) (interface{}, error) {
+
+		dict := NewOrderedDict()
+		for _, pair := range pairs {
+			dict.Set(pair.Key, pair.Value)
+		}
+		return dict, nil
+	}}
+	out, err := Loads(`{"z": 1, "a": {"y": 2, "b": 3}}`, options)
+	if err != nil {
+		t.Fatalf("Loads(ObjectPairsHook) => %v", err)
+	}
+	if s, _ := Dumps(out, nil); s != `{"z": 1, "a": {"y": 2, "b": 3}}` {
+		t.Errorf("Loads(ObjectPairsHook) => %s, want document order", s)
+	}
+
+	options = &LoadsOptions{ObjectHook: func(dict Dict) (interface{}, error) {
+		return len(dict), nil
+	}}
+	if out, _ := Loads(`[{"a": 1, "b": 2}, {}]`, options); !reflect.
+		DeepEqual(out, List{2, 0}) {
+		t.Errorf("Loads(ObjectHook) => %v, want [2 0]", out)
+	}
+}
+
+//=============================================================================
+
+func TestListDictJSON(t *testing.T) {
+	in := Dict{"b": List{1, 0.5, Dict{"c": "<x>"}}, "a": time.Unix(0, 0).UTC()}
+	data, err := json.Marshal(in)
+	// encoding/json escapes HTML characters in MarshalJSON output
+	want := `{"a":"1970-01-01T00:00:00Z","b":[1,0.5,{"c":"\u003cx\u003e"}]}`
+	if string(data) != want || err != nil {
+		t.Errorf("json.Marshal(%v) => %s, %v, want %s", in, data, err, want)
+	}
+
+	var dict Dict
+	if err := json.Unmarshal(data, &dict); err != nil {
+		t.Fatalf("json.Unmarshal(%s) => %v", data, err)
+	}
+	nested, ok := dict["b"].(List)
+	if !ok || !reflect.DeepEqual(nested[2], Dict{"c": "<x>"}) {
+		t.Errorf("json.Unmarshal(%s) => %#v, want nested List and Dict",
+			data, dict)
+	}
+
+	var list List
+	if err := json.Unmarshal([]byte(`[[1], {"a": null}]`), &list); err != nil ||
+		!reflect.DeepEqual(list, List{List{1}, Dict{"a": nil}}) {
+		t.Errorf("json.Unmarshal() => %#v, %v", list, err)
+	}
+	if err := json.Unmarshal([]byte(`{}`), &list); !errors.
+		Is(err, ErrWrongType) {
+		t.Errorf("json.Unmarshal(object into List) => %v, want ErrWrongType",
+			err)
+	}
+	if _, err := json.Marshal(List{math.NaN()}); err == nil {
+		t.Errorf("json.Marshal(NaN) => no error, want error")
+	}
+}
@@ -26,6 +26,13 @@ var (
 	// from empty list
 	ErrRemoveFromEmptyList = errors.
 		New("Trying to remove element from empty list")
+	// ErrIndexOutOfRange is returned when user want to get or change
+	// element with index outside the list
+	ErrIndexOutOfRange = errors.New("list index out of range")
+	// ErrSliceSize is returned when user want to assign sequence to
+	// extended slice of different size
+	ErrSliceSize = errors.
+		New("sequence size doesn't match extended slice size")
 )
 
 //=============================================================================
@@ -36,10 +43,10 @@ func (list *List) Append(values ...interface{}) {
 }
 
 // AppendIfMissing adds an element to the end of the list if it's not already
-// in the list.
+// in the list. Elements are compared with Equal.
 func (list *List) AppendIfMissing(value interface{}) {
 	for _, ele := range *list {
-		if ele == value {
+		if Equal(ele, value) {
 			// Element exists, exit
 			goto exit
 		}
@@ -48,11 +55,26 @@ func (list *List) AppendIfMissing(value interface{}) {
 exit:
 }
 
+// At returns element with given index. Negative index counts from the end
+// of the list, same as in Python.
+//		l := listdict.List{"one", 2, "three"}
+// 		l.At(0)  => "one", nil
+// 		l.At(-1) => "three", nil
+// 		l.At(3)  => nil, *IndexError
+func (list List) At(index int) (interface{}, error) {
+	i, ok := normIndex(index, len(list))
+	if !ok {
+		return nil, &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	return list[i], nil
+}
+
 // Count returns the number of times value appears in the list.
+// Elements are compared with Equal.
 func (list List) Count(value interface{}) int {
 	counter := 0
 	for _, listValue := range list {
-		if listValue == value {
+		if Equal(listValue, value) {
 			counter++
 		}
 	}
@@ -60,17 +82,16 @@ func (list List) Count(value interface{}) int {
 }
 
 // Delete removes element with given index from the list.
+// Negative index counts from the end of the list.
 func (list *List) Delete(index int) error {
-	if len(*list) <= 0 {
-		return ErrRemoveFromEmptyList
-	}
-
-	listLen := len(*list)
+	return deleteIndex(list, index)
+}
 
-	copy((*list)[index:], (*list)[index+1:])
-	(*list)[listLen-1] = nil
-	*list = (*list)[:listLen-1]
-	return nil
+// DeleteSlice removes elements selected by slice start:stop:step from
+// the list, same as Python's del l[start:stop:step].
+// Use Omit for values that were left out.
+func (list *List) DeleteSlice(start, stop, step int) error {
+	return deleteSlice(list, start, stop, step)
 }
 
 // Extend one list with the contents of the other list.
@@ -80,32 +101,35 @@ func (list *List) Extend(otherList List) {
 	}
 }
 
+// Get returns element with given index or defaultVal if index is outside
+// the list. Negative index counts from the end of the list.
+//		l := listdict.List{"one", 2}
+// 		l.Get(-1, "none") => 2
+// 		l.Get(5, "none")  => "none"
+func (list List) Get(index int, defaultVal interface{}) interface{} {
+	if i, ok := normIndex(index, len(list)); ok {
+		return list[i]
+	}
+	return defaultVal
+}
+
 // Index returns the index of the first item in the list whose value is val.
-// It is -1 if there is no such item.
+// It is -1 and *ValueError if there is no such item.
+// Elements are compared with Equal.
 func (list List) Index(val interface{}) (int, error) {
 	for index, listValue := range list {
-		if listValue == val {
+		if Equal(listValue, val) {
 			return index, nil
 		}
 	}
-	errorString := fmt.Sprintf("%v is not in list", val)
-	return -1, errors.New(errorString)
+	return -1, &ValueError{Value: val, Err: ErrNotInList}
 }
 
 // Insert an element at a given position. If the position is past the end
-// of the list, append to the end.
+// of the list, append to the end. Negative position counts from the end
+// of the list, if it's before the beginning insert at the beginning.
 func (list *List) Insert(index int, values ...interface{}) {
-
-	if len(*list) > index {
-		for i := 0; i < len(values); i++ {
-			*list = append(*list, 0)
-		}
-
-		copy((*list)[index+len(values):], (*list)[index:])
-		copy((*list)[index:], values)
-	} else {
-		*list = append(*list, values...)
-	}
+	insertAt(list, index, values...)
 }
 
 // IsEqual returns true if lists are equal.
@@ -116,7 +140,7 @@ func (list List) IsEqual(otherList List) bool {
 // Remove and returns the last element in the list.
 func (list *List) Pop() (interface{}, error) {
 	if len(*list) <= 0 {
-		return nil, ErrRemoveFromEmptyList
+		return nil, &IndexError{Index: -1, Err: ErrRemoveFromEmptyList}
 	}
 
 	listLen := len(*list)
@@ -127,46 +151,94 @@ func (list *List) Pop() (interface{}, error) {
 }
 
 // Remove and returns the element at the given position in the list.
+// Negative position counts from the end of the list.
 func (list *List) PopItem(index int) (interface{}, error) {
 	if len(*list) <= 0 {
-		return nil, ErrRemoveFromEmptyList
+		return nil, &IndexError{Index: index, Err: ErrRemoveFromEmptyList}
 	}
 
-	val := (*list)[index]
+	val, err := list.At(index)
+	if err != nil {
+		return nil, err
+	}
 	(*list).Delete(index)
 
 	return val, nil
 }
 
 // Remove the first element from the list whose value matches the given value.
-// Error if no match is found.
+// *ValueError if no match is found. Elements are compared with Equal.
 func (list *List) Remove(val interface{}) error {
-	errorString := fmt.Sprintf("%v is not in list", val)
 	if len(*list) > 0 {
 		for index, listValue := range *list {
-			if listValue == val {
+			if Equal(listValue, val) {
 				(*list).Delete(index)
 				return nil
 			}
 		}
 	}
-	return errors.New(errorString)
+	return &ValueError{Value: val, Err: ErrNotInList}
 }
 
 // Reverse the elements of the list in place.
 func (list *List) Reverse() {
-	if len(*list) > 0 {
-		maxIndex := len(*list) - 1
-		for index := 0; index < (maxIndex/2)+1; index++ {
-			(*list)[index], (*list)[maxIndex-index] =
-				(*list)[maxIndex-index], (*list)[index]
-		}
+	reverse(*list)
+}
+
+// Set replaces element with given index. Negative index counts from the end
+// of the list.
+func (list List) Set(index int, value interface{}) error {
+	i, ok := normIndex(index, len(list))
+	if !ok {
+		return &IndexError{Index: index, Err: ErrIndexOutOfRange}
 	}
+	list[i] = value
+	return nil
+}
+
+// SetSlice replaces elements selected by slice start:stop:step with values,
+// same as Python's l[start:stop:step] = values. Use Omit for values that
+// were left out. If step is 1 the list can grow or shrink, otherwise
+// values must have the same length as the slice.
+//		l := listdict.List{1, 2, 3}
+// 		l.SetSlice(0, 2, 1, listdict.List{"a"}) // l = [a 3]
+func (list *List) SetSlice(start, stop, step int, values List) error {
+	return setSlice(list, start, stop, step, values)
+}
+
+// Slice returns a new list with elements selected by slice
+// start:stop:step, same as Python's l[start:stop:step].
+// Use Omit for values that were left out.
+//		l := listdict.List{0, 1, 2, 3, 4}
+// 		l.Slice(1, -1, 1)                           => [1 2 3]
+// 		l.Slice(listdict.Omit, listdict.Omit, 2)    => [0 2 4]
+// 		l.Slice(listdict.Omit, listdict.Omit, -1)   => [4 3 2 1 0]
+func (list List) Slice(start, stop, step int) (List, error) {
+	return sliceOf(list, start, stop, step)
 }
 
 // Sort the list in place ordering elements from smallest to largest.
-//func (list *List) Sort() {
-//}
+// Sort is stable and follows Python 3 ordering rules (see Compare).
+// If key is not nil elements are ordered by key(element), reverse sorts
+// from largest to smallest. On error the list is left unchanged.
+//		l := listdict.List{3, 1.5, 2}
+// 		l.Sort(nil, false) => [1.5 2 3]
+func (list *List) Sort(key func(interface{}) interface{}, reverse bool) error {
+	sorted, err := sortedBy(*list, key, reverse)
+	if err != nil {
+		return err
+	}
+	copy(*list, sorted)
+	return nil
+}
+
+// Sorted returns a new sorted list, same as Sort but without changing
+// the list.
+func (list List) Sorted(key func(interface{}) interface{}, reverse bool) (
+	List, error) {
+
+	return sortedBy(list, key, reverse)
+}
 
 // String returns list values as string
 //		l := listdict.List{"one", 2, "three"}
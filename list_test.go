@@ -8,6 +8,7 @@ package listdict
 
 import (
 	"errors"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -50,6 +51,9 @@ var listAppendIfMissingTests = []struct {
 	{List{}, "one", List{"one"}},
 	{List{"one", "two"}, "two", List{"one", "two"}},
 	{List{"one", 1}, 1, List{"one", 1}},
+	{List{"one", 1}, 1.0, List{"one", 1}},
+	{List{List{1}}, List{1}, List{List{1}}},
+	{List{Dict{"a": 1}}, Dict{"a": 2}, List{Dict{"a": 1}, Dict{"a": 2}}},
 }
 
 func TestListAppendIfMissing(t *testing.T) {
@@ -66,6 +70,34 @@ func TestListAppendIfMissing(t *testing.T) {
 
 //=============================================================================
 
+var listAtTests = []struct {
+	in       List
+	index    int
+	out      interface{}
+	outError error
+}{
+	{List{"one", "two", "three"}, 0, "one", nil},
+	{List{"one", "two", "three"}, 2, "three", nil},
+	{List{"one", "two", "three"}, -1, "three", nil},
+	{List{"one", "two", "three"}, -3, "one", nil},
+	{List{"one", "two", "three"}, 3, nil, ErrIndexOutOfRange},
+	{List{"one", "two", "three"}, -4, nil, ErrIndexOutOfRange},
+	{List{}, 0, nil, ErrIndexOutOfRange},
+}
+
+func TestListAt(t *testing.T) {
+	for index, lat := range listAtTests {
+		val, err := lat.in.At(lat.index)
+		if val != lat.out || !errors.Is(err, lat.outError) {
+			t.Errorf(
+				"%d. %v.At(%d) => %v, %v, want %v, %v",
+				index, lat.in, lat.index, val, err, lat.out, lat.outError)
+		}
+	}
+}
+
+//=============================================================================
+
 var listCountTests = []struct {
 	in  List
 	val interface{}
@@ -75,6 +107,8 @@ var listCountTests = []struct {
 	{List{"one", "two", "three", "two"}, "zero", 0},
 	{List{1, 2, 3, 2}, 3, 1},
 	{List{}, 1, 0},
+	{List{1, 1.0, int64(1), true, "1"}, 1, 4},
+	{List{List{1, 2}, Dict{"a": 1}, List{1, 2}}, List{1, 2}, 2},
 }
 
 func TestListCount(t *testing.T) {
@@ -99,13 +133,17 @@ var listDeleteTests = []struct {
 	{List{"one", "two", "three"}, 1, List{"one", "three"}, nil},
 	{List{"one"}, 0, List{}, nil},
 	{List{}, 0, List{}, ErrRemoveFromEmptyList},
+	{List{"one", "two", "three"}, -1, List{"one", "two"}, nil},
+	{List{"one", "two", "three"}, -3, List{"two", "three"}, nil},
+	{List{"one", "two"}, 2, List{"one", "two"}, ErrIndexOutOfRange},
+	{List{"one", "two"}, -3, List{"one", "two"}, ErrIndexOutOfRange},
 }
 
 func TestListDelete(t *testing.T) {
 	for index, ldt := range listDeleteTests {
 		list := append(List{}, ldt.in...)
 		err := list.Delete(ldt.val)
-		if err != ldt.outError {
+		if !errors.Is(err, ldt.outError) {
 			t.Errorf(
 				"%d. %v.Delete(%d) => %v, want %v",
 				index, ldt.in, ldt.val, err, ldt.outError)
@@ -146,6 +184,64 @@ func TestListExtend(t *testing.T) {
 
 //=============================================================================
 
+var listDeleteSliceTests = []struct {
+	in                List
+	start, stop, step int
+	out               List
+	outError          error
+}{
+	{List{0, 1, 2, 3, 4}, 1, 3, 1, List{0, 3, 4}, nil},
+	{List{0, 1, 2, 3, 4}, Omit, Omit, 2, List{1, 3}, nil},
+	{List{0, 1, 2, 3, 4}, Omit, Omit, -2, List{1, 3}, nil},
+	{List{0, 1, 2, 3, 4}, -2, Omit, Omit, List{0, 1, 2}, nil},
+	{List{0, 1, 2, 3, 4}, 3, 1, 1, List{0, 1, 2, 3, 4}, nil},
+	{List{0, 1, 2, 3, 4}, Omit, Omit, Omit, List{}, nil},
+	{List{1, 2, 3}, 2, Omit, math.MaxInt, List{1, 2}, nil},
+	{List{1, 2, 3}, 0, Omit, -math.MaxInt, List{2, 3}, nil},
+	{List{0, 1, 2}, Omit, Omit, 0, List{0, 1, 2}, ErrZeroSliceStep},
+}
+
+func TestListDeleteSlice(t *testing.T) {
+	for index, ldst := range listDeleteSliceTests {
+		list := append(List{}, ldst.in...)
+		err := list.DeleteSlice(ldst.start, ldst.stop, ldst.step)
+		if !errors.Is(err, ldst.outError) || !reflect.DeepEqual(list, ldst.out) {
+			t.Errorf(
+				"%d. %v.DeleteSlice(%d, %d, %d) => %v, %v, want %v, %v",
+				index, ldst.in, ldst.start, ldst.stop, ldst.step,
+				list, err, ldst.out, ldst.outError)
+		}
+	}
+}
+
+//=============================================================================
+
+var listGetTests = []struct {
+	in         List
+	index      int
+	defaultVal interface{}
+	out        interface{}
+}{
+	{List{"one", "two"}, 1, "none", "two"},
+	{List{"one", "two"}, -2, "none", "one"},
+	{List{"one", "two"}, 2, "none", "none"},
+	{List{"one", "two"}, -3, nil, nil},
+	{List{}, 0, 0, 0},
+}
+
+func TestListGet(t *testing.T) {
+	for index, lgt := range listGetTests {
+		val := lgt.in.Get(lgt.index, lgt.defaultVal)
+		if val != lgt.out {
+			t.Errorf(
+				"%d. %v.Get(%d, %v) => %v, want %v",
+				index, lgt.in, lgt.index, lgt.defaultVal, val, lgt.out)
+		}
+	}
+}
+
+//=============================================================================
+
 var listIndexTests = []struct {
 	in       List
 	val      interface{}
@@ -153,16 +249,17 @@ var listIndexTests = []struct {
 	outError error
 }{
 	{List{"one", "two", "three", "two"}, "two", 1, nil},
-	{List{"one", "two"}, "zero", -1, errors.New("zero is not in list")},
+	{List{"one", "two"}, "zero", -1, ErrNotInList},
 	{List{1, 2, 3, 2}, 3, 2, nil},
-	{List{}, 1, -1, errors.New("1 is not in list")},
+	{List{1, List{2}, Dict{"three": 3}}, Dict{"three": 3.0}, 2, nil},
+	{List{1, 2}, 2.0, 1, nil},
+	{List{}, 1, -1, ErrNotInList},
 }
 
 func TestListIndex(t *testing.T) {
 	for index, lit := range listIndexTests {
 		foundIndex, err := lit.in.Index(lit.val)
-		if foundIndex != lit.out || (err == nil && err != lit.outError) ||
-			(err != nil && err.Error() != lit.outError.Error()) {
+		if foundIndex != lit.out || !errors.Is(err, lit.outError) {
 			t.Errorf(
 				"%d. %v.Index(%v) => %v, %v, want %v, %v",
 				index, lit.in, lit.val, foundIndex, err, lit.out, lit.outError)
@@ -187,6 +284,13 @@ var listInsertTests = []struct {
 	{List{"one", "two", "three"},
 		1, []interface{}{"1.1", 1.2, "1.3"},
 		List{"one", "1.1", 1.2, "1.3", "two", "three"}},
+	{List{"one", "two", "three"},
+		-1, []interface{}{"2.5"},
+		List{"one", "two", "2.5", "three"}},
+	{List{"one", "two", "three"},
+		-10, []interface{}{"zero"},
+		List{"zero", "one", "two", "three"}},
+	{List{}, 0, []interface{}{1, 2}, List{1, 2}},
 }
 
 func TestListInsert(t *testing.T) {
@@ -244,7 +348,7 @@ func TestListPop(t *testing.T) {
 	for index, lpt := range listPopTests {
 		list := append(List{}, lpt.in...)
 		item, err := list.Pop()
-		if item != lpt.out || err != lpt.outError {
+		if item != lpt.out || !errors.Is(err, lpt.outError) {
 			t.Errorf(
 				"%d. %v.Pop() => %v, %v, want %v, %v",
 				index, lpt.in, item, err, lpt.out, lpt.outError)
@@ -269,13 +373,15 @@ var listPopItemTests = []struct {
 	{List{"one", "two", "three"}, 1, "two", nil, List{"one", "three"}},
 	{List{1, 2, 3, 2}, 2, 3, nil, List{1, 2, 2}},
 	{List{}, 0, nil, ErrRemoveFromEmptyList, List{}},
+	{List{1, 2, 3}, -1, 3, nil, List{1, 2}},
+	{List{1, 2, 3}, 3, nil, ErrIndexOutOfRange, List{1, 2, 3}},
 }
 
 func TestListPopItem(t *testing.T) {
 	for index, lpt := range listPopItemTests {
 		list := append(List{}, lpt.in...)
 		item, err := list.PopItem(lpt.index)
-		if item != lpt.out || err != lpt.outError {
+		if item != lpt.out || !errors.Is(err, lpt.outError) {
 			t.Errorf(
 				"%d. %v.PopItem(%d) => %v, %v, want %v, %v",
 				index, lpt.in, lpt.index, item, err, lpt.out, lpt.outError)
@@ -298,17 +404,17 @@ var listRemoveTests = []struct {
 }{
 	{List{"one", "two", "one"}, "one", nil, List{"two", "one"}},
 	{List{"one", "two", "three"}, "zero",
-		errors.New("zero is not in list"), List{"one", "two", "three"}},
+		ErrNotInList, List{"one", "two", "three"}},
 	{List{1, 2, 3, 2}, 3, nil, List{1, 2, 2}},
-	{List{}, 1, errors.New("1 is not in list"), List{}},
+	{List{List{1}, 2}, List{1.0}, nil, List{2}},
+	{List{}, 1, ErrNotInList, List{}},
 }
 
 func TestListRemove(t *testing.T) {
 	for index, lrt := range listRemoveTests {
 		list := append(List{}, lrt.in...)
 		err := list.Remove(lrt.val)
-		if (err == nil && err != lrt.out) ||
-			(err != nil && err.Error() != lrt.out.Error()) {
+		if !errors.Is(err, lrt.out) {
 			t.Errorf(
 				"%d. %v.Remove(%v) => %v, want %v",
 				index, lrt.in, lrt.val, err, lrt.out)
@@ -347,29 +453,175 @@ func TestListReverse(t *testing.T) {
 
 //=============================================================================
 
-//var listSortTests = []struct {
-//	in  List
-//	out List
-//}{
-//	{List{"o", "ze", "a", "two"}, List{"a", "o", "two", "ze"}},
-//	{List{2, 3, 1, -2}, List{-2, 1, 2, 3}},
-//	{List{1, "one", 2}, List{1, 2, "one"}},
-//	{List{2, 1.3, 1}, List{1, 1.3, 2}},
-//	{List{1, "1.2", "a", "ola", 34, 2, "1", "12", 1.2, "2", "ala"},
-//		List{1, 1.2, 2, 34, "1", "1.2", "12", "2", "a", "ala", "ola"}},
-//}
-
-//func TestListSort(t *testing.T) {
-//	for index, lst := range listSortTests {
-//		list := append(List{}, lst.in...)
-//		list.Sort()
-//		if !reflect.DeepEqual(list, lst.out) {
-//			t.Errorf(
-//				"%d. %v.Sort() => out list = %v, want %v",
-//				index, lst.in, list, lst.out)
-//		}
-//	}
-//}
+var listSetTests = []struct {
+	in       List
+	index    int
+	val      interface{}
+	out      List
+	outError error
+}{
+	{List{1, 2, 3}, 0, "one", List{"one", 2, 3}, nil},
+	{List{1, 2, 3}, -1, "three", List{1, 2, "three"}, nil},
+	{List{1, 2, 3}, 3, "four", List{1, 2, 3}, ErrIndexOutOfRange},
+}
+
+func TestListSet(t *testing.T) {
+	for index, lst := range listSetTests {
+		list := append(List{}, lst.in...)
+		err := list.Set(lst.index, lst.val)
+		if !errors.Is(err, lst.outError) || !reflect.DeepEqual(list, lst.out) {
+			t.Errorf(
+				"%d. %v.Set(%d, %v) => %v, %v, want %v, %v",
+				index, lst.in, lst.index, lst.val,
+				list, err, lst.out, lst.outError)
+		}
+	}
+}
+
+//=============================================================================
+
+var listSetSliceTests = []struct {
+	in                List
+	start, stop, step int
+	values            List
+	out               List
+	outError          error
+}{
+	{List{0, 1, 2, 3}, 1, 3, 1, List{"a"}, List{0, "a", 3}, nil},
+	{List{0, 1, 2, 3}, 1, 2, 1, List{"a", "b", "c"},
+		List{0, "a", "b", "c", 2, 3}, nil},
+	{List{0, 1, 2, 3}, Omit, Omit, Omit, List{}, List{}, nil},
+	{List{0, 1, 2, 3}, 2, 2, 1, List{"x"}, List{0, 1, "x", 2, 3}, nil},
+	{List{0, 1, 2, 3}, 3, 1, 1, List{"x"}, List{0, 1, 2, "x", 3}, nil},
+	{List{0, 1, 2, 3}, Omit, Omit, 2, List{"a", "b"},
+		List{"a", 1, "b", 3}, nil},
+	{List{0, 1, 2, 3}, Omit, Omit, -1, List{"a", "b", "c", "d"},
+		List{"d", "c", "b", "a"}, nil},
+	{List{0, 1, 2, 3}, Omit, Omit, 2, List{"a"},
+		List{0, 1, 2, 3}, ErrSliceSize},
+	{List{0, 1, 2, 3}, Omit, Omit, 0, List{},
+		List{0, 1, 2, 3}, ErrZeroSliceStep},
+}
+
+func TestListSetSlice(t *testing.T) {
+	for index, lsst := range listSetSliceTests {
+		list := append(List{}, lsst.in...)
+		err := list.SetSlice(lsst.start, lsst.stop, lsst.step, lsst.values)
+		if !errors.Is(err, lsst.outError) || !reflect.DeepEqual(list, lsst.out) {
+			t.Errorf(
+				"%d. %v.SetSlice(%d, %d, %d, %v) => %v, %v, want %v, %v",
+				index, lsst.in, lsst.start, lsst.stop, lsst.step,
+				lsst.values, list, err, lsst.out, lsst.outError)
+		}
+	}
+}
+
+func TestListSetSliceItself(t *testing.T) {
+	list := List{1, 2, 3}
+	if err := list.SetSlice(1, 1, 1, list); err != nil {
+		t.Fatalf("%v.SetSlice(1, 1, 1, itself) => %v", list, err)
+	}
+	want := List{1, 1, 2, 3, 2, 3}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("SetSlice(1, 1, 1, itself) => %v, want %v", list, want)
+	}
+}
+
+//=============================================================================
+
+var listSliceTests = []struct {
+	in                List
+	start, stop, step int
+	out               List
+	outError          error
+}{
+	{List{0, 1, 2, 3, 4}, 1, 3, 1, List{1, 2}, nil},
+	{List{0, 1, 2, 3, 4}, 1, -1, Omit, List{1, 2, 3}, nil},
+	{List{0, 1, 2, 3, 4}, Omit, Omit, 2, List{0, 2, 4}, nil},
+	{List{0, 1, 2, 3, 4}, Omit, Omit, -1, List{4, 3, 2, 1, 0}, nil},
+	{List{0, 1, 2, 3, 4}, -2, Omit, Omit, List{3, 4}, nil},
+	{List{0, 1, 2, 3, 4}, 3, 0, -2, List{3, 1}, nil},
+	{List{0, 1, 2, 3, 4}, -100, 100, Omit, List{0, 1, 2, 3, 4}, nil},
+	{List{0, 1, 2, 3, 4}, 3, 1, 1, List{}, nil},
+	{List{}, Omit, Omit, -1, List{}, nil},
+	{List{0, 1}, Omit, Omit, 0, nil, ErrZeroSliceStep},
+}
+
+func TestListSlice(t *testing.T) {
+	for index, lst := range listSliceTests {
+		out, err := lst.in.Slice(lst.start, lst.stop, lst.step)
+		if !errors.Is(err, lst.outError) || !reflect.DeepEqual(out, lst.out) {
+			t.Errorf(
+				"%d. %v.Slice(%d, %d, %d) => %v, %v, want %v, %v",
+				index, lst.in, lst.start, lst.stop, lst.step,
+				out, err, lst.out, lst.outError)
+		}
+	}
+}
+
+//=============================================================================
+
+var listSortTests = []struct {
+	in      List
+	key     func(interface{}) interface{}
+	reverse bool
+	out     List
+	isError bool
+}{
+	{List{"o", "ze", "a", "two"}, nil, false,
+		List{"a", "o", "two", "ze"}, false},
+	{List{2, 3, 1, -2}, nil, false, List{-2, 1, 2, 3}, false},
+	{List{2, 1.3, 1}, nil, false, List{1, 1.3, 2}, false},
+	{List{int8(2), uint(1), 1.5, int64(-3), true}, nil, false,
+		List{int64(-3), uint(1), true, 1.5, int8(2)}, false},
+	{List{List{1, 2}, List{1}, List{0, 5}}, nil, false,
+		List{List{0, 5}, List{1}, List{1, 2}}, false},
+	{List{2, 3, 1}, nil, true, List{3, 2, 1}, false},
+	{List{"bb", "a", "cc", "d"},
+		func(v interface{}) interface{} { return len(v.(string)) }, false,
+		List{"a", "d", "bb", "cc"}, false},
+	{List{"bb", "a", "cc", "d"},
+		func(v interface{}) interface{} { return len(v.(string)) }, true,
+		List{"bb", "cc", "a", "d"}, false},
+	{List{1, "one", 2}, nil, false, List{1, "one", 2}, true},
+	{List{nil, 1}, nil, false, List{nil, 1}, true},
+	{List{}, nil, false, List{}, false},
+}
+
+func TestListSort(t *testing.T) {
+	for index, lst := range listSortTests {
+		list := append(List{}, lst.in...)
+		err := list.Sort(lst.key, lst.reverse)
+		if lst.isError {
+			var typeErr *TypeError
+			if !errors.As(err, &typeErr) || !errors.Is(err, ErrUnorderable) {
+				t.Errorf("%d. %v.Sort() => error %v, want TypeError",
+					index, lst.in, err)
+			}
+		} else if err != nil {
+			t.Errorf("%d. %v.Sort() => error %v", index, lst.in, err)
+		}
+		if !reflect.DeepEqual(list, lst.out) {
+			t.Errorf(
+				"%d. %v.Sort() => out list = %v, want %v",
+				index, lst.in, list, lst.out)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestListSorted(t *testing.T) {
+	list := List{3, 1, 2}
+	sorted, err := list.Sorted(nil, false)
+	if err != nil || !reflect.DeepEqual(sorted, List{1, 2, 3}) {
+		t.Errorf("%v.Sorted() => %v, %v, want [1 2 3], nil",
+			list, sorted, err)
+	}
+	if !reflect.DeepEqual(list, List{3, 1, 2}) {
+		t.Errorf("Sorted() changed the list to %v", list)
+	}
+}
 
 //=============================================================================
 
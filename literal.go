@@ -0,0 +1,694 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+var (
+	// ErrInvalidLiteral is returned when user want to parse text that is
+	// not a Python literal
+	ErrInvalidLiteral = errors.New("invalid literal")
+)
+
+// SyntaxError is returned by ParseLiteral for text that is not a Python
+// literal. It wraps ErrInvalidLiteral.
+type SyntaxError struct {
+	Msg string // what is wrong, e.g. "unexpected character"
+	Pos int    // character (not byte) index where parsing failed
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s (char %d)", e.Msg, e.Pos)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrInvalidLiteral
+}
+
+//=============================================================================
+
+// ParseLiteral parses Python literal, same as Python's ast.literal_eval.
+// It accepts lists, tuples, dicts, sets, set(), strings with any quotes and
+// prefixes (r, b, u), concatenated strings, numbers (with underscores,
+// 0x, 0o and 0b, complex like 1+2j), True, False and None, and returns:
+//
+//	[...]          List
+//	(...)          Tuple
+//	{'k': v}       Dict, or *HashDict if any key is not a string
+//	{a, b}, set()  *Set
+//	'...'          string
+//	b'...'         []byte
+//	numbers        int (*big.Int if it doesn't fit), float64 or complex128
+//
+// Comments and whitespace are skipped. Invalid text returns *SyntaxError,
+// unhashable dict keys and set elements return *TypeError.
+//
+//	v, _ := listdict.ParseLiteral(`['a', 2, {'k': None}]`)
+//	// v = List{"a", 2, Dict{"k": nil}}
+func ParseLiteral(text string) (interface{}, error) {
+	p := &literalParser{src: text}
+	p.skipSpace()
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	// Top level "1, 2" is a tuple, same as in Python
+	if p.peek() == ',' {
+		tuple := Tuple{value}
+		for p.peek() == ',' {
+			p.pos++
+			p.skipSpace()
+			if p.pos >= len(p.src) {
+				break
+			}
+			if value, err = p.value(); err != nil {
+				return nil, err
+			}
+			tuple = append(tuple, value)
+			p.skipSpace()
+		}
+		value = tuple
+	}
+	if p.pos < len(p.src) {
+		return nil, p.error("unexpected character", p.pos)
+	}
+	return value, nil
+}
+
+//=============================================================================
+
+// simpleEscapes are values of escape sequences without digits.
+var simpleEscapes = map[byte]string{
+	'\n': "", '\\': "\\", '\'': "'", '"': "\"", 'a': "\a", 'b': "\b",
+	'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+}
+
+// literalParser holds state of a single ParseLiteral.
+type literalParser struct {
+	src   string
+	pos   int // byte index in src
+	depth int // number of values being parsed, nested in each other
+}
+
+// error returns *SyntaxError for byte index pos.
+func (p *literalParser) error(msg string, pos int) error {
+	return &SyntaxError{Msg: msg, Pos: utf8.RuneCountInString(p.src[:pos])}
+}
+
+// unclosed returns *SyntaxError for bracket at index open.
+func (p *literalParser) unclosed(open int) error {
+	return p.error(fmt.Sprintf("'%c' was never closed", p.src[open]), open)
+}
+
+// peek returns byte at current position, 0 at the end.
+func (p *literalParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+// skipSpace skips whitespace, comments and line continuations.
+func (p *literalParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\\' && strings.HasPrefix(p.src[p.pos+1:], "\n"):
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+func (p *literalParser) value() (interface{}, error) {
+	if p.depth >= container.MaxDepth {
+		return nil, p.error("too many nested parentheses", p.pos)
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	switch c := p.peek(); {
+	case c == '[':
+		p.pos++
+		items, err := p.items(p.pos-1, ']')
+		if err != nil {
+			return nil, err
+		}
+		return List(items), nil
+	case c == '(':
+		return p.tuple()
+	case c == '{':
+		return p.dictOrSet()
+	case c == '\'' || c == '"':
+		return p.stringLiterals()
+	case c == '-' || c == '+' || c == '.' || isDecimal(c):
+		return p.signedNumber()
+	case isNameStart(c):
+		start := p.pos
+		name := p.name()
+		if p.peek() == '\'' || p.peek() == '"' {
+			p.pos = start
+			return p.stringLiterals()
+		}
+		switch name {
+		case "None":
+			return nil, nil
+		case "True":
+			return true, nil
+		case "False":
+			return false, nil
+		case "set":
+			p.skipSpace()
+			if strings.HasPrefix(p.src[p.pos:], "(") {
+				p.pos++
+				p.skipSpace()
+				if p.peek() == ')' {
+					p.pos++
+					return NewSet(), nil
+				}
+			}
+		}
+		return nil, p.error(fmt.Sprintf("malformed node or string: %s", name),
+			start)
+	case c == 0:
+		return nil, p.error("unexpected end of text", p.pos)
+	}
+	return nil, p.error("unexpected character", p.pos)
+}
+
+// items parses comma separated values up to close, trailing comma allowed.
+// Bracket at index open is already read.
+func (p *literalParser) items(open int, close byte) ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		p.skipSpace()
+		if p.peek() == close {
+			p.pos++
+			return items, nil
+		}
+		if p.pos >= len(p.src) {
+			return nil, p.unclosed(open)
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, val)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case close:
+		case 0:
+			return nil, p.unclosed(open)
+		default:
+			return nil, p.error(fmt.Sprintf("expecting ',' or '%c'", close),
+				p.pos)
+		}
+	}
+}
+
+func (p *literalParser) tuple() (interface{}, error) {
+	start := p.pos
+	p.pos++ // (
+	p.skipSpace()
+	if p.peek() == ')' {
+		p.pos++
+		return Tuple{}, nil
+	}
+	first, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	// (a) is just a in parentheses
+	if p.peek() == ')' {
+		p.pos++
+		return first, nil
+	}
+	if p.peek() != ',' {
+		return nil, p.error("expecting ',' or ')'", p.pos)
+	}
+	p.pos++
+	rest, err := p.items(start, ')')
+	if err != nil {
+		return nil, err
+	}
+	return append(Tuple{first}, rest...), nil
+}
+
+func (p *literalParser) dictOrSet() (interface{}, error) {
+	start := p.pos
+	p.pos++ // {
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return Dict{}, nil
+	}
+	first, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ':' {
+		p.pos = start + 1
+		items, err := p.items(start, '}')
+		if err != nil {
+			return nil, err
+		}
+		return SetFromList(items)
+	}
+
+	keys, values := List{first}, List{}
+loop:
+	for {
+		if p.peek() != ':' {
+			return nil, p.error("expecting ':'", p.pos)
+		}
+		p.pos++
+		p.skipSpace()
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+		p.skipSpace()
+		switch p.peek() {
+		case '}':
+			p.pos++
+			break loop
+		case ',':
+			p.pos++
+			p.skipSpace()
+			if p.peek() == '}' {
+				p.pos++
+				break loop
+			}
+		case 0:
+			return nil, p.unclosed(start)
+		default:
+			return nil, p.error("expecting ',' or '}'", p.pos)
+		}
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipSpace()
+	}
+
+	dict := make(Dict, len(keys))
+	for i, key := range keys {
+		s, ok := key.(string)
+		if !ok {
+			return hashDictFromPairs(keys, values)
+		}
+		dict[s] = values[i]
+	}
+	return dict, nil
+}
+
+// hashDictFromPairs returns *HashDict with keys set to values in order.
+func hashDictFromPairs(keys, values List) (*HashDict, error) {
+	dict := NewHashDict()
+	for i, key := range keys {
+		if err := dict.Set(key, values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+//=============================================================================
+
+// stringLiterals parses one or more adjacent string literals and joins them.
+func (p *literalParser) stringLiterals() (interface{}, error) {
+	start := p.pos
+	var str strings.Builder
+	var isBytes bool
+	for count := 0; ; count++ {
+		s, b, err := p.stringLiteral()
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 && b != isBytes {
+			return nil, p.error("cannot mix bytes and nonbytes literals",
+				start)
+		}
+		isBytes = b
+		str.WriteString(s)
+
+		// Adjacent strings are joined, 'a' "b" is 'ab'
+		mark := p.pos
+		p.skipSpace()
+		c := p.peek()
+		if c != '\'' && c != '"' && !isNameStart(c) {
+			p.pos = mark
+			break
+		}
+		if isNameStart(c) {
+			p.name()
+			if c = p.peek(); c != '\'' && c != '"' {
+				p.pos = mark
+				break
+			}
+			p.pos = mark
+			p.skipSpace()
+		}
+	}
+	if isBytes {
+		return []byte(str.String()), nil
+	}
+	return str.String(), nil
+}
+
+// stringLiteral parses a single string literal with optional prefix. It returns
+// its value and true for bytes.
+func (p *literalParser) stringLiteral() (string, bool, error) {
+	start := p.pos
+	prefix := strings.ToLower(p.name())
+	switch prefix {
+	case "", "r", "u", "b", "br", "rb":
+	default:
+		return "", false, p.error(fmt.Sprintf("invalid string prefix %q",
+			prefix), start)
+	}
+	raw := strings.Contains(prefix, "r")
+	isBytes := strings.Contains(prefix, "b")
+
+	quote := p.src[p.pos : p.pos+1]
+	if strings.HasPrefix(p.src[p.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	p.pos += len(quote)
+
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.src) ||
+			(len(quote) == 1 && p.src[p.pos] == '\n') {
+			return "", false, p.error("unterminated string literal", start)
+		}
+		if strings.HasPrefix(p.src[p.pos:], quote) {
+			p.pos += len(quote)
+			return sb.String(), isBytes, nil
+		}
+
+		c := p.src[p.pos]
+		if isBytes && c >= utf8.RuneSelf {
+			return "", false, p.error(
+				"bytes can only contain ASCII literal characters", p.pos)
+		}
+		if c != '\\' {
+			sb.WriteByte(c)
+			p.pos++
+			continue
+		}
+		if p.pos+1 >= len(p.src) {
+			return "", false, p.error("unterminated string literal", start)
+		}
+		if raw {
+			// Backslash stays, but the next character can't end the string
+			sb.WriteString(p.src[p.pos : p.pos+2])
+			p.pos += 2
+			continue
+		}
+		if err := p.escape(&sb, isBytes); err != nil {
+			return "", false, err
+		}
+	}
+}
+
+// escape writes value of escape sequence at current position to sb.
+func (p *literalParser) escape(sb *strings.Builder, isBytes bool) error {
+	start := p.pos
+	c := p.src[p.pos+1]
+	p.pos += 2
+	if simple, ok := simpleEscapes[c]; ok {
+		sb.WriteString(simple)
+		return nil
+	}
+
+	var digits int
+	var base int
+	switch {
+	case c >= '0' && c <= '7':
+		// Up to 3 octal digits, the first one is already read
+		p.pos--
+		end := p.pos
+		for end < len(p.src) && end < p.pos+3 && p.src[end] >= '0' &&
+			p.src[end] <= '7' {
+			end++
+		}
+		n, _ := strconv.ParseUint(p.src[p.pos:end], 8, 32)
+		p.pos = end
+		writeCode(sb, rune(n), isBytes)
+		return nil
+	case c == 'x':
+		digits, base = 2, 16
+	case c == 'u' && !isBytes:
+		digits, base = 4, 16
+	case c == 'U' && !isBytes:
+		digits, base = 8, 16
+	default:
+		// Unknown escapes are kept as they are, same as in Python
+		sb.WriteString(p.src[start:p.pos])
+		return nil
+	}
+
+	if p.pos+digits > len(p.src) {
+		return p.error(fmt.Sprintf("truncated \\%c escape", c), start)
+	}
+	hex := p.src[p.pos : p.pos+digits]
+	n, err := strconv.ParseUint(hex, base, 32)
+	if err != nil || strings.ContainsAny(hex, "+-_") {
+		return p.error(fmt.Sprintf("truncated \\%c escape", c), start)
+	}
+	if n > utf8.MaxRune {
+		return p.error("illegal Unicode character", start)
+	}
+	p.pos += digits
+	writeCode(sb, rune(n), isBytes)
+	return nil
+}
+
+// writeCode writes character code n to sb, as byte for bytes literals.
+func writeCode(sb *strings.Builder, n rune, isBytes bool) {
+	if isBytes {
+		sb.WriteByte(byte(n))
+	} else {
+		sb.WriteRune(n)
+	}
+}
+
+//=============================================================================
+
+// signedNumber parses a number with optional sign, or complex number
+// written as real part plus or minus imaginary part, e.g. -1+2j.
+func (p *literalParser) signedNumber() (interface{}, error) {
+	first, err := p.unaryNumber()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := first.(complex128); ok {
+		return first, nil
+	}
+
+	mark := p.pos
+	p.skipSpace()
+	if c := p.peek(); c == '+' || c == '-' {
+		start := p.pos
+		p.pos++
+		p.skipSpace()
+		second, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		im, ok := second.(complex128)
+		if !ok {
+			return nil, p.error("malformed node or string: only complex "+
+				"numbers can be added", start)
+		}
+		if c == '-' {
+			im = -im
+		}
+		return complex(toFloat(first), imag(im)), nil
+	}
+	p.pos = mark
+	return first, nil
+}
+
+// unaryNumber parses a number with optional single + or -.
+func (p *literalParser) unaryNumber() (interface{}, error) {
+	sign := p.peek()
+	if sign != '-' && sign != '+' {
+		return p.number()
+	}
+	p.pos++
+	p.skipSpace()
+	val, err := p.number()
+	if err != nil || sign == '+' {
+		return val, err
+	}
+	switch v := val.(type) {
+	case int:
+		if v == -v && v != 0 {
+			// -MinInt doesn't fit
+			return new(big.Int).Neg(big.NewInt(int64(v))), nil
+		}
+		return -v, nil
+	case *big.Int:
+		return bigToInt(new(big.Int).Neg(v)), nil
+	case float64:
+		return -v, nil
+	case complex128:
+		return -v, nil
+	}
+	return val, nil
+}
+
+// number parses unsigned int, float or imaginary number.
+func (p *literalParser) number() (interface{}, error) {
+	start := p.pos
+	if strings.HasPrefix(p.src[p.pos:], "0") && p.pos+1 < len(p.src) {
+		base := map[byte]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2,
+			'B': 2}[p.src[p.pos+1]]
+		if base != 0 {
+			p.pos += 2
+			digits, ok := p.digits(func(c byte) bool {
+				_, err := strconv.ParseUint(string(c), base, 8)
+				return err == nil
+			}, true)
+			if !ok || digits == "" {
+				return nil, p.error("invalid number literal", start)
+			}
+			n, _ := new(big.Int).SetString(digits, base)
+			return bigToInt(n), nil
+		}
+	}
+
+	whole, ok := p.digits(isDecimal, false)
+	if !ok {
+		return nil, p.error("invalid decimal literal", start)
+	}
+	isFloat := false
+	if p.peek() == '.' {
+		p.pos++
+		fraction, ok := p.digits(isDecimal, false)
+		if !ok || (whole == "" && fraction == "") {
+			return nil, p.error("invalid decimal literal", start)
+		}
+		isFloat = true
+	} else if whole == "" {
+		return nil, p.error("unexpected character", start)
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		exp, ok := p.digits(isDecimal, false)
+		if !ok || exp == "" {
+			return nil, p.error("invalid decimal literal", start)
+		}
+		isFloat = true
+	}
+	text := strings.ReplaceAll(p.src[start:p.pos], "_", "")
+
+	if c := p.peek(); c == 'j' || c == 'J' {
+		p.pos++
+		f, _ := strconv.ParseFloat(text, 64)
+		return complex(0, f), nil
+	}
+	if isNameStart(p.peek()) {
+		return nil, p.error("invalid decimal literal", start)
+	}
+	if isFloat {
+		// Too large numbers are infinite, same as Python's float()
+		f, _ := strconv.ParseFloat(text, 64)
+		return f, nil
+	}
+	if len(text) > 1 && text[0] == '0' && strings.Trim(text, "0") != "" {
+		return nil, p.error("leading zeros in decimal integer literals "+
+			"are not permitted", start)
+	}
+	n, _ := new(big.Int).SetString(text, 10)
+	return bigToInt(n), nil
+}
+
+// digits reads digits with single underscores between them. With
+// leadingUnderscore the first digit can follow an underscore, like 0x_1.
+func (p *literalParser) digits(isDigit func(byte) bool,
+	leadingUnderscore bool) (string, bool) {
+
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '_' {
+			if (sb.Len() == 0 && !leadingUnderscore) || p.pos+1 >=
+				len(p.src) || !isDigit(p.src[p.pos+1]) {
+				return "", false
+			}
+			leadingUnderscore = false
+			p.pos++
+			continue
+		}
+		if !isDigit(c) {
+			break
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	return sb.String(), true
+}
+
+// name reads identifier, it can be empty.
+func (p *literalParser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) && (isNameStart(p.src[p.pos]) ||
+		isDecimal(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// bigToInt returns n as int if it fits.
+func bigToInt(n *big.Int) interface{} {
+	if n.IsInt64() && int64(int(n.Int64())) == n.Int64() {
+		return int(n.Int64())
+	}
+	return n
+}
+
+// toFloat returns int, *big.Int or float64 as float64.
+func toFloat(value interface{}) float64 {
+	n, _ := toNumber(value)
+	return n.float()
+}
+
+func isDecimal(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
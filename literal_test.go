@@ -0,0 +1,158 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+//=============================================================================
+
+var parseLiteralTests = []struct {
+	in  string
+	out interface{}
+}{
+	{"None", nil},
+	{"['a', 2, {'k': None}]", List{"a", 2, Dict{"k": nil}}},
+	{" [True, False, [],] ", List{true, false, List{}}},
+	{"(1, 'a')", Tuple{1, "a"}},
+	{"(1,)", Tuple{1}},
+	{"(1)", 1},
+	{"()", Tuple{}},
+	{"1, 2", Tuple{1, 2}},
+	{"{}", Dict{}},
+	{"{'a': 1, 'a': 2, \"b\": (),}", Dict{"a": 2, "b": Tuple{}}},
+	{`"it's"`, "it's"},
+	{`'it\'s'`, "it's"},
+	{`'''a'b"c
+d'''`, "a'b\"c\nd"},
+	{`"""x"""`, "x"},
+	{`'a' "b"  'c'`, "abc"},
+	{`'\x41\101ż\U0001F600\n\q'`, "AAż\U0001F600\n\\q"},
+	{`r'\n\''`, `\n\'`},
+	{`u'ż'`, "ż"},
+	{`b'a\x00\xff' B"b"`, []byte("a\x00\xffb")},
+	{`rb'\x00'`, []byte(`\x00`)},
+	{"-12", -12},
+	{"+1_000", 1000},
+	{"0", 0},
+	{"000", 0},
+	{"0x_ff", 255},
+	{"0o17", 15},
+	{"-0b101", -5},
+	{"1.5e3", 1500.0},
+	{".5", 0.5},
+	{"5.", 5.0},
+	{"1e400", math.Inf(1)},
+	{"2j", complex(0, 2)},
+	{"-1 - 2.5j", complex(-1, -2.5)},
+	{"[1, # comment\n 2]", List{1, 2}},
+	{"123456789012345678901234567890", func() *big.Int {
+		b, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		return b
+	}()},
+}
+
+func TestParseLiteral(t *testing.T) {
+	for index, pt := range parseLiteralTests {
+		if out, err := ParseLiteral(pt.in); !reflect.DeepEqual(out, pt.out) ||
+			err != nil {
+			t.Errorf("%d. ParseLiteral(%s) => %#v, %v, want %#v",
+				index, pt.in, out, err, pt.out)
+		}
+	}
+}
+
+func TestParseLiteralSets(t *testing.T) {
+	out, err := ParseLiteral("{1, 'a', (2, 3), 1}")
+	set, ok := out.(*Set)
+	if err != nil || !ok || !set.IsEqual(newTestSet(t, 1, "a", Tuple{2, 3})) {
+		t.Errorf("ParseLiteral(set) => %v, %v, want {1, 'a', (2, 3)}", out, err)
+	}
+	if out, _ := ParseLiteral("set( )"); Repr(out) != "set()" {
+		t.Errorf("ParseLiteral(set()) => %v, want set()", out)
+	}
+
+	out, err = ParseLiteral("{1: 'a', 'b': [2], (1, 2): None}")
+	dict, ok := out.(*HashDict)
+	if err != nil || !ok || Repr(dict) != "{1: 'a', 'b': [2], (1, 2): None}" {
+		t.Errorf("ParseLiteral(dict) => %v, %v, want *HashDict", out, err)
+	}
+
+	if _, err := ParseLiteral("{[1]: 2}"); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("ParseLiteral(list key) => %v, want ErrUnhashable", err)
+	}
+	if _, err := ParseLiteral("{1, [2]}"); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("ParseLiteral(list in set) => %v, want ErrUnhashable", err)
+	}
+}
+
+var parseLiteralErrorTests = []struct {
+	in  string
+	err string
+}{
+	{"", "unexpected end of text (char 0)"},
+	{"[1, 2", "'[' was never closed (char 0)"},
+	{"[1 2]", "expecting ',' or ']' (char 3)"},
+	{"{'a' 1}", "expecting ',' or '}' (char 5)"},
+	{"{'a': 1, 'b'}", "expecting ':' (char 12)"},
+	{"'abc", "unterminated string literal (char 0)"},
+	{"'a\nb'", "unterminated string literal (char 0)"},
+	{"'ż' 1", "unexpected character (char 4)"},
+	{`'\x4'`, `truncated \x escape (char 1)`},
+	{`b'ż'`, "bytes can only contain ASCII literal characters (char 2)"},
+	{`'a' b'b'`, "cannot mix bytes and nonbytes literals (char 0)"},
+	{`f'x'`, `invalid string prefix "f" (char 0)`},
+	{"01", "leading zeros in decimal integer literals are not permitted " +
+		"(char 0)"},
+	{"1__0", "invalid decimal literal (char 0)"},
+	{"1abc", "invalid decimal literal (char 0)"},
+	{"1 + 2", "malformed node or string: only complex numbers can be added " +
+		"(char 2)"},
+	{"foo", "malformed node or string: foo (char 0)"},
+	{"[1] [2]", "unexpected character (char 4)"},
+}
+
+func TestParseLiteralErrors(t *testing.T) {
+	for index, pt := range parseLiteralErrorTests {
+		_, err := ParseLiteral(pt.in)
+		if err == nil || err.Error() != pt.err ||
+			!errors.Is(err, ErrInvalidLiteral) {
+			t.Errorf("%d. ParseLiteral(%s) => %v, want %s",
+				index, pt.in, err, pt.err)
+		}
+	}
+	deep := strings.Repeat("[(", container.MaxDepth)
+	want := fmt.Sprintf("too many nested parentheses (char %d)", container.MaxDepth)
+	if _, err := ParseLiteral(deep); err == nil || err.Error() != want ||
+		!errors.Is(err, ErrInvalidLiteral) {
+		t.Errorf("ParseLiteral(deeply nested) => %v, want %s", err, want)
+	}
+	nested := strings.Repeat("[", container.MaxDepth-1) + strings.Repeat("]", container.MaxDepth-1)
+	if _, err := ParseLiteral(nested); err != nil {
+		t.Errorf("ParseLiteral(nested %d times) => %v, want nil",
+			container.MaxDepth-1, err)
+	}
+}
+
+func TestReprParseLiteral(t *testing.T) {
+	in := List{"a'\"\\\n", 1, -0.0, 1e100, []byte{0, 'x'}, Tuple{nil, true},
+		Dict{"k": List{complex(1, -1)}}}
+	out, err := ParseLiteral(in.Repr())
+	if err != nil || !reflect.DeepEqual(out, in) {
+		t.Errorf("ParseLiteral(%s) => %#v, %v, want %#v", in.Repr(), out, err,
+			in)
+	}
+}
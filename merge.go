@@ -0,0 +1,232 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ListStrategy tells DeepMerge what to do when both Dicts have a List
+// under the same key.
+type ListStrategy int
+
+const (
+	// ListReplace treats Lists as any other values, see ConflictMode
+	ListReplace ListStrategy = iota
+	// ListAppend appends right List to the left one
+	ListAppend
+	// ListAppendUnique appends elements of right List that are not in
+	// the left one (compared with Equal)
+	ListAppendUnique
+	// ListMergeByIndex merges elements with the same index, Dicts are
+	// merged recursively and other elements are conflicts
+	ListMergeByIndex
+)
+
+// ConflictMode tells DeepMerge what to do when both Dicts have different
+// values under the same key that can't be merged.
+type ConflictMode int
+
+const (
+	// TakeRight uses value from the right Dict, same as Update
+	TakeRight ConflictMode = iota
+	// TakeLeft keeps value from the left Dict
+	TakeLeft
+	// ConflictError stops merging and returns error
+	ConflictError
+)
+
+// MergeOptions are options of DeepMerge. Paths are keys from the top Dict
+// joined with ".", List indexes are used as keys, e.g. "servers.0.port".
+type MergeOptions struct {
+	Lists          ListStrategy            // strategy for List values
+	ListsByPath    map[string]ListStrategy // strategy for given paths
+	Conflicts      ConflictMode            // what to do with conflicts
+	ConflictByPath map[string]ConflictMode // conflict mode for given paths
+}
+
+// Conflict is a path where DeepMerge found different values.
+type Conflict struct {
+	Path  []string    // keys from the top Dict
+	Left  interface{} // value from the left Dict
+	Right interface{} // value from the right Dict
+}
+
+var (
+	// ErrMergeConflict is returned when user want to merge Dicts with
+	// different values under the same key using ConflictError mode
+	ErrMergeConflict = errors.New("merge conflict")
+)
+
+//=============================================================================
+
+// Or returns a new dictionary with key-value pairs of the dictionary
+// updated with otherDict, same as Python's d | other.
+func (dict Dict) Or(otherDict Dict) Dict {
+	out := make(Dict, len(dict)+len(otherDict))
+	out.Update(dict)
+	out.Update(otherDict)
+	return out
+}
+
+// IOr updates the dictionary with otherDict, same as Python's d |= other.
+func (dict Dict) IOr(otherDict Dict) {
+	dict.Update(otherDict)
+}
+
+// String returns the conflict as "path: left != right".
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %v != %v", strings.Join(c.Path, "."), c.Left,
+		c.Right)
+}
+
+//=============================================================================
+
+// DeepMerge returns a new Dict with right merged into left. Nested Dicts
+// are merged recursively, Lists by options.Lists strategy and other
+// different values are conflicts resolved by options.Conflicts. Options can
+// be nil to use ListReplace and TakeRight, same as Update on every level.
+// It returns all conflicts found, in order of sorted keys, and *ValueError
+// wrapping ErrMergeConflict for the first conflict in ConflictError mode.
+// Dicts along merged paths are new, other values are not copied.
+//
+//	defaults := listdict.Dict{"db": listdict.Dict{"host": "localhost", "port": 5432}}
+//	file := listdict.Dict{"db": listdict.Dict{"host": "db.example.com"}}
+//	cfg, conflicts, _ := listdict.DeepMerge(defaults, file, nil)
+//	// cfg = {db: {host: db.example.com, port: 5432}}
+//	// conflicts = [db.host: localhost != db.example.com]
+func DeepMerge(left, right Dict, options *MergeOptions) (Dict, []Conflict,
+	error) {
+
+	if options == nil {
+		options = &MergeOptions{}
+	}
+	m := &merger{options: options}
+	out, err := m.mergeDicts(nil, left, right)
+	if err != nil {
+		return nil, m.conflicts, err
+	}
+	return out, m.conflicts, nil
+}
+
+// merger holds state of a single DeepMerge.
+type merger struct {
+	options   *MergeOptions
+	conflicts []Conflict
+}
+
+func (m *merger) mergeDicts(path []string, left, right Dict) (Dict, error) {
+	out := make(Dict, len(left)+len(right))
+	out.Update(left)
+
+	// Sorted keys, so conflicts are found in the same order every time
+	keys := make([]string, 0, len(right))
+	for key := range right {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		leftVal, ok := left[key]
+		if !ok {
+			out[key] = right[key]
+			continue
+		}
+		val, err := m.mergeValues(append(path[:len(path):len(path)], key),
+			leftVal, right[key])
+		if err != nil {
+			return nil, err
+		}
+		out[key] = val
+	}
+	return out, nil
+}
+
+func (m *merger) mergeValues(path []string, left, right interface{}) (
+	interface{}, error) {
+
+	switch l := left.(type) {
+	case Dict:
+		if r, ok := right.(Dict); ok {
+			return m.mergeDicts(path, l, r)
+		}
+	case List:
+		if r, ok := right.(List); ok {
+			return m.mergeLists(path, l, r)
+		}
+	}
+	if Equal(left, right) {
+		return right, nil
+	}
+	return m.conflict(path, left, right)
+}
+
+func (m *merger) mergeLists(path []string, left, right List) (
+	interface{}, error) {
+
+	strategy := m.options.Lists
+	if s, ok := m.options.ListsByPath[strings.Join(path, ".")]; ok {
+		strategy = s
+	}
+
+	switch strategy {
+	case ListAppend:
+		return append(append(List{}, left...), right...), nil
+	case ListAppendUnique:
+		out := append(List{}, left...)
+		for _, val := range right {
+			out.AppendIfMissing(val)
+		}
+		return out, nil
+	case ListMergeByIndex:
+		out := append(List{}, left...)
+		for i, val := range right {
+			if i >= len(left) {
+				out = append(out, val)
+				continue
+			}
+			merged, err := m.mergeValues(
+				append(path[:len(path):len(path)], strconv.Itoa(i)),
+				left[i], val)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = merged
+		}
+		return out, nil
+	}
+
+	if Equal(left, right) {
+		return right, nil
+	}
+	return m.conflict(path, left, right)
+}
+
+// conflict records a conflict and returns the value chosen by the mode.
+func (m *merger) conflict(path []string, left, right interface{}) (
+	interface{}, error) {
+
+	m.conflicts = append(m.conflicts,
+		Conflict{Path: path, Left: left, Right: right})
+
+	mode := m.options.Conflicts
+	if c, ok := m.options.ConflictByPath[strings.Join(path, ".")]; ok {
+		mode = c
+	}
+	switch mode {
+	case TakeLeft:
+		return left, nil
+	case ConflictError:
+		return nil, &ValueError{Value: strings.Join(path, "."),
+			Err: ErrMergeConflict}
+	}
+	return right, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+func TestDictOr(t *testing.T) {
+	a := Dict{"x": 1, "y": 2}
+	b := Dict{"y": 3, "z": 4}
+
+	if out := a.Or(b); !out.IsEqual(Dict{"x": 1, "y": 3, "z": 4}) ||
+		!a.IsEqual(Dict{"x": 1, "y": 2}) {
+		t.Errorf("Or() => %v, left %v", out, a)
+	}
+	if out := b.Or(a); !out.IsEqual(Dict{"x": 1, "y": 2, "z": 4}) {
+		t.Errorf("Or() => %v, want map[x:1 y:2 z:4]", out)
+	}
+	a.IOr(b)
+	if !a.IsEqual(Dict{"x": 1, "y": 3, "z": 4}) {
+		t.Errorf("IOr() => %v, want map[x:1 y:3 z:4]", a)
+	}
+}
+
+//=============================================================================
+
+var deepMergeTests = []struct {
+	options   *MergeOptions
+	out       Dict
+	conflicts []string
+}{
+	{nil,
+		Dict{"db": Dict{"host": "remote", "port": 5432},
+			"tags": List{"b", "a"}, "servers": List{Dict{"name": "a"}},
+			"debug": false, "new": 1},
+		[]string{"db.host", "debug", "servers", "tags"}},
+	{&MergeOptions{Lists: ListAppend, Conflicts: TakeLeft},
+		Dict{"db": Dict{"host": "localhost", "port": 5432},
+			"tags":    List{"a", "b", "a"},
+			"servers": List{Dict{"name": "x", "port": 1}, Dict{"name": "a"}},
+			"debug":   true, "new": 1},
+		[]string{"db.host", "debug"}},
+	{&MergeOptions{Lists: ListAppendUnique,
+		ListsByPath:    map[string]ListStrategy{"servers": ListMergeByIndex},
+		ConflictByPath: map[string]ConflictMode{"debug": TakeLeft}},
+		Dict{"db": Dict{"host": "remote", "port": 5432},
+			"tags":    List{"a", "b"},
+			"servers": List{Dict{"name": "a", "port": 1}},
+			"debug":   true, "new": 1},
+		[]string{"db.host", "debug", "servers.0.name"}},
+}
+
+func TestDeepMerge(t *testing.T) {
+	for index, dt := range deepMergeTests {
+		left := Dict{"db": Dict{"host": "localhost", "port": 5432},
+			"tags": List{"a"}, "servers": List{Dict{"name": "x", "port": 1}},
+			"debug": true}
+		right := Dict{"db": Dict{"host": "remote"}, "tags": List{"b", "a"},
+			"servers": List{Dict{"name": "a"}}, "debug": false, "new": 1}
+
+		out, conflicts, err := DeepMerge(left, right, dt.options)
+		if err != nil || !Equal(out, dt.out) {
+			t.Errorf("%d. DeepMerge() => %v, %v, want %v", index, out, err,
+				dt.out)
+		}
+		paths := []string{}
+		for _, c := range conflicts {
+			paths = append(paths, strings.Join(c.Path, "."))
+		}
+		if !reflect.DeepEqual(paths, dt.conflicts) {
+			t.Errorf("%d. DeepMerge() conflicts => %v, want %v", index,
+				paths, dt.conflicts)
+		}
+		if !Equal(left["db"], Dict{"host": "localhost", "port": 5432}) {
+			t.Errorf("%d. DeepMerge() changed left Dict to %v", index, left)
+		}
+	}
+}
+
+func TestDeepMergeError(t *testing.T) {
+	left := Dict{"a": Dict{"b": 1, "c": 1}}
+	right := Dict{"a": Dict{"b": 1, "c": "one"}}
+
+	_, conflicts, err := DeepMerge(left, right,
+		&MergeOptions{Conflicts: ConflictError})
+	if !errors.Is(err, ErrMergeConflict) || len(conflicts) != 1 ||
+		err.Error() != "merge conflict: a.c" {
+		t.Errorf("DeepMerge() => %v, %v, want error %v: a.c", conflicts,
+			err, ErrMergeConflict)
+	}
+	if c := conflicts[0].String(); c != "a.c: 1 != one" {
+		t.Errorf("Conflict => %v, want a.c: 1 != one", c)
+	}
+}
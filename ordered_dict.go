@@ -0,0 +1,297 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// OrderedDict is a dictionary that remembers insertion order of keys,
+// same as Python's collections.OrderedDict (and dict since Python 3.7).
+// Keys, Values, Items and JSON output follow the insertion order,
+// changing value of an existing key doesn't change its position.
+// The zero value is an empty OrderedDict ready to use.
+//
+//	d := listdict.NewOrderedDict()
+//	d.Set("b", 1)
+//	d.Set("a", 2)
+//	d.Keys() // [b a]
+type OrderedDict struct {
+	entries entryList[string, string]
+}
+
+// NewOrderedDict returns new empty OrderedDict.
+func NewOrderedDict() *OrderedDict {
+	dict := &OrderedDict{}
+	dict.entries.init()
+	return dict
+}
+
+// OrderedDictFromDict returns new OrderedDict with key-value pairs of
+// the dict. Dict has no order so keys are added in sorted order.
+func OrderedDictFromDict(dict Dict) *OrderedDict {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ordered := NewOrderedDict()
+	for _, key := range keys {
+		ordered.Set(key, dict[key])
+	}
+	return ordered
+}
+
+// OrderedDictFromKeys creates a new OrderedDict with keys from list, in list
+// order, and values set to defaultVal.
+func OrderedDictFromKeys(list List, defaultVal interface{}) *OrderedDict {
+	dict := NewOrderedDict()
+	for _, value := range list {
+		dict.Set(fmt.Sprintf("%v", value), defaultVal)
+	}
+	return dict
+}
+
+//=============================================================================
+
+// All returns an iterator over key-value pairs of the dictionary,
+// in insertion order.
+func (dict *OrderedDict) All() iter.Seq2[string, interface{}] {
+	return func(yield func(string, interface{}) bool) {
+		for e := range dict.entries.all() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Clear removes all elements from the dictionary.
+func (dict *OrderedDict) Clear() {
+	dict.entries.clear()
+}
+
+// Delete removes the given key from the dictionary.
+// It returns *KeyError if key is NOT in the dictionary.
+func (dict *OrderedDict) Delete(key string) error {
+	if dict.entries.remove(key) == nil {
+		return &KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+	return nil
+}
+
+// Get returns value for the given key or defaultVal if key is NOT in
+// the dictionary.
+func (dict *OrderedDict) Get(key string, defaultVal interface{}) interface{} {
+	if e := dict.entries.get(key); e != nil {
+		return e.value
+	}
+	return defaultVal
+}
+
+// HasKey returns true if key is in the dictionary, false otherwise.
+func (dict *OrderedDict) HasKey(key string) bool {
+	return dict.entries.get(key) != nil
+}
+
+// IsEqual returns true if dicts have equal key-value pairs in the same
+// order, same as comparing two OrderedDicts in Python. To ignore the order
+// compare results of ToDict.
+func (dict *OrderedDict) IsEqual(otherDict *OrderedDict) bool {
+	if dict.Len() != otherDict.Len() {
+		return false
+	}
+	others := slices.Collect(otherDict.entries.all())
+	i := 0
+	for e := range dict.entries.all() {
+		if e.key != others[i].key ||
+			!reflect.DeepEqual(e.value, others[i].value) {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// Items returns a list of the dictionary's [key, value] pairs,
+// in insertion order.
+func (dict *OrderedDict) Items() []List {
+	list := make([]List, 0, dict.Len())
+	for key, value := range dict.All() {
+		list = append(list, List{key, value})
+	}
+	return list
+}
+
+// IterKeys returns an iterator over keys of the dictionary,
+// in insertion order.
+func (dict *OrderedDict) IterKeys() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for key := range dict.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// IterValues returns an iterator over values of the dictionary,
+// in insertion order.
+func (dict *OrderedDict) IterValues() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for _, value := range dict.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns a list of the dictionary's keys, in insertion order.
+func (dict *OrderedDict) Keys() List {
+	list := make(List, 0, dict.Len())
+	for key := range dict.All() {
+		list = append(list, key)
+	}
+	return list
+}
+
+// Len returns the number of keys in the dictionary.
+func (dict *OrderedDict) Len() int {
+	return dict.entries.len()
+}
+
+// MarshalJSON returns the dictionary as JSON object with keys in insertion
+// order.
+func (dict *OrderedDict) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for key, value := range dict.All() {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		jsonKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		jsonValue, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(jsonKey)
+		buf.WriteByte(':')
+		buf.Write(jsonValue)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MoveToEnd moves an existing key to the end of the dictionary, or to the
+// beginning if last is false. It returns *KeyError if key is NOT in
+// the dictionary. An iteration in progress goes on with the keys after
+// its current one, so a key moved to the end is returned by it again.
+func (dict *OrderedDict) MoveToEnd(key string, last bool) error {
+	if !dict.entries.move(key, last) {
+		return &KeyError{Key: key, Err: ErrKeyNotFound}
+	}
+	return nil
+}
+
+// Pop returns value and remove the given key from the dictionary.
+// If the given key is NOT in the dictionary return defaultVal.
+func (dict *OrderedDict) Pop(key string, defaultVal interface{}) (
+	interface{}, error) {
+
+	if dict.Len() <= 0 {
+		return defaultVal, &KeyError{Key: key, Err: ErrRemoveFromEmptyDict}
+	}
+	e := dict.entries.remove(key)
+	if e == nil {
+		return defaultVal, nil
+	}
+	return e.value, nil
+}
+
+// PopItem removes and returns [key, value] pair from the dictionary.
+// Pairs are returned in LIFO order if last is true or FIFO order if false.
+func (dict *OrderedDict) PopItem(last bool) (List, error) {
+	if dict.Len() <= 0 {
+		return List{}, &KeyError{Err: ErrRemoveFromEmptyDict}
+	}
+	e := dict.entries.remove(dict.entries.first(last).key)
+	return List{e.key, e.value}, nil
+}
+
+// Set sets value for the given key. New keys are added at the end,
+// existing keys keep their position.
+func (dict *OrderedDict) Set(key string, value interface{}) {
+	dict.entries.set(key, key, value)
+}
+
+// SetDefault is like Get but will set key to defaultVal if key is not
+// already in the dictionary.
+func (dict *OrderedDict) SetDefault(key string,
+	defaultVal interface{}) interface{} {
+
+	if e := dict.entries.get(key); e != nil {
+		return e.value
+	}
+	dict.Set(key, defaultVal)
+	return defaultVal
+}
+
+// String returns dictionary key-value pairs as string, in insertion order.
+//
+//	d.String() => "one: 1, two: 2"
+func (dict *OrderedDict) String() string {
+	out := make([]string, 0, dict.Len())
+	for key, value := range dict.All() {
+		out = append(out, fmt.Sprintf("%v: %v", key, value))
+	}
+	return strings.Join(out, ", ")
+}
+
+// ToDict returns key-value pairs of the dictionary as a new Dict.
+func (dict *OrderedDict) ToDict() Dict {
+	out := make(Dict, dict.Len())
+	for key, value := range dict.All() {
+		out[key] = value
+	}
+	return out
+}
+
+// Update updates the dictionary with the key-value pairs in the otherDict
+// dictionary replacing current values and adding new keys, in otherDict
+// order, at the end.
+func (dict *OrderedDict) Update(otherDict *OrderedDict) {
+	for key, value := range otherDict.All() {
+		dict.Set(key, value)
+	}
+}
+
+// UpdateDict is the same as Update for unordered Dict, new keys are added
+// in sorted order.
+func (dict *OrderedDict) UpdateDict(otherDict Dict) {
+	dict.Update(OrderedDictFromDict(otherDict))
+}
+
+// Values returns a list of the dictionary's values, in insertion order.
+func (dict *OrderedDict) Values() List {
+	list := make(List, 0, dict.Len())
+	for _, value := range dict.All() {
+		list = append(list, value)
+	}
+	return list
+}
@@ -0,0 +1,206 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestOrderedDictOrder(t *testing.T) {
+	dict := NewOrderedDict()
+	dict.Set("c", 1)
+	dict.Set("a", 2)
+	dict.Set("b", 3)
+	dict.Set("c", 4)
+
+	if keys := dict.Keys(); !reflect.DeepEqual(keys, List{"c", "a", "b"}) {
+		t.Errorf("Keys() => %v, want [c a b]", keys)
+	}
+	if values := dict.Values(); !reflect.DeepEqual(values, List{4, 2, 3}) {
+		t.Errorf("Values() => %v, want [4 2 3]", values)
+	}
+	items := dict.Items()
+	if want := []List{{"c", 4}, {"a", 2}, {"b", 3}}; !reflect.DeepEqual(items,
+		want) {
+		t.Errorf("Items() => %v, want %v", items, want)
+	}
+
+	dict.Delete("a")
+	dict.Set("a", 5)
+	if keys := CollectList(dict.IterKeys()); !reflect.DeepEqual(keys,
+		List{"c", "b", "a"}) {
+		t.Errorf("IterKeys() after re-adding a => %v, want [c b a]", keys)
+	}
+	if err := dict.Delete("x"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Delete(x) => %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestOrderedDictDeleteWhileIterating(t *testing.T) {
+	dict := OrderedDictFromKeys(List{"a", "b", "c", "d", "e"}, 0)
+	var keys List
+	for key := range dict.All() {
+		keys = append(keys, key)
+		dict.Delete(key)
+		if key == "b" {
+			dict.Delete("c")
+		}
+	}
+	if !reflect.DeepEqual(keys, List{"a", "b", "d", "e"}) || dict.Len() != 0 {
+		t.Errorf("All() deleting keys => %v, %v, want [a b d e], empty",
+			keys, dict)
+	}
+
+	dict = OrderedDictFromKeys(List{"a", "b"}, 0)
+	keys = nil
+	for key := range dict.IterKeys() {
+		keys = append(keys, key)
+		dict.Clear()
+	}
+	if !reflect.DeepEqual(keys, List{"a"}) {
+		t.Errorf("IterKeys() with Clear() => %v, want [a]", keys)
+	}
+}
+
+func TestOrderedDictMoveWhileIterating(t *testing.T) {
+	dict := OrderedDictFromKeys(List{"a", "b", "c"}, 0)
+	var keys List
+	for key := range dict.IterKeys() {
+		if len(keys) == 0 {
+			dict.MoveToEnd("a", true)
+		}
+		keys = append(keys, key)
+	}
+	if !reflect.DeepEqual(keys, List{"a", "b", "c", "a"}) {
+		t.Errorf("IterKeys() with MoveToEnd(a, true) => %v, want [a b c a]",
+			keys)
+	}
+
+	keys = nil
+	for key := range dict.IterKeys() {
+		if len(keys) == 0 {
+			dict.MoveToEnd("a", false)
+		}
+		keys = append(keys, key)
+	}
+	if !reflect.DeepEqual(keys, List{"b", "c"}) ||
+		!reflect.DeepEqual(dict.Keys(), List{"a", "b", "c"}) {
+		t.Errorf("IterKeys() with MoveToEnd(a, false) => %v, dict %v, "+
+			"want [b c], [a b c]", keys, dict.Keys())
+	}
+}
+
+func TestOrderedDictZeroValue(t *testing.T) {
+	var dict OrderedDict
+	if dict.Len() != 0 || dict.HasKey("a") || len(dict.Keys()) != 0 {
+		t.Errorf("zero OrderedDict => %v, want empty", dict.Keys())
+	}
+	if err := dict.MoveToEnd("a", true); err == nil {
+		t.Errorf("MoveToEnd(a) on zero OrderedDict => nil, want error")
+	}
+	dict.Set("b", 1)
+	dict.Set("a", 2)
+	dict.MoveToEnd("b", true)
+	if !reflect.DeepEqual(dict.Items(), []List{{"a", 2}, {"b", 1}}) {
+		t.Errorf("zero OrderedDict after Set => %v, want [[a 2] [b 1]]",
+			dict.Items())
+	}
+}
+
+func TestOrderedDictPopItem(t *testing.T) {
+	dict := OrderedDictFromKeys(List{"one", "two", "three"}, 0)
+
+	if item, err := dict.PopItem(true); !reflect.DeepEqual(item,
+		List{"three", 0}) || err != nil {
+		t.Errorf("PopItem(true) => %v, %v, want [three 0], nil", item, err)
+	}
+	if item, err := dict.PopItem(false); !reflect.DeepEqual(item,
+		List{"one", 0}) || err != nil {
+		t.Errorf("PopItem(false) => %v, %v, want [one 0], nil", item, err)
+	}
+	if val, err := dict.Pop("two", nil); val != 0 || err != nil {
+		t.Errorf("Pop(two) => %v, %v, want 0, nil", val, err)
+	}
+	if _, err := dict.PopItem(true); !errors.Is(err, ErrRemoveFromEmptyDict) {
+		t.Errorf("PopItem() on empty dict => %v, want %v",
+			err, ErrRemoveFromEmptyDict)
+	}
+}
+
+func TestOrderedDictMoveToEnd(t *testing.T) {
+	dict := OrderedDictFromKeys(List{"a", "b", "c", "d", "e"}, nil)
+	dict.MoveToEnd("b", true)
+	dict.MoveToEnd("d", false)
+	if keys := dict.Keys(); !reflect.DeepEqual(keys,
+		List{"d", "a", "c", "e", "b"}) {
+		t.Errorf("Keys() => %v, want [d a c e b]", keys)
+	}
+	if err := dict.MoveToEnd("x", true); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("MoveToEnd(x) => %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestOrderedDictIsEqual(t *testing.T) {
+	ab := NewOrderedDict()
+	ab.Set("a", 1)
+	ab.Set("b", List{2})
+	ba := NewOrderedDict()
+	ba.Set("b", List{2})
+	ba.Set("a", 1)
+
+	if ab.IsEqual(ba) {
+		t.Errorf("%v.IsEqual(%v) => true, order should matter", ab, ba)
+	}
+	if !ab.ToDict().IsEqual(ba.ToDict()) {
+		t.Errorf("ToDict() of %v and %v should be equal", ab, ba)
+	}
+	ba.MoveToEnd("b", true)
+	if !ab.IsEqual(ba) {
+		t.Errorf("%v.IsEqual(%v) => false, want true", ab, ba)
+	}
+}
+
+func TestOrderedDictDict(t *testing.T) {
+	dict := OrderedDictFromDict(Dict{"b": 2, "a": 1, "c": 3})
+	if keys := dict.Keys(); !reflect.DeepEqual(keys, List{"a", "b", "c"}) {
+		t.Errorf("OrderedDictFromDict().Keys() => %v, want [a b c]", keys)
+	}
+	dict.UpdateDict(Dict{"a": 0, "e": 5, "d": 4})
+	if want := (Dict{"a": 0, "b": 2, "c": 3, "d": 4, "e": 5}); !dict.ToDict().
+		IsEqual(want) {
+		t.Errorf("ToDict() => %v, want %v", dict.ToDict(), want)
+	}
+	if dict.String() != "a: 0, b: 2, c: 3, d: 4, e: 5" {
+		t.Errorf("String() => %q", dict.String())
+	}
+
+	if val := dict.SetDefault("a", 9); val != 0 {
+		t.Errorf("SetDefault(a, 9) => %v, want 0", val)
+	}
+	if val := dict.Get("x", 9); val != 9 || dict.HasKey("x") {
+		t.Errorf("Get(x, 9) => %v, want 9 and no key", val)
+	}
+	dict.Clear()
+	if dict.Len() != 0 || len(dict.Keys()) != 0 {
+		t.Errorf("Clear() => %v, want empty dict", dict)
+	}
+}
+
+func TestOrderedDictMarshalJSON(t *testing.T) {
+	dict := NewOrderedDict()
+	dict.Set("z", 1)
+	dict.Set("a", List{"x", nil})
+	out, err := json.Marshal(dict)
+	if want := `{"z":1,"a":["x",null]}`; string(out) != want || err != nil {
+		t.Errorf("json.Marshal(%v) => %s, %v, want %s", dict, out, err, want)
+	}
+}
@@ -0,0 +1,1086 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package pickle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// Decoder reads pickles from a stream, same as Python's pickle.Unpickler.
+type Decoder struct {
+	// Registry holds globals allowed in pickles, nil refuses all of them
+	Registry *Registry
+	// PersistentLoad returns value for persistent ID, same as Python's
+	// persistent_load. If it's nil persistent IDs are refused.
+	PersistentLoad func(pid interface{}) (interface{}, error)
+
+	r   *bufio.Reader
+	pos int
+}
+
+// NewDecoder returns new Decoder reading from r with DefaultRegistry.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{Registry: DefaultRegistry(), r: bufio.NewReader(r)}
+}
+
+// Loads decodes a single pickle with DefaultRegistry, same as Python's
+// pickle.loads. Data after the end of the pickle is ignored.
+func Loads(data []byte) (interface{}, error) {
+	return NewDecoder(bytes.NewReader(data)).Decode()
+}
+
+// Decode reads the next pickle from the stream and returns its value.
+// It returns *UnpicklingError for invalid data or forbidden globals,
+// io.EOF if the stream has no more data and io.ErrUnexpectedEOF if
+// it ends in the middle of a pickle.
+func (d *Decoder) Decode() (interface{}, error) {
+	u := &unpickler{Decoder: d, memo: make(map[int]interface{}),
+		converted: make(map[interface{}]interface{})}
+	start := d.pos
+	for {
+		u.opPos = d.pos
+		op, err := d.r.ReadByte()
+		if err != nil {
+			if err == io.EOF && d.pos != start {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		d.pos++
+		if op == opStop {
+			value, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if value, err = u.convert(value); err != nil {
+				return nil, err
+			}
+			// Objects not reached from value are created too, as in Python
+			for _, obj := range u.objects {
+				if _, err := u.convert(obj); err != nil {
+					return nil, err
+				}
+			}
+			return value, nil
+		}
+		if err := u.run(op); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+//=============================================================================
+
+// Opcodes, names are the same as in Python's pickletools.
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opPopMark        = '1'
+	opDup            = '2'
+	opFloat          = 'F'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opLong           = 'L'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opPersID         = 'P'
+	opBinPersID      = 'Q'
+	opReduce         = 'R'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opBuild          = 'b'
+	opGlobal         = 'c'
+	opDict           = 'd'
+	opEmptyDict      = '}'
+	opAppends        = 'e'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opInst           = 'i'
+	opLongBinGet     = 'j'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opObj            = 'o'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opSetItem        = 's'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opSetItems       = 'u'
+	opBinFloat       = 'G'
+
+	// Protocol 2
+	opProto    = 0x80
+	opNewObj   = 0x81
+	opExt1     = 0x82
+	opExt2     = 0x83
+	opExt4     = 0x84
+	opTuple1   = 0x85
+	opTuple2   = 0x86
+	opTuple3   = 0x87
+	opNewTrue  = 0x88
+	opNewFalse = 0x89
+	opLong1    = 0x8a
+	opLong4    = 0x8b
+
+	// Protocol 3
+	opBinBytes      = 'B'
+	opShortBinBytes = 'C'
+
+	// Protocol 4
+	opShortBinUnicode = 0x8c
+	opBinUnicode8     = 0x8d
+	opBinBytes8       = 0x8e
+	opEmptySet        = 0x8f
+	opAddItems        = 0x90
+	opFrozenSet       = 0x91
+	opNewObjEx        = 0x92
+	opStackGlobal     = 0x93
+	opMemoize         = 0x94
+	opFrame           = 0x95
+
+	// Protocol 5
+	opByteArray8     = 0x96
+	opNextBuffer     = 0x97
+	opReadOnlyBuffer = 0x98
+)
+
+// Python containers while they are decoded, mutable and with identity so
+// memo can share them. They are converted to listdict types at the end.
+type (
+	pyList      struct{ items []interface{} }
+	pyTuple     struct{ items []interface{} }
+	pyDict      struct{ keys, values []interface{} }
+	pySet       struct{ items []interface{} }
+	pyFrozenSet struct{ items []interface{} }
+	pyGlobal    struct {
+		module, name string
+		fn           Global
+	}
+	// pyObject is a call of a global or a persistent ID. It's made when
+	// converted, so its arguments and states are complete even if their
+	// containers get items after the call opcode.
+	pyObject struct {
+		pos    int           // position of the opcode creating it, for errors
+		global *pyGlobal     // nil for persistent ID
+		args   interface{}   // *pyTuple of arguments or persistent ID
+		states []interface{} // states of BUILD opcodes
+		items  []interface{} // items appended to it
+		pairs  []interface{} // key-value pairs set in it
+		busy   bool          // its arguments are being converted
+	}
+)
+
+// unpickler holds state of a single Decode.
+type unpickler struct {
+	*Decoder
+	opPos     int // position of current opcode, for errors
+	depth     int // number of values being converted, nested in each other
+	stack     []interface{}
+	marks     []int // stack lengths at MARK opcodes
+	memo      map[int]interface{}
+	converted map[interface{}]interface{} // py* values converted so far
+	objects   []*pyObject                 // all objects, in creation order
+}
+
+func (u *unpickler) error(msg string) error {
+	return &UnpicklingError{Pos: u.opPos, Msg: msg, Err: ErrInvalidPickle}
+}
+
+func (u *unpickler) push(value interface{}) {
+	u.stack = append(u.stack, value)
+}
+
+func (u *unpickler) pop() (interface{}, error) {
+	if len(u.stack) == 0 || (len(u.marks) > 0 &&
+		len(u.stack) == u.marks[len(u.marks)-1]) {
+		return nil, u.error("stack underflow")
+	}
+	value := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	return value, nil
+}
+
+func (u *unpickler) top() (interface{}, error) {
+	value, err := u.pop()
+	if err == nil {
+		u.push(value)
+	}
+	return value, err
+}
+
+// popMark returns values pushed after the last MARK and removes them.
+func (u *unpickler) popMark() ([]interface{}, error) {
+	if len(u.marks) == 0 {
+		return nil, u.error("could not find MARK")
+	}
+	mark := u.marks[len(u.marks)-1]
+	u.marks = u.marks[:len(u.marks)-1]
+	items := append([]interface{}{}, u.stack[mark:]...)
+	u.stack = u.stack[:mark]
+	return items, nil
+}
+
+//=============================================================================
+
+// run executes a single opcode.
+func (u *unpickler) run(op byte) error {
+	switch op {
+	case opProto:
+		n, err := u.readByte()
+		if err != nil {
+			return err
+		}
+		if n > HighestProtocol {
+			return &UnpicklingError{Pos: u.opPos, Err: ErrUnsupportedProtocol,
+				Msg: fmt.Sprintf("protocol %d", n)}
+		}
+	case opFrame:
+		// Frames only group opcodes for reading, they can be ignored
+		_, err := u.read(8)
+		return err
+	case opMark:
+		u.marks = append(u.marks, len(u.stack))
+	case opPop:
+		if len(u.stack) > 0 && (len(u.marks) == 0 ||
+			u.marks[len(u.marks)-1] < len(u.stack)) {
+			u.stack = u.stack[:len(u.stack)-1]
+			return nil
+		}
+		// POP at MARK removes the mark, same as Python
+		_, err := u.popMark()
+		return err
+	case opPopMark:
+		_, err := u.popMark()
+		return err
+	case opDup:
+		value, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.push(value)
+
+	case opNone:
+		u.push(nil)
+	case opNewTrue:
+		u.push(true)
+	case opNewFalse:
+		u.push(false)
+	case opInt:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		// Protocol 0 writes bools as I01 and I00
+		switch line {
+		case "01":
+			u.push(true)
+		case "00":
+			u.push(false)
+		default:
+			return u.pushInt(line)
+		}
+	case opLong:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		return u.pushInt(strings.TrimSuffix(line, "L"))
+	case opBinInt1, opBinInt2, opBinInt:
+		size := map[byte]int{opBinInt1: 1, opBinInt2: 2, opBinInt: 4}[op]
+		data, err := u.read(size)
+		if err != nil {
+			return err
+		}
+		var buf [4]byte
+		copy(buf[:], data)
+		n := int(binary.LittleEndian.Uint32(buf[:]))
+		if op == opBinInt {
+			n = int(int32(n))
+		}
+		u.push(n)
+	case opLong1, opLong4:
+		size, err := u.readSize(map[byte]int{opLong1: 1, opLong4: 4}[op])
+		if err != nil {
+			return err
+		}
+		data, err := u.read(size)
+		if err != nil {
+			return err
+		}
+		u.push(decodeLong(data))
+	case opFloat:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(line, 64)
+		if err != nil && !isRangeError(err) {
+			return u.error(fmt.Sprintf("invalid float %q", line))
+		}
+		u.push(f)
+	case opBinFloat:
+		data, err := u.read(8)
+		if err != nil {
+			return err
+		}
+		u.push(math.Float64frombits(binary.BigEndian.Uint64(data)))
+
+	case opString:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		// Python 2 str written as its repr, decoded to its raw bytes
+		s, ok := "", false
+		if len(line) >= 2 && line[0] == line[len(line)-1] &&
+			(line[0] == '\'' || line[0] == '"') {
+			s, ok = decodeStringEscape(line[1 : len(line)-1])
+		}
+		if !ok {
+			return u.error(fmt.Sprintf("invalid string %q", line))
+		}
+		u.push(s)
+	case opUnicode:
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		s, ok := decodeRawUnicodeEscape(line)
+		if !ok {
+			return u.error(fmt.Sprintf("invalid unicode %q", line))
+		}
+		u.push(s)
+	case opShortBinString, opBinString, opShortBinUnicode, opBinUnicode,
+		opBinUnicode8:
+		data, err := u.readSized(map[byte]int{opShortBinString: 1,
+			opBinString: 4, opShortBinUnicode: 1, opBinUnicode: 4,
+			opBinUnicode8: 8}[op])
+		if err != nil {
+			return err
+		}
+		u.push(string(data))
+	case opShortBinBytes, opBinBytes, opBinBytes8, opByteArray8:
+		data, err := u.readSized(map[byte]int{opShortBinBytes: 1,
+			opBinBytes: 4, opBinBytes8: 8, opByteArray8: 8}[op])
+		if err != nil {
+			return err
+		}
+		u.push(data)
+	case opNextBuffer, opReadOnlyBuffer:
+		return u.error("out-of-band buffers are not supported")
+
+	case opEmptyList:
+		u.push(&pyList{})
+	case opList:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&pyList{items: items})
+	case opAppend, opAppends:
+		var items []interface{}
+		var err error
+		if op == opAppends {
+			items, err = u.popMark()
+		} else {
+			var item interface{}
+			item, err = u.pop()
+			items = []interface{}{item}
+		}
+		if err != nil {
+			return err
+		}
+		return u.appendItems(items)
+	case opEmptyTuple:
+		u.push(&pyTuple{items: []interface{}{}})
+	case opTuple:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&pyTuple{items: items})
+	case opTuple1, opTuple2, opTuple3:
+		n := int(op-opTuple1) + 1
+		items := make([]interface{}, n)
+		for i := n - 1; i >= 0; i-- {
+			item, err := u.pop()
+			if err != nil {
+				return err
+			}
+			items[i] = item
+		}
+		u.push(&pyTuple{items: items})
+	case opEmptyDict:
+		u.push(&pyDict{})
+	case opDict:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		dict := &pyDict{}
+		u.push(dict)
+		return u.setItems(items)
+	case opSetItem, opSetItems:
+		var items []interface{}
+		var err error
+		if op == opSetItems {
+			items, err = u.popMark()
+		} else {
+			var value, key interface{}
+			if value, err = u.pop(); err == nil {
+				key, err = u.pop()
+			}
+			items = []interface{}{key, value}
+		}
+		if err != nil {
+			return err
+		}
+		return u.setItems(items)
+	case opEmptySet:
+		u.push(&pySet{})
+	case opAddItems:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		value, err := u.top()
+		if err != nil {
+			return err
+		}
+		set, ok := value.(*pySet)
+		if !ok {
+			return u.error("ADDITEMS to non-set")
+		}
+		set.items = append(set.items, items...)
+	case opFrozenSet:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		u.push(&pyFrozenSet{items: items})
+
+	case opGet, opBinGet, opLongBinGet, opPut, opBinPut, opLongBinPut:
+		index, err := u.readMemoIndex(op)
+		if err != nil {
+			return err
+		}
+		if op == opGet || op == opBinGet || op == opLongBinGet {
+			value, ok := u.memo[index]
+			if !ok {
+				return u.error(fmt.Sprintf("memo key %d not found", index))
+			}
+			u.push(value)
+			return nil
+		}
+		value, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.memo[index] = value
+	case opMemoize:
+		value, err := u.top()
+		if err != nil {
+			return err
+		}
+		u.memo[len(u.memo)] = value
+
+	case opGlobal, opInst:
+		module, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		name, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		global, err := u.global(module, name)
+		if err != nil {
+			return err
+		}
+		if op == opGlobal {
+			u.push(global)
+			return nil
+		}
+		args, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		return u.call(global, &pyTuple{items: args}, nil)
+	case opStackGlobal:
+		name, err := u.pop()
+		if err != nil {
+			return err
+		}
+		module, err := u.pop()
+		if err != nil {
+			return err
+		}
+		moduleName, ok1 := module.(string)
+		globalName, ok2 := name.(string)
+		if !ok1 || !ok2 {
+			return u.error("STACK_GLOBAL requires str")
+		}
+		global, err := u.global(moduleName, globalName)
+		if err != nil {
+			return err
+		}
+		u.push(global)
+	case opReduce, opNewObj:
+		args, err := u.pop()
+		if err != nil {
+			return err
+		}
+		callable, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.call(callable, args, nil)
+	case opNewObjEx:
+		kwargs, err := u.pop()
+		if err != nil {
+			return err
+		}
+		args, err := u.pop()
+		if err != nil {
+			return err
+		}
+		callable, err := u.pop()
+		if err != nil {
+			return err
+		}
+		return u.call(callable, args, kwargs)
+	case opObj:
+		items, err := u.popMark()
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return u.error("OBJ without class")
+		}
+		return u.call(items[0], &pyTuple{items: items[1:]}, nil)
+	case opBuild:
+		state, err := u.pop()
+		if err != nil {
+			return err
+		}
+		value, err := u.top()
+		if err != nil {
+			return err
+		}
+		obj, ok := value.(*pyObject)
+		if !ok {
+			return u.error(fmt.Sprintf("BUILD on %T", value))
+		}
+		obj.states = append(obj.states, state)
+	case opExt1, opExt2, opExt4:
+		return &UnpicklingError{Pos: u.opPos, Err: ErrForbiddenGlobal,
+			Msg: "extension registry is not supported"}
+	case opPersID, opBinPersID:
+		var pid interface{}
+		var err error
+		if op == opPersID {
+			pid, err = u.readLine()
+		} else {
+			pid, err = u.pop()
+		}
+		if err != nil {
+			return err
+		}
+		if u.PersistentLoad == nil {
+			return &UnpicklingError{Pos: u.opPos, Err: ErrForbiddenGlobal,
+				Msg: "persistent IDs are not supported"}
+		}
+		u.pushObject(&pyObject{args: pid})
+	default:
+		return u.error(fmt.Sprintf("invalid opcode 0x%02x", op))
+	}
+	return nil
+}
+
+//=============================================================================
+
+// global returns allowed global module.name.
+func (u *unpickler) global(module, name string) (*pyGlobal, error) {
+	fn, ok := u.Registry.Lookup(module, name)
+	if !ok {
+		return nil, &UnpicklingError{Pos: u.opPos, Err: ErrForbiddenGlobal,
+			Msg: fmt.Sprintf("global '%s.%s'", module, name)}
+	}
+	return &pyGlobal{module: module, name: name, fn: fn}, nil
+}
+
+// call pushes object created by calling global with args.
+func (u *unpickler) call(callable, args, kwargs interface{}) error {
+	global, ok := callable.(*pyGlobal)
+	if !ok {
+		return u.error(fmt.Sprintf("call of non-global %T", callable))
+	}
+	if kwargs != nil {
+		if dict, ok := kwargs.(*pyDict); !ok || len(dict.keys) > 0 {
+			return u.error("keyword arguments are not supported")
+		}
+	}
+	if _, ok := args.(*pyTuple); !ok {
+		return u.error("arguments must be a tuple")
+	}
+	u.pushObject(&pyObject{global: global, args: args})
+	return nil
+}
+
+func (u *unpickler) pushObject(obj *pyObject) {
+	obj.pos = u.opPos
+	u.objects = append(u.objects, obj)
+	u.push(obj)
+}
+
+// appendItems appends items to the list (or deque) on top of the stack.
+func (u *unpickler) appendItems(items []interface{}) error {
+	value, err := u.top()
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case *pyList:
+		v.items = append(v.items, items...)
+	case *pyObject:
+		v.items = append(v.items, items...)
+	default:
+		return u.error(fmt.Sprintf("APPEND to %T", value))
+	}
+	return nil
+}
+
+// setItems sets key-value pairs of items in the dict (or OrderedDict) on
+// top of the stack.
+func (u *unpickler) setItems(items []interface{}) error {
+	if len(items)%2 != 0 {
+		return u.error("odd number of items for SETITEMS")
+	}
+	value, err := u.top()
+	if err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case *pyDict:
+		for i := 0; i < len(items); i += 2 {
+			v.keys = append(v.keys, items[i])
+			v.values = append(v.values, items[i+1])
+		}
+	case *pyObject:
+		v.pairs = append(v.pairs, items...)
+	default:
+		return u.error(fmt.Sprintf("SETITEM to %T", value))
+	}
+	return nil
+}
+
+// convert returns value with Python containers converted to listdict
+// types. Shared containers are converted once, so references and cycles
+// are kept. Containers nested deeper than container.MaxDepth return
+// *UnpicklingError.
+func (u *unpickler) convert(value interface{}) (interface{}, error) {
+	if u.depth >= container.MaxDepth {
+		return nil, u.error("maximum nesting depth exceeded")
+	}
+	u.depth++
+	defer func() { u.depth-- }()
+
+	switch value.(type) {
+	case *pyList, *pyTuple, *pyDict, *pySet, *pyFrozenSet, *pyObject:
+		if out, ok := u.converted[value]; ok {
+			return out, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case *pyList:
+		out := make(listdict.List, len(v.items))
+		u.converted[v] = out
+		for i, item := range v.items {
+			converted, err := u.convert(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	case *pyTuple:
+		out := make(listdict.Tuple, len(v.items))
+		u.converted[v] = out
+		for i, item := range v.items {
+			converted, err := u.convert(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = converted
+		}
+		return out, nil
+	case *pyDict:
+		return u.convertDict(v)
+	case *pySet, *pyFrozenSet:
+		var items []interface{}
+		if set, ok := v.(*pySet); ok {
+			items = set.items
+		} else {
+			items = v.(*pyFrozenSet).items
+		}
+		list, err := u.convert(&pyList{items: items})
+		if err != nil {
+			return nil, err
+		}
+		var out interface{}
+		if _, ok := v.(*pySet); ok {
+			out, err = listdict.SetFromList(list.(listdict.List))
+		} else {
+			out, err = listdict.FrozenSetFromList(list.(listdict.List))
+		}
+		if err != nil {
+			return nil, &UnpicklingError{Pos: u.opPos, Msg: "set", Err: err}
+		}
+		u.converted[v] = out
+		return out, nil
+	case *pyObject:
+		return u.convertObject(v)
+	case *pyGlobal:
+		return nil, u.error(fmt.Sprintf("global '%s.%s' used as value",
+			v.module, v.name))
+	}
+	return value, nil
+}
+
+// convertObject calls global of the object, or PersistentLoad, with
+// converted arguments, then sets its states and adds its items.
+func (u *unpickler) convertObject(obj *pyObject) (interface{}, error) {
+	fail := func(msg string, err error) error {
+		return &UnpicklingError{Pos: obj.pos, Msg: msg, Err: err}
+	}
+	if obj.busy {
+		return nil, fail("object is in its own arguments", ErrInvalidPickle)
+	}
+	obj.busy = true
+	args, err := u.convert(obj.args)
+	obj.busy = false
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	if obj.global == nil {
+		if out, err = u.PersistentLoad(args); err != nil {
+			return nil, fail("persistent_load", err)
+		}
+	} else if out, err = obj.global.fn(args.(listdict.Tuple)); err != nil {
+		return nil, fail(fmt.Sprintf("%s.%s", obj.global.module,
+			obj.global.name), err)
+	}
+	// Set before states and items, so they can hold the object
+	u.converted[obj] = out
+
+	for _, state := range obj.states {
+		setter, ok := out.(StateSetter)
+		if !ok {
+			return nil, fail(fmt.Sprintf("BUILD on %T", out), ErrInvalidPickle)
+		}
+		converted, err := u.convert(state)
+		if err != nil {
+			return nil, err
+		}
+		if err := setter.SetState(converted); err != nil {
+			return nil, fail("BUILD", err)
+		}
+	}
+	if len(obj.items) > 0 {
+		deque, ok := out.(*listdict.Deque)
+		if !ok {
+			return nil, fail(fmt.Sprintf("APPEND to %T", out),
+				ErrInvalidPickle)
+		}
+		for _, item := range obj.items {
+			converted, err := u.convert(item)
+			if err != nil {
+				return nil, err
+			}
+			deque.Append(converted)
+		}
+	}
+	if len(obj.pairs) > 0 {
+		dict, ok := out.(*listdict.OrderedDict)
+		if !ok {
+			return nil, fail(fmt.Sprintf("SETITEM to %T", out),
+				ErrInvalidPickle)
+		}
+		for i := 0; i < len(obj.pairs); i += 2 {
+			key, ok := obj.pairs[i].(string)
+			if !ok {
+				return nil, fail("OrderedDict key must be str",
+					ErrInvalidPickle)
+			}
+			converted, err := u.convert(obj.pairs[i+1])
+			if err != nil {
+				return nil, err
+			}
+			dict.Set(key, converted)
+		}
+	}
+	return out, nil
+}
+
+func (u *unpickler) convertDict(dict *pyDict) (interface{}, error) {
+	stringKeys := true
+	for _, key := range dict.keys {
+		if _, ok := key.(string); !ok {
+			stringKeys = false
+			break
+		}
+	}
+
+	if stringKeys {
+		out := make(listdict.Dict, len(dict.keys))
+		u.converted[dict] = out
+		for i, key := range dict.keys {
+			value, err := u.convert(dict.values[i])
+			if err != nil {
+				return nil, err
+			}
+			out[key.(string)] = value
+		}
+		return out, nil
+	}
+
+	out := listdict.NewHashDict()
+	u.converted[dict] = out
+	for i, key := range dict.keys {
+		convertedKey, err := u.convert(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := u.convert(dict.values[i])
+		if err != nil {
+			return nil, err
+		}
+		if err := out.Set(convertedKey, value); err != nil {
+			return nil, &UnpicklingError{Pos: u.opPos, Msg: "dict", Err: err}
+		}
+	}
+	return out, nil
+}
+
+//=============================================================================
+
+func (u *unpickler) readByte() (byte, error) {
+	c, err := u.r.ReadByte()
+	if err == nil {
+		u.pos++
+	}
+	return c, err
+}
+
+// read returns the next n bytes. Memory grows with data actually read, so
+// a wrong size in corrupted data can't allocate too much.
+func (u *unpickler) read(n int) ([]byte, error) {
+	var buf bytes.Buffer
+	copied, err := io.CopyN(&buf, u.r, int64(n))
+	u.pos += int(copied)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readSize returns little-endian unsigned size of given number of bytes.
+func (u *unpickler) readSize(size int) (int, error) {
+	data, err := u.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var buf [8]byte
+	copy(buf[:], data)
+	n := binary.LittleEndian.Uint64(buf[:])
+	if n > math.MaxInt32 && strconv.IntSize == 32 || n > math.MaxInt64 {
+		return 0, u.error("size too large")
+	}
+	return int(n), nil
+}
+
+// readSized returns data preceded by its size of given number of bytes.
+func (u *unpickler) readSized(size int) ([]byte, error) {
+	n, err := u.readSize(size)
+	if err != nil {
+		return nil, err
+	}
+	return u.read(n)
+}
+
+// readLine returns text up to "\n", without it.
+func (u *unpickler) readLine() (string, error) {
+	line, err := u.r.ReadString('\n')
+	u.pos += len(line)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line[:len(line)-1], "\r"), nil
+}
+
+func (u *unpickler) readMemoIndex(op byte) (int, error) {
+	switch op {
+	case opGet, opPut:
+		line, err := u.readLine()
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return 0, u.error(fmt.Sprintf("invalid memo key %q", line))
+		}
+		return n, nil
+	case opBinGet, opBinPut:
+		n, err := u.readByte()
+		return int(n), err
+	}
+	return u.readSize(4)
+}
+
+func (u *unpickler) pushInt(text string) error {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(text), 10)
+	if !ok {
+		return u.error(fmt.Sprintf("invalid int %q", text))
+	}
+	u.push(bigToInt(n))
+	return nil
+}
+
+// decodeLong returns little-endian two's complement number, as int if it
+// fits.
+func decodeLong(data []byte) interface{} {
+	reversed := make([]byte, len(data))
+	for i, c := range data {
+		reversed[len(data)-1-i] = c
+	}
+	n := new(big.Int).SetBytes(reversed)
+	if len(data) > 0 && data[len(data)-1]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+	}
+	return bigToInt(n)
+}
+
+// bigToInt returns n as int if it fits.
+func bigToInt(n *big.Int) interface{} {
+	if n.IsInt64() && int64(int(n.Int64())) == n.Int64() {
+		return int(n.Int64())
+	}
+	return n
+}
+
+// decodeStringEscape decodes escapes of Python 2 str repr to raw bytes,
+// same as Python's codecs.escape_decode. Unknown escapes are kept.
+func decodeStringEscape(s string) (string, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", false
+		}
+		switch c := s[i]; c {
+		case '\n':
+		case '\\', '\'', '"':
+			sb.WriteByte(c)
+		case 'a':
+			sb.WriteByte('\a')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'x':
+			if i+3 > len(s) {
+				return "", false
+			}
+			n, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", false
+			}
+			sb.WriteByte(byte(n))
+			i += 2
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := 0
+			for end := min(i+3, len(s)); i < end && '0' <= s[i] &&
+				s[i] <= '7'; i++ {
+				n = n*8 + int(s[i]-'0')
+			}
+			i--
+			sb.WriteByte(byte(n))
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), true
+}
+
+// decodeRawUnicodeEscape decodes Python's raw-unicode-escape: bytes are
+// Latin-1 characters, only \uXXXX and \UXXXXXXXX are escapes.
+func decodeRawUnicodeEscape(s string) (string, bool) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') {
+			size := 4
+			if s[i+1] == 'U' {
+				size = 8
+			}
+			if i+2+size > len(s) {
+				return "", false
+			}
+			n, err := strconv.ParseUint(s[i+2:i+2+size], 16, 32)
+			if err != nil || n > utf8.MaxRune {
+				return "", false
+			}
+			sb.WriteRune(rune(n))
+			i += 1 + size
+			continue
+		}
+		sb.WriteRune(rune(s[i]))
+	}
+	return sb.String(), true
+}
+
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+	return ok && numErr.Err == strconv.ErrRange
+}
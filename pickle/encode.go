@@ -0,0 +1,763 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package pickle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+const (
+	batchSize       = 1000     // items in APPENDS, SETITEMS and ADDITEMS
+	frameSizeMin    = 4        // smaller frames are written without FRAME
+	frameSizeTarget = 64 << 10 // frames are committed above this size
+)
+
+// Encoder writes pickles to a stream, same as Python's pickle.Pickler.
+type Encoder struct {
+	w        io.Writer
+	protocol int
+}
+
+// NewEncoder returns new Encoder writing to w with given protocol.
+// Negative protocol means HighestProtocol, same as in Python.
+func NewEncoder(w io.Writer, protocol int) *Encoder {
+	if protocol < 0 {
+		protocol = HighestProtocol
+	}
+	return &Encoder{w: w, protocol: protocol}
+}
+
+// Dumps returns value as pickle with given protocol, same as Python's
+// pickle.dumps. For data made of the types Python pickles without
+// classes of its own (None, bool, int, float, str, bytes, list, tuple, dict,
+// set and frozenset) the output is the same as Python's.
+func Dumps(value interface{}, protocol int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, protocol).Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes value as a single pickle. Python types are written for:
+//
+//	nil, bool                  None, bool
+//	ints, *big.Int             int
+//	floats, complex numbers    float, complex
+//	string, []byte             str, bytes
+//	listdict.List, slices      list
+//	listdict.Tuple, arrays     tuple
+//	listdict.Dict, string maps dict, with sorted keys
+//	*listdict.HashDict         dict
+//	*listdict.Set, FrozenSet   set, frozenset
+//	*listdict.OrderedDict      collections.OrderedDict
+//	*listdict.Deque            collections.deque
+//	listdict.Counter           collections.Counter
+//
+// Shared Lists, Dicts and other containers are written once, so the
+// references (and cycles) are kept. Other values return *listdict.TypeError
+// wrapping ErrUnpicklable. Tuple containing itself through tuples only
+// returns *listdict.ValueError wrapping ErrRecursiveTuple.
+func (e *Encoder) Encode(value interface{}) error {
+	if e.protocol > HighestProtocol {
+		return &listdict.ValueError{Value: e.protocol,
+			Err: ErrUnsupportedProtocol}
+	}
+	p := &pickler{protocol: e.protocol, out: e.w,
+		memo: make(map[interface{}]int), tuples: make(map[interface{}]int)}
+	if p.protocol >= 2 {
+		p.buf.Write([]byte{opProto, byte(p.protocol)})
+	}
+	if p.protocol >= 4 {
+		// PROTO is not framed
+		if err := p.flush(); err != nil {
+			return err
+		}
+		p.framing = true
+	}
+	if err := p.save(value); err != nil {
+		return err
+	}
+	p.buf.WriteByte(opStop)
+	return p.commitFrame(true)
+}
+
+//=============================================================================
+
+// pickler holds state of a single Encode.
+type pickler struct {
+	protocol int
+	out      io.Writer
+	buf      bytes.Buffer // current frame
+	framing  bool
+	memo     map[interface{}]int // memo index by identity or global name
+	memoLen  int                 // number of memoized values
+	// memoLen when saving of a tuple started, by memo key of tuples
+	// being saved
+	tuples map[interface{}]int
+}
+
+// globalKey identifies a global in memo.
+type globalKey struct {
+	module, name string
+}
+
+func (p *pickler) save(value interface{}) error {
+	if err := p.commitFrame(false); err != nil {
+		return err
+	}
+	if index, ok := p.lookup(value); ok {
+		p.get(index)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case nil:
+		p.buf.WriteByte(opNone)
+		return nil
+	case bool:
+		p.saveBool(v)
+		return nil
+	case string:
+		return p.saveString(v)
+	case []byte:
+		return p.saveBytes(v)
+	case *big.Int:
+		if v == nil {
+			p.buf.WriteByte(opNone)
+			return nil
+		}
+		p.saveInt(v)
+		return nil
+	case complex64:
+		return p.saveComplex(complex128(v))
+	case complex128:
+		return p.saveComplex(v)
+	case listdict.List:
+		return p.saveList(v, v)
+	case listdict.Tuple:
+		return p.saveTuple(v, v)
+	case listdict.Dict:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]interface{}, 0, 2*len(v))
+		for _, key := range keys {
+			items = append(items, key, v[key])
+		}
+		return p.saveDict(v, items)
+	case listdict.DefaultDict:
+		return p.save(v.Dict)
+	case *listdict.HashDict:
+		items := make([]interface{}, 0, 2*v.Len())
+		for key, val := range v.All() {
+			items = append(items, key, val)
+		}
+		return p.saveDict(v, items)
+	case *listdict.Set:
+		return p.saveSet(v, v.ToList(), false)
+	case listdict.FrozenSet:
+		return p.saveSet(nil, v.ToList(), true)
+	case *listdict.OrderedDict:
+		return p.saveOrderedDict(v)
+	case *listdict.Deque:
+		return p.saveDeque(v)
+	case listdict.Counter:
+		return p.saveCounter(v)
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		p.saveBool(val.Bool())
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		p.saveInt(big.NewInt(val.Int()))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		p.saveInt(new(big.Int).SetUint64(val.Uint()))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		p.saveFloat(val.Float())
+		return nil
+	case reflect.String:
+		return p.saveString(val.String())
+	case reflect.Slice:
+		if val.IsNil() {
+			p.buf.WriteByte(opNone)
+			return nil
+		}
+		return p.saveList(value, reflectItems(val))
+	case reflect.Array:
+		return p.saveTuple(nil, reflectItems(val))
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			break
+		}
+		keys := val.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		items := make([]interface{}, 0, 2*len(keys))
+		for _, key := range keys {
+			items = append(items, key.Interface(),
+				val.MapIndex(key).Interface())
+		}
+		return p.saveDict(value, items)
+	}
+	return &listdict.TypeError{Values: []interface{}{value},
+		Err: ErrUnpicklable}
+}
+
+//=============================================================================
+
+func (p *pickler) saveBool(b bool) {
+	switch {
+	case p.protocol >= 2 && b:
+		p.buf.WriteByte(opNewTrue)
+	case p.protocol >= 2:
+		p.buf.WriteByte(opNewFalse)
+	case b:
+		p.buf.WriteString("I01\n")
+	default:
+		p.buf.WriteString("I00\n")
+	}
+}
+
+func (p *pickler) saveInt(n *big.Int) {
+	if n.IsInt64() && n.Int64() >= math.MinInt32 && n.Int64() <= math.MaxInt32 {
+		i := n.Int64()
+		switch {
+		case p.protocol == 0:
+			fmt.Fprintf(&p.buf, "I%d\n", i)
+		case i >= 0 && i <= 0xff:
+			p.buf.Write([]byte{opBinInt1, byte(i)})
+		case i >= 0 && i <= 0xffff:
+			p.buf.WriteByte(opBinInt2)
+			p.buf.Write(binary.LittleEndian.AppendUint16(nil, uint16(i)))
+		default:
+			p.buf.WriteByte(opBinInt)
+			p.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(i)))
+		}
+		return
+	}
+	if p.protocol < 2 {
+		fmt.Fprintf(&p.buf, "L%sL\n", n.String())
+		return
+	}
+	data := encodeLong(n)
+	if len(data) < 256 {
+		p.buf.Write([]byte{opLong1, byte(len(data))})
+	} else {
+		p.buf.WriteByte(opLong4)
+		p.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+	}
+	p.buf.Write(data)
+}
+
+func (p *pickler) saveFloat(f float64) {
+	if p.protocol == 0 {
+		fmt.Fprintf(&p.buf, "F%s\n", listdict.Repr(f))
+		return
+	}
+	p.buf.WriteByte(opBinFloat)
+	p.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (p *pickler) saveComplex(c complex128) error {
+	// complex(real, imag), same as Python's __reduce__
+	if err := p.saveGlobal("builtins", "complex"); err != nil {
+		return err
+	}
+	if err := p.saveTuple(nil, []interface{}{real(c), imag(c)}); err != nil {
+		return err
+	}
+	p.buf.WriteByte(opReduce)
+	p.memoize(nil)
+	return nil
+}
+
+// saveString writes str, equal strings are written once.
+func (p *pickler) saveString(s string) error {
+	switch {
+	case p.protocol == 0:
+		p.buf.WriteByte(opUnicode)
+		p.buf.WriteString(encodeRawUnicodeEscape(s))
+		p.buf.WriteByte('\n')
+	case len(s) < 256 && p.protocol >= 4:
+		p.buf.Write([]byte{opShortBinUnicode, byte(len(s))})
+		p.buf.WriteString(s)
+	case len(s) > math.MaxUint32 && p.protocol >= 4:
+		header := binary.LittleEndian.AppendUint64([]byte{opBinUnicode8},
+			uint64(len(s)))
+		if err := p.writeLarge(header, []byte(s)); err != nil {
+			return err
+		}
+	default:
+		header := binary.LittleEndian.AppendUint32([]byte{opBinUnicode},
+			uint32(len(s)))
+		if err := p.writeLarge(header, []byte(s)); err != nil {
+			return err
+		}
+	}
+	p.memoize(s)
+	return nil
+}
+
+func (p *pickler) saveBytes(b []byte) error {
+	if p.protocol < 3 {
+		// Python 2 has no bytes, Python 3 writes them as
+		// _codecs.encode(latin1 str, 'latin1')
+		if len(b) == 0 {
+			if err := p.saveGlobal("builtins", "bytes"); err != nil {
+				return err
+			}
+			if err := p.saveTuple(nil, nil); err != nil {
+				return err
+			}
+		} else {
+			if err := p.saveGlobal("_codecs", "encode"); err != nil {
+				return err
+			}
+			latin1 := make([]rune, len(b))
+			for i, c := range b {
+				latin1[i] = rune(c)
+			}
+			err := p.saveTuple(nil, []interface{}{string(latin1), "latin1"})
+			if err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte(opReduce)
+		p.memoize(b)
+		return nil
+	}
+
+	var header []byte
+	switch {
+	case len(b) < 256:
+		header = []byte{opShortBinBytes, byte(len(b))}
+	case len(b) > math.MaxUint32 && p.protocol >= 4:
+		header = binary.LittleEndian.AppendUint64([]byte{opBinBytes8},
+			uint64(len(b)))
+	default:
+		header = binary.LittleEndian.AppendUint32([]byte{opBinBytes},
+			uint32(len(b)))
+	}
+	if err := p.writeLarge(header, b); err != nil {
+		return err
+	}
+	p.memoize(b)
+	return nil
+}
+
+// saveTuple writes tuple of items, value is used as its memo key.
+// Tuple saved again by its items, through a list or dict, is taken from
+// memo, same as in Python.
+func (p *pickler) saveTuple(value interface{}, items []interface{}) error {
+	if len(items) == 0 {
+		if p.protocol >= 1 {
+			p.buf.WriteByte(opEmptyTuple)
+		} else {
+			p.buf.Write([]byte{opMark, opTuple})
+		}
+		return nil
+	}
+
+	if key, ok := memoKeyOf(value); ok {
+		start, saving := p.tuples[key]
+		if saving && start == p.memoLen {
+			// Nothing memoized since, so it would never end
+			return &listdict.ValueError{Err: ErrRecursiveTuple}
+		}
+		p.tuples[key] = p.memoLen
+		defer func() {
+			if saving {
+				p.tuples[key] = start
+			} else {
+				delete(p.tuples, key)
+			}
+		}()
+	}
+
+	mark := len(items) > 3 || p.protocol < 2
+	if mark {
+		p.buf.WriteByte(opMark)
+	}
+	for _, item := range items {
+		if err := p.save(item); err != nil {
+			return err
+		}
+	}
+	if index, ok := p.lookup(value); ok {
+		// Drop the items and use the tuple they saved
+		switch {
+		case !mark:
+			p.buf.Write(bytes.Repeat([]byte{opPop}, len(items)))
+		case p.protocol >= 1:
+			p.buf.WriteByte(opPopMark)
+		default:
+			p.buf.Write(bytes.Repeat([]byte{opPop}, len(items)+1))
+		}
+		p.get(index)
+		return nil
+	}
+	if mark {
+		p.buf.WriteByte(opTuple)
+	} else {
+		p.buf.WriteByte(opTuple1 + byte(len(items)-1))
+	}
+	p.memoize(value)
+	return nil
+}
+
+// saveList writes list value with items.
+func (p *pickler) saveList(value interface{}, items []interface{}) error {
+	if p.protocol >= 1 {
+		p.buf.WriteByte(opEmptyList)
+	} else {
+		p.buf.Write([]byte{opMark, opList})
+	}
+	p.memoize(value)
+	return p.batch(items, 1, opAppend, opAppends)
+}
+
+// saveDict writes dict value with items as key, value, key, value...
+func (p *pickler) saveDict(value interface{}, items []interface{}) error {
+	if p.protocol >= 1 {
+		p.buf.WriteByte(opEmptyDict)
+	} else {
+		p.buf.Write([]byte{opMark, opDict})
+	}
+	p.memoize(value)
+	return p.batch(items, 2, opSetItem, opSetItems)
+}
+
+// batch writes items added to container on top of the stack, with
+// single opcode for each item in protocol 0 and in batches otherwise.
+func (p *pickler) batch(items []interface{}, itemSize int, single,
+	many byte) error {
+
+	if len(items) == itemSize || (p.protocol == 0 && len(items) > 0) {
+		for i := 0; i < len(items); i += itemSize {
+			for _, item := range items[i : i+itemSize] {
+				if err := p.save(item); err != nil {
+					return err
+				}
+			}
+			p.buf.WriteByte(single)
+		}
+		return nil
+	}
+	for start := 0; start < len(items); start += batchSize * itemSize {
+		end := min(start+batchSize*itemSize, len(items))
+		p.buf.WriteByte(opMark)
+		for _, item := range items[start:end] {
+			if err := p.save(item); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte(many)
+	}
+	return nil
+}
+
+// saveSet writes set (or frozenset if frozen), value is used as its memo
+// key.
+func (p *pickler) saveSet(value interface{}, items listdict.List,
+	frozen bool) error {
+
+	if p.protocol < 4 {
+		// set(list) or frozenset(list)
+		name := "set"
+		if frozen {
+			name = "frozenset"
+		}
+		if err := p.saveGlobal("builtins", name); err != nil {
+			return err
+		}
+		var args []interface{}
+		if !frozen || len(items) > 0 {
+			args = []interface{}{items}
+		}
+		if err := p.saveTuple(nil, args); err != nil {
+			return err
+		}
+		p.buf.WriteByte(opReduce)
+	} else if frozen {
+		p.buf.WriteByte(opMark)
+		for _, item := range items {
+			if err := p.save(item); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte(opFrozenSet)
+	} else {
+		p.buf.WriteByte(opEmptySet)
+		p.memoize(value)
+		for start := 0; start < len(items); start += batchSize {
+			p.buf.WriteByte(opMark)
+			for _, item := range items[start:min(start+batchSize,
+				len(items))] {
+				if err := p.save(item); err != nil {
+					return err
+				}
+			}
+			p.buf.WriteByte(opAddItems)
+		}
+		return nil
+	}
+	p.memoize(value)
+	return nil
+}
+
+func (p *pickler) saveOrderedDict(dict *listdict.OrderedDict) error {
+	// OrderedDict() followed by SETITEMS, same as Python's __reduce__
+	if err := p.saveGlobal("collections", "OrderedDict"); err != nil {
+		return err
+	}
+	if err := p.saveTuple(nil, nil); err != nil {
+		return err
+	}
+	p.buf.WriteByte(opReduce)
+	p.memoize(dict)
+	items := make([]interface{}, 0, 2*dict.Len())
+	for key, val := range dict.All() {
+		items = append(items, key, val)
+	}
+	return p.batch(items, 2, opSetItem, opSetItems)
+}
+
+func (p *pickler) saveDeque(deque *listdict.Deque) error {
+	// deque((), maxlen) followed by APPENDS, same as Python's __reduce__
+	if err := p.saveGlobal("collections", "deque"); err != nil {
+		return err
+	}
+	var args []interface{}
+	if maxLen := deque.MaxLen(); maxLen != listdict.Omit {
+		args = []interface{}{listdict.Tuple{}, maxLen}
+	}
+	if err := p.saveTuple(nil, args); err != nil {
+		return err
+	}
+	p.buf.WriteByte(opReduce)
+	p.memoize(deque)
+	return p.batch(deque.ToList(), 1, opAppend, opAppends)
+}
+
+func (p *pickler) saveCounter(counter listdict.Counter) error {
+	// Counter(dict), same as Python's __reduce__
+	if err := p.saveGlobal("collections", "Counter"); err != nil {
+		return err
+	}
+	err := p.saveTuple(nil, []interface{}{counter.HashDict})
+	if err != nil {
+		return err
+	}
+	p.buf.WriteByte(opReduce)
+	p.memoize(nil)
+	return nil
+}
+
+// saveGlobal writes reference to module.name, Python 3 builtins are
+// written as Python 2 __builtin__ for protocols below 3, same as Python.
+func (p *pickler) saveGlobal(module, name string) error {
+	key := globalKey{module, name}
+	if index, ok := p.memo[key]; ok {
+		p.get(index)
+		return nil
+	}
+	if p.protocol >= 4 {
+		if err := p.saveString(module); err != nil {
+			return err
+		}
+		if err := p.saveString(name); err != nil {
+			return err
+		}
+		p.buf.WriteByte(opStackGlobal)
+	} else {
+		if p.protocol < 3 && module == "builtins" {
+			module = "__builtin__"
+		}
+		fmt.Fprintf(&p.buf, "%c%s\n%s\n", opGlobal, module, name)
+	}
+	p.memoize(key)
+	return nil
+}
+
+//=============================================================================
+
+// lookup returns memo index of value written before.
+func (p *pickler) lookup(value interface{}) (int, bool) {
+	key, ok := memoKeyOf(value)
+	if !ok {
+		return 0, false
+	}
+	index, ok := p.memo[key]
+	return index, ok
+}
+
+// memoize stores value on top of the stack in memo, like Python's
+// Pickler.memoize. Values without identity get memo index too, to write
+// the same output as Python.
+func (p *pickler) memoize(value interface{}) {
+	index := p.memoLen
+	p.memoLen++
+	if key, ok := memoKeyOf(value); ok {
+		p.memo[key] = index
+	}
+	switch {
+	case p.protocol >= 4:
+		p.buf.WriteByte(opMemoize)
+	case p.protocol == 0:
+		fmt.Fprintf(&p.buf, "%c%d\n", opPut, index)
+	case index < 256:
+		p.buf.Write([]byte{opBinPut, byte(index)})
+	default:
+		p.buf.WriteByte(opLongBinPut)
+		p.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(index)))
+	}
+}
+
+// get writes reference to memo index.
+func (p *pickler) get(index int) {
+	switch {
+	case p.protocol == 0:
+		fmt.Fprintf(&p.buf, "%c%d\n", opGet, index)
+	case index < 256:
+		p.buf.Write([]byte{opBinGet, byte(index)})
+	default:
+		p.buf.WriteByte(opLongBinGet)
+		p.buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(index)))
+	}
+}
+
+// memoKeyOf returns memo key of value: strings by value, globals by name
+// and containers by identity.
+func memoKeyOf(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string, globalKey:
+		return v, true
+	}
+	if id, ok := container.Identity(reflect.ValueOf(value)); ok {
+		return id, true
+	}
+	return nil, false
+}
+
+//=============================================================================
+
+// writeLarge writes opcode header and data of str or bytes, large data is
+// written outside of frames.
+func (p *pickler) writeLarge(header, data []byte) error {
+	if !p.framing || len(data) < frameSizeTarget {
+		p.buf.Write(header)
+		p.buf.Write(data)
+		return nil
+	}
+	if err := p.commitFrame(true); err != nil {
+		return err
+	}
+	if _, err := p.out.Write(header); err != nil {
+		return err
+	}
+	_, err := p.out.Write(data)
+	return err
+}
+
+// commitFrame writes current frame if it's large enough or force is set.
+func (p *pickler) commitFrame(force bool) error {
+	if !p.framing {
+		if force {
+			return p.flush()
+		}
+		return nil
+	}
+	if p.buf.Len() == 0 || (!force && p.buf.Len() < frameSizeTarget) {
+		return nil
+	}
+	if p.buf.Len() >= frameSizeMin {
+		header := []byte{opFrame}
+		header = binary.LittleEndian.AppendUint64(header, uint64(p.buf.Len()))
+		if _, err := p.out.Write(header); err != nil {
+			return err
+		}
+	}
+	return p.flush()
+}
+
+func (p *pickler) flush() error {
+	_, err := p.out.Write(p.buf.Bytes())
+	p.buf.Reset()
+	return err
+}
+
+// encodeLong returns n as little-endian two's complement, as short as
+// possible, same as Python's pickle.encode_long.
+func encodeLong(n *big.Int) []byte {
+	if n.Sign() == 0 {
+		return nil
+	}
+	size := n.BitLen()/8 + 1
+	// Two's complement of negative n is 2^(8*size) + n
+	value := new(big.Int).Set(n)
+	if n.Sign() < 0 {
+		value.Add(value, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	data := make([]byte, size)
+	value.FillBytes(data)
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	if n.Sign() < 0 && size > 1 && data[size-1] == 0xff &&
+		data[size-2]&0x80 != 0 {
+		data = data[:size-1]
+	}
+	return data
+}
+
+// encodeRawUnicodeEscape encodes s for UNICODE opcode, same as Python's
+// raw-unicode-escape with characters breaking the line escaped.
+func encodeRawUnicodeEscape(s string) string {
+	var buf []byte
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == 0 || r == '\n' || r == '\r' || r == 0x1a:
+			buf = fmt.Appendf(buf, `\u%04x`, r)
+		case r < 0x100:
+			buf = append(buf, byte(r))
+		case r < 0x10000:
+			buf = fmt.Appendf(buf, `\u%04x`, r)
+		default:
+			buf = fmt.Appendf(buf, `\U%08x`, r)
+		}
+	}
+	return string(buf)
+}
+
+// reflectItems returns elements of slice or array val.
+func reflectItems(val reflect.Value) []interface{} {
+	items := make([]interface{}, val.Len())
+	for i := range items {
+		items[i] = val.Index(i).Interface()
+	}
+	return items
+}
@@ -0,0 +1,288 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package pickle reads and writes Python's pickle format, protocols 0 to 5,
+with listdict types.
+
+	data, _ := pickle.Dumps(listdict.List{"a", 1, listdict.Dict{"k": nil}},
+		pickle.DefaultProtocol)
+	value, _ := pickle.Loads(data)	// listdict.List{"a", 1, listdict.Dict{"k": nil}}
+
+Python values are decoded as:
+
+	None, bool           nil, bool
+	int                  int, or *big.Int if it doesn't fit
+	float, complex       float64, complex128
+	str                  string (Python 2 str too, with its raw bytes)
+	bytes, bytearray     []byte
+	list, tuple          listdict.List, listdict.Tuple
+	dict                 listdict.Dict, or *listdict.HashDict if any key
+	                     is not a string
+	set, frozenset       *listdict.Set, listdict.FrozenSet
+	OrderedDict, deque   *listdict.OrderedDict, *listdict.Deque
+	Counter              listdict.Counter
+
+Pickles can name any Python class or function (a global) and call it,
+which is why Python's pickle is unsafe for untrusted data. Here globals
+are looked up in a Registry and the default one only knows the types
+above, everything else is refused with ErrForbiddenGlobal. Register your
+own globals to decode other classes.
+*/
+package pickle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/gosimple/listdict"
+)
+
+const (
+	// HighestProtocol is the highest protocol supported
+	HighestProtocol = 5
+	// DefaultProtocol is the default protocol of Python 3.8 to 3.13
+	DefaultProtocol = 4
+)
+
+var (
+	// ErrInvalidPickle is returned when user want to decode data that is
+	// not a valid pickle
+	ErrInvalidPickle = errors.New("invalid pickle data")
+	// ErrForbiddenGlobal is returned when user want to decode pickle naming
+	// a class or function that is not in the Registry
+	ErrForbiddenGlobal = errors.New("global is not allowed")
+	// ErrUnsupportedProtocol is returned when user want to use protocol
+	// higher than HighestProtocol
+	ErrUnsupportedProtocol = errors.New("unsupported pickle protocol")
+	// ErrUnpicklable is returned when user want to encode value of a type
+	// that has no pickle version
+	ErrUnpicklable = errors.New("value can't be pickled")
+	// ErrRecursiveTuple is returned when user want to encode Tuple that
+	// contains itself through tuples only, with no list or dict between
+	ErrRecursiveTuple = errors.New("tuple contains itself")
+)
+
+// UnpicklingError is returned by Decoder for data it can't decode, same as
+// Python's pickle.UnpicklingError. It wraps ErrInvalidPickle,
+// ErrForbiddenGlobal, ErrUnsupportedProtocol or error of a Global.
+type UnpicklingError struct {
+	Pos int    // byte offset of the opcode that failed
+	Msg string // what is wrong
+	Err error
+}
+
+func (e *UnpicklingError) Error() string {
+	return fmt.Sprintf("%v: %s (byte %d)", e.Err, e.Msg, e.Pos)
+}
+
+func (e *UnpicklingError) Unwrap() error {
+	return e.Err
+}
+
+//=============================================================================
+
+// Global creates a value of Python class or function named in a pickle,
+// from arguments of the call (REDUCE, NEWOBJ or INST opcode). Arguments are
+// already decoded. Globals are called when the whole pickle is read, so
+// arguments have all items added to them later in the pickle.
+type Global func(args listdict.Tuple) (interface{}, error)
+
+// StateSetter is implemented by values created by a Global that accept
+// state of Python object (BUILD opcode), same as Python's __setstate__.
+type StateSetter interface {
+	SetState(state interface{}) error
+}
+
+// Registry holds globals allowed in pickles, by module and name.
+type Registry struct {
+	globals map[string]Global
+}
+
+// NewRegistry returns new empty Registry, it refuses all globals.
+func NewRegistry() *Registry {
+	return &Registry{globals: make(map[string]Global)}
+}
+
+// DefaultRegistry returns new Registry allowing Python types that have
+// listdict versions: set, frozenset, bytes, bytearray, complex, OrderedDict,
+// deque and Counter. Both Python 3 and Python 2 module names are allowed.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, module := range []string{"builtins", "__builtin__"} {
+		r.Register(module, "set", newSet)
+		r.Register(module, "frozenset", newFrozenSet)
+		r.Register(module, "bytes", newBytes)
+		r.Register(module, "bytearray", newBytes)
+		r.Register(module, "complex", newComplex)
+	}
+	r.Register("_codecs", "encode", encode)
+	r.Register("collections", "OrderedDict", newOrderedDict)
+	r.Register("collections", "deque", newDeque)
+	r.Register("collections", "Counter", newCounter)
+	return r
+}
+
+// Register allows global module.name in pickles, global creates its values.
+func (r *Registry) Register(module, name string, global Global) {
+	r.globals[module+"."+name] = global
+}
+
+// Lookup returns Global registered for module.name.
+func (r *Registry) Lookup(module, name string) (Global, bool) {
+	if r == nil {
+		return nil, false
+	}
+	global, ok := r.globals[module+"."+name]
+	return global, ok
+}
+
+//=============================================================================
+
+// Globals of DefaultRegistry, args are checked as Python does.
+
+func newSet(args listdict.Tuple) (interface{}, error) {
+	items, err := iterableArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return listdict.SetFromList(items)
+}
+
+func newFrozenSet(args listdict.Tuple) (interface{}, error) {
+	items, err := iterableArg(args)
+	if err != nil {
+		return nil, err
+	}
+	return listdict.FrozenSetFromList(items)
+}
+
+func newBytes(args listdict.Tuple) (interface{}, error) {
+	switch len(args) {
+	case 0:
+		return []byte{}, nil
+	case 1:
+		if b, ok := args[0].([]byte); ok {
+			return append([]byte{}, b...), nil
+		}
+	case 2:
+		return encode(args)
+	}
+	return nil, argsError(args)
+}
+
+// encode is Python's _codecs.encode, used for bytes by protocols 0 to 2.
+func encode(args listdict.Tuple) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, argsError(args)
+	}
+	s, ok := args[0].(string)
+	if encoding, _ := args[1].(string); !ok || (encoding != "latin1" &&
+		encoding != "latin-1") {
+		return nil, argsError(args)
+	}
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, argsError(args)
+		}
+		out = append(out, byte(r))
+	}
+	return out, nil
+}
+
+func newComplex(args listdict.Tuple) (interface{}, error) {
+	parts := [2]float64{}
+	if len(args) > 2 {
+		return nil, argsError(args)
+	}
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case float64:
+			parts[i] = v
+		case int:
+			parts[i] = float64(v)
+		case *big.Int:
+			parts[i], _ = new(big.Float).SetInt(v).Float64()
+		default:
+			return nil, argsError(args)
+		}
+	}
+	return complex(parts[0], parts[1]), nil
+}
+
+func newOrderedDict(args listdict.Tuple) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, argsError(args)
+	}
+	return listdict.NewOrderedDict(), nil
+}
+
+func newDeque(args listdict.Tuple) (interface{}, error) {
+	items, err := iterableArg(args[:min(len(args), 1)])
+	if err != nil || len(args) > 2 {
+		return nil, argsError(args)
+	}
+	maxLen := listdict.Omit
+	if len(args) == 2 && args[1] != nil {
+		n, ok := args[1].(int)
+		if !ok {
+			return nil, argsError(args)
+		}
+		maxLen = n
+	}
+	return listdict.DequeFromList(items, maxLen), nil
+}
+
+// newCounter returns Counter with counts from dict argument, Dict keys are
+// added in sorted order.
+func newCounter(args listdict.Tuple) (interface{}, error) {
+	counter := listdict.NewCounter()
+	if len(args) == 0 {
+		return counter, nil
+	}
+	if len(args) != 1 {
+		return nil, argsError(args)
+	}
+	var counts *listdict.HashDict
+	switch dict := args[0].(type) {
+	case listdict.Dict:
+		counts = listdict.HashDictFromDict(dict)
+	case *listdict.HashDict:
+		counts = dict
+	default:
+		return nil, argsError(args)
+	}
+	for key, val := range counts.All() {
+		if _, ok := val.(int); !ok {
+			return nil, argsError(args)
+		}
+		counter.Set(key, val)
+	}
+	return counter, nil
+}
+
+// iterableArg returns elements of optional List or Tuple argument.
+func iterableArg(args listdict.Tuple) (listdict.List, error) {
+	switch len(args) {
+	case 0:
+		return listdict.List{}, nil
+	case 1:
+		switch v := args[0].(type) {
+		case listdict.List:
+			return v, nil
+		case listdict.Tuple:
+			return v.ToList(), nil
+		}
+	}
+	return nil, argsError(args)
+}
+
+func argsError(args listdict.Tuple) error {
+	return &listdict.TypeError{Values: []interface{}{args},
+		Err: listdict.ErrWrongType}
+}
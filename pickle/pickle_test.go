@@ -0,0 +1,474 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package pickle
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+//=============================================================================
+
+// Pickles written by Python 3.11 with protocols 0, 2 and 5
+var loadsTests = []struct {
+	data string
+	repr string
+}{
+	{"(lp0\nVa\np1\naI1\na(dp2\nVk\np3\nNsaI01\naF2.5\na.",
+		"['a', 1, {'k': None}, True, 2.5]"},
+	{"\x80\x02]q\x00(X\x01\x00\x00\x00aq\x01K\x01}q\x02X\x01\x00\x00\x00kq" +
+		"\x03Ns\x88G@\x04\x00\x00\x00\x00\x00\x00e.",
+		"['a', 1, {'k': None}, True, 2.5]"},
+	{"\x80\x05\x95\x1d\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x01a\x94K\x01}" +
+		"\x94\x8c\x01k\x94Ns\x88G@\x04\x00\x00\x00\x00\x00\x00e.",
+		"['a', 1, {'k': None}, True, 2.5]"},
+	{"(I1\nV\\u017c\np0\nc_codecs\nencode\np1\n(Vb\xff\np2\nVlatin1\np3\ntp4" +
+		"\nRp5\nL-123456789012345678901234567890L\ntp6\n.",
+		"(1, 'ż', b'b\\xff', -123456789012345678901234567890)"},
+	{"\x80\x02(K\x01X\x02\x00\x00\x00\xc5\xbcq\x00c_codecs\nencode\nq\x01X" +
+		"\x03\x00\x00\x00b\xc3\xbfq\x02X\x06\x00\x00\x00latin1q\x03\x86q\x04Rq" +
+		"\x05\x8a\x0d.\xf5\xc0\xb1\x11\x1f\x8c<\x09\xf0\x16q\xfetq\x06.",
+		"(1, 'ż', b'b\\xff', -123456789012345678901234567890)"},
+	{"\x80\x05\x95\x1f\x00\x00\x00\x00\x00\x00\x00(K\x01\x8c\x02\xc5\xbc\x94C" +
+		"\x02b\xff\x94\x8a\x0d.\xf5\xc0\xb1\x11\x1f\x8c<\x09\xf0\x16q\xfet\x94.",
+		"(1, 'ż', b'b\\xff', -123456789012345678901234567890)"},
+	{"c__builtin__\nset\np0\n((lp1\nI1\natp2\nRp3\n.", "{1}"},
+	{"\x80\x02c__builtin__\nset\nq\x00]q\x01K\x01a\x85q\x02Rq\x03.", "{1}"},
+	{"\x80\x05\x95\x07\x00\x00\x00\x00\x00\x00\x00\x8f\x94(K\x01\x90.", "{1}"},
+	{"c__builtin__\nfrozenset\np0\n((lp1\nVx\np2\natp3\nRp4\n.",
+		"frozenset({'x'})"},
+	{"\x80\x05\x95\x08\x00\x00\x00\x00\x00\x00\x00(\x8c\x01x\x94\x91\x94.",
+		"frozenset({'x'})"},
+	{"(dp0\nI1\nVone\np1\ns(I2\nI3\ntp2\nNs.", "{1: 'one', (2, 3): None}"},
+	{"\x80\x05\x95\x14\x00\x00\x00\x00\x00\x00\x00}\x94(K\x01\x8c\x03one\x94K" +
+		"\x02K\x03\x86\x94Nu.", "{1: 'one', (2, 3): None}"},
+	{"ccollections\nOrderedDict\np0\n(tRp1\nVz\np2\nI1\nsVa\np3\n(lp4\nI2\nas.",
+		"OrderedDict({'z': 1, 'a': [2]})"},
+	{"\x80\x05\x953\x00\x00\x00\x00\x00\x00\x00\x8c\x0bcollections\x94\x8c" +
+		"\x0bOrderedDict\x94\x93\x94)R\x94(\x8c\x01z\x94K\x01\x8c\x01a\x94]\x94K" +
+		"\x02au.", "OrderedDict({'z': 1, 'a': [2]})"},
+	{"ccollections\ndeque\np0\n((tI3\ntp1\nRp2\nI1\naI2\na.",
+		"deque([1, 2], maxlen=3)"},
+	{"\x80\x02ccollections\ndeque\nq\x00)K\x03\x86q\x01Rq\x02(K\x01K\x02e.",
+		"deque([1, 2], maxlen=3)"},
+	{"\x80\x02ccollections\nCounter\nq\x00}q\x01X\x01\x00\x00\x00aq\x02K\x02s" +
+		"\x85q\x03Rq\x04.", "Counter({'a': 2})"},
+	{"ccollections\nCounter\np0\n((dp1\nI1\nI2\nsVa\np2\nI1\nstp3\nRp4\n.",
+		"Counter({1: 2, 'a': 1})"},
+	{"\x80\x02ccollections\nCounter\nq\x00}q\x01(K\x01K\x02X\x01\x00\x00" +
+		"\x00aq\x02K\x01u\x85q\x03Rq\x04.", "Counter({1: 2, 'a': 1})"},
+	{"c__builtin__\ncomplex\np0\n(F1.0\nF-2.0\ntp1\nRp2\n.", "(1-2j)"},
+	{"\x80\x05\x95.\x00\x00\x00\x00\x00\x00\x00\x8c\x08builtins\x94\x8c\x07" +
+		"complex\x94\x93\x94G?\xf0\x00\x00\x00\x00\x00\x00G\xc0\x00\x00\x00\x00" +
+		"\x00\x00\x00\x86\x94R\x94.", "(1-2j)"},
+	{"\x80\x02c__builtin__\nbytearray\nq\x00c_codecs\nencode\nq\x01X\x02\x00" +
+		"\x00\x00abq\x02X\x06\x00\x00\x00latin1q\x03\x86q\x04Rq\x05\x85q\x06Rq" +
+		"\x07.", "b'ab'"},
+	{"\x80\x05\x95\x0d\x00\x00\x00\x00\x00\x00\x00\x96\x02\x00\x00\x00\x00\x00" +
+		"\x00\x00ab\x94.", "b'ab'"},
+	// Hand-written, list appended to after it was added, same result in
+	// Python's pickle.loads
+	{"\x80\x02ccollections\ndeque\n)R]q\x00ah\x00K\x01a0.", "deque([[1]])"},
+	{"\x80\x02ccollections\nOrderedDict\n)RX\x01\x00\x00\x00a]q\x00sh\x00K" +
+		"\x01a0.", "OrderedDict({'a': [1]})"},
+	// Python 2 str
+	{"S'a\\'b'\np0\n.", `"a'b"`},
+	{"U\x02ab.", "'ab'"},
+}
+
+func TestLoads(t *testing.T) {
+	for index, lt := range loadsTests {
+		out, err := Loads([]byte(lt.data))
+		if repr := listdict.Repr(out); repr != lt.repr || err != nil {
+			t.Errorf("%d. Loads() => %s, %v, want %s", index, repr, err, lt.repr)
+		}
+	}
+
+	out, _ := Loads([]byte(loadsTests[1].data))
+	want := listdict.List{"a", 1, listdict.Dict{"k": nil}, true, 2.5}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Loads() => %#v, want %#v", out, want)
+	}
+}
+
+// Python 2 str is decoded to its raw bytes by both STRING and binary
+// opcodes
+var loadsStringTests = []struct {
+	data string
+	out  string
+}{
+	{"S'\\xff'\np0\n.", "\xff"},
+	{"U\x01\xff.", "\xff"},
+	{"S'\\xc5\\xbc\\n\\t\\\\'\np0\n.", "ż\n\t\\"},
+	{"S\"it's\"\np0\n.", "it's"},
+	{"S'\\101\\0\\q'\np0\n.", "A\x00\\q"},
+}
+
+func TestLoadsString(t *testing.T) {
+	for index, lt := range loadsStringTests {
+		if out, err := Loads([]byte(lt.data)); out != lt.out || err != nil {
+			t.Errorf("%d. Loads(%q) => %q, %v, want %q", index, lt.data, out,
+				err, lt.out)
+		}
+	}
+	for index, data := range []string{"S'a\np0\n.", "Sa\np0\n.",
+		"S'\\'\np0\n.", "S'\\x1'\np0\n."} {
+		if _, err := Loads([]byte(data)); !errors.Is(err, ErrInvalidPickle) {
+			t.Errorf("%d. Loads(%q) => %v, want ErrInvalidPickle", index, data,
+				err)
+		}
+	}
+}
+
+var roundTripTests = []interface{}{
+	nil,
+	listdict.List{"a", 1, -1, 300, -70000, 1 << 40, 2.5, true, false, nil},
+	listdict.Dict{"b": listdict.List{}, "a": listdict.Dict{"x": []byte{}}},
+	listdict.Tuple{},
+	listdict.Tuple{"ż", []byte("\x00\xff"), listdict.Tuple{1}},
+	listdict.Tuple{1, 2, 3, 4},
+	complex(0.5, -1),
+	"line\nbreak \\ \x00 \U0001F600",
+	func() *big.Int {
+		n, _ := new(big.Int).SetString("-340282366920938463463374607431768211456",
+			10)
+		return n
+	}(),
+}
+
+func TestRoundTrip(t *testing.T) {
+	for protocol := 0; protocol <= HighestProtocol; protocol++ {
+		for index, value := range roundTripTests {
+			data, err := Dumps(value, protocol)
+			if err != nil {
+				t.Errorf("%d. Dumps(%v, %d) => %v", index, value, protocol, err)
+				continue
+			}
+			out, err := Loads(data)
+			if !reflect.DeepEqual(out, value) || err != nil {
+				t.Errorf("%d. Loads(Dumps(%v, %d)) => %#v, %v",
+					index, value, protocol, out, err)
+			}
+		}
+	}
+}
+
+func TestRoundTripContainers(t *testing.T) {
+	set, _ := listdict.SetFromList(listdict.List{1, "a", listdict.Tuple{2}})
+	frozen, _ := listdict.FrozenSetFromList(listdict.List{"x"})
+	hashDict := listdict.NewHashDict()
+	hashDict.Set(listdict.Tuple{1, 2}, "pair")
+	hashDict.Set(3, nil)
+	ordered := listdict.NewOrderedDict()
+	ordered.Set("z", 1)
+	ordered.Set("a", listdict.List{2})
+	counter, _ := listdict.CounterFromList(listdict.List{"a", 1, "a"})
+	values := []interface{}{set, frozen, hashDict, ordered,
+		listdict.DequeFromList(listdict.List{1, 2}, 3),
+		listdict.DequeFromList(listdict.List{}, listdict.Omit),
+		counter}
+
+	for protocol := 0; protocol <= HighestProtocol; protocol++ {
+		for index, value := range values {
+			data, err := Dumps(value, protocol)
+			if err != nil {
+				t.Errorf("%d. Dumps(%v, %d) => %v", index, value, protocol, err)
+				continue
+			}
+			out, err := Loads(data)
+			if listdict.Repr(out) != listdict.Repr(value) || err != nil {
+				t.Errorf("%d. Loads(Dumps(%s, %d)) => %s, %v", index,
+					listdict.Repr(value), protocol, listdict.Repr(out), err)
+			}
+		}
+	}
+}
+
+func TestSharedAndCycles(t *testing.T) {
+	shared := listdict.List{1}
+	list := listdict.List{shared, shared, nil}
+	list[2] = list
+	for protocol := 0; protocol <= HighestProtocol; protocol++ {
+		data, err := Dumps(list, protocol)
+		if err != nil {
+			t.Fatalf("Dumps(cycle, %d) => %v", protocol, err)
+		}
+		value, err := Loads(data)
+		out, ok := value.(listdict.List)
+		if err != nil || !ok || len(out) != 3 {
+			t.Fatalf("Loads(Dumps(cycle, %d)) => %v, %v", protocol, value, err)
+		}
+		out[0].(listdict.List)[0] = "changed"
+		if out[1].(listdict.List)[0] != "changed" {
+			t.Errorf("%d. shared List is not shared after Loads", protocol)
+		}
+		if inner := out[2].(listdict.List); &inner[0] != &out[0] {
+			t.Errorf("%d. cycle is not kept after Loads", protocol)
+		}
+	}
+}
+
+// Python's pickle.dumps for the same values
+var dumpsTests = []struct {
+	in       interface{}
+	protocol int
+	out      string
+}{
+	{listdict.List{"a", 1, listdict.Dict{"k": nil}}, 0,
+		"(lp0\nVa\np1\naI1\na(dp2\nVk\np3\nNsa."},
+	{listdict.List{"a", 1, listdict.Dict{"k": nil}}, 4,
+		"\x80\x04\x95\x13\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x01a\x94K\x01}" +
+			"\x94\x8c\x01k\x94Nse."},
+	{listdict.Tuple{[]byte("b")}, 2,
+		"\x80\x02c_codecs\nencode\nq\x00X\x01\x00\x00\x00bq\x01X\x06\x00\x00\x00" +
+			"latin1q\x02\x86q\x03Rq\x04\x85q\x05."},
+	{-1 << 40, 1, "L-1099511627776L\n."},
+	{-1 << 40, 3, "\x80\x03\x8a\x06\x00\x00\x00\x00\x00\xff."},
+}
+
+func TestDumps(t *testing.T) {
+	for index, dt := range dumpsTests {
+		if out, err := Dumps(dt.in, dt.protocol); string(out) != dt.out ||
+			err != nil {
+			t.Errorf("%d. Dumps(%v, %d) => %q, %v, want %q",
+				index, dt.in, dt.protocol, out, err, dt.out)
+		}
+	}
+}
+
+func TestDumpsLarge(t *testing.T) {
+	// Large str is written outside of frames, same as Python
+	s := strings.Repeat("y", 70000)
+	data, err := Dumps(listdict.List{s}, 4)
+	if err != nil || !bytes.HasPrefix(data, []byte("\x80\x04]\x94Xp\x11\x01\x00")) {
+		t.Errorf("Dumps(large) => %q..., %v", data[:min(len(data), 12)], err)
+	}
+	if out, err := Loads(data); err != nil || out.(listdict.List)[0] != s {
+		t.Errorf("Loads(Dumps(large)) => %v", err)
+	}
+
+	list := make(listdict.List, 2500)
+	for i := range list {
+		list[i] = i
+	}
+	data, _ = Dumps(list, 5)
+	if out, err := Loads(data); !reflect.DeepEqual(out, list) || err != nil {
+		t.Errorf("Loads(Dumps(2500 ints)) => %v", err)
+	}
+}
+
+func TestDumpsErrors(t *testing.T) {
+	if _, err := Dumps(listdict.List{struct{}{}}, 4); !errors.
+		Is(err, ErrUnpicklable) {
+		t.Errorf("Dumps(struct{}) => %v, want ErrUnpicklable", err)
+	}
+	if _, err := Dumps(nil, 6); !errors.Is(err, ErrUnsupportedProtocol) {
+		t.Errorf("Dumps(protocol 6) => %v, want ErrUnsupportedProtocol", err)
+	}
+	self, outer := listdict.Tuple{nil}, listdict.Tuple{nil}
+	self[0], outer[0] = self, listdict.Tuple{outer}
+	for index, tuple := range []listdict.Tuple{self, outer} {
+		if _, err := Dumps(tuple, 2); !errors.Is(err, ErrRecursiveTuple) {
+			t.Errorf("%d. Dumps(recursive Tuple) => %v, want ErrRecursiveTuple",
+				index, err)
+		}
+	}
+}
+
+func TestRecursiveTuple(t *testing.T) {
+	tuple := listdict.Tuple{listdict.List{nil}}
+	tuple[0].(listdict.List)[0] = tuple
+	// Python's pickle.dumps(t, 2) after t = ([],); t[0].append(t)
+	want := "\x80\x02]q\x00h\x00\x85q\x01a0h\x01."
+	if out, err := Dumps(tuple, 2); string(out) != want || err != nil {
+		t.Errorf("Dumps(recursive Tuple, 2) => %q, %v, want %q", out, err, want)
+	}
+	for protocol := 0; protocol <= HighestProtocol; protocol++ {
+		data, err := Dumps(tuple, protocol)
+		if err != nil {
+			t.Fatalf("Dumps(recursive Tuple, %d) => %v", protocol, err)
+		}
+		value, err := Loads(data)
+		out, ok := value.(listdict.Tuple)
+		if err != nil || !ok || len(out) != 1 {
+			t.Fatalf("Loads(Dumps(recursive Tuple, %d)) => %v", protocol, err)
+		}
+		inner, _ := out[0].(listdict.List)[0].(listdict.Tuple)
+		if len(inner) != 1 || &inner[0] != &out[0] {
+			t.Errorf("%d. cycle is not kept after Loads", protocol)
+		}
+	}
+}
+
+//=============================================================================
+
+var loadsErrorTests = []struct {
+	data string
+	err  error
+}{
+	// pickle.dumps(os.system, 4)
+	{"\x80\x04\x95\x14\x00\x00\x00\x00\x00\x00\x00\x8c\x05posix\x94\x8c\x06" +
+		"system\x94\x93\x94.", ErrForbiddenGlobal},
+	{"cos\nsystem\n(S'echo'\ntR.", ErrForbiddenGlobal},
+	{"\x80\x02c__main__\nC\nq\x00)\x81q\x01.", ErrForbiddenGlobal},
+	{"\x80\x02\x82\x01.", ErrForbiddenGlobal},
+	{"(I1\nP1\n.", ErrForbiddenGlobal},
+	{"\x80\x06N.", ErrUnsupportedProtocol},
+	{"\xffN.", ErrInvalidPickle},
+	{"a.", ErrInvalidPickle},
+	{"]h\x05.", ErrInvalidPickle},
+	{".", ErrInvalidPickle},
+	{"c__builtin__\nset\n.", ErrInvalidPickle},
+	{"N", io.ErrUnexpectedEOF},
+	{"X\xff\xff\xff\x7fabc", io.ErrUnexpectedEOF},
+	{"", io.EOF},
+}
+
+func TestLoadsErrors(t *testing.T) {
+	for index, lt := range loadsErrorTests {
+		if _, err := Loads([]byte(lt.data)); !errors.Is(err, lt.err) {
+			t.Errorf("%d. Loads(%q) => %v, want %v", index, lt.data, err, lt.err)
+		}
+	}
+	// ]*n a*n . nests n lists
+	n := container.MaxDepth + 1
+	deep := "\x80\x02" + strings.Repeat("]", n) + strings.Repeat("a", n-1) + "."
+	var unpicklingErr *UnpicklingError
+	if _, err := Loads([]byte(deep)); !errors.As(err, &unpicklingErr) ||
+		!errors.Is(err, ErrInvalidPickle) {
+		t.Errorf("Loads(deeply nested) => %v, want %v", err, ErrInvalidPickle)
+	}
+	n = container.MaxDepth
+	nested := "\x80\x02" + strings.Repeat("]", n) + strings.Repeat("a", n-1) + "."
+	if _, err := Loads([]byte(nested)); err != nil {
+		t.Errorf("Loads(nested %d times) => %v, want nil", n, err)
+	}
+}
+
+// point is a Go version of Python class, created by NEWOBJ and BUILD.
+type point struct {
+	X, Y interface{}
+}
+
+func (p *point) SetState(state interface{}) error {
+	dict, ok := state.(listdict.Dict)
+	if !ok {
+		return errors.New("state must be dict")
+	}
+	p.X, p.Y = dict["x"], dict["y"]
+	return nil
+}
+
+func TestRegistry(t *testing.T) {
+	// Python's pickle.dumps(Point(1, 2), 2) of a plain class
+	data := []byte("\x80\x02cgeo\nPoint\nq\x00)\x81q\x01}q\x02(X\x01\x00\x00" +
+		"\x00xq\x03K\x01X\x01\x00\x00\x00yq\x04K\x02ub.")
+
+	decoder := NewDecoder(bytes.NewReader(data))
+	decoder.Registry.Register("geo", "Point", func(
+		args listdict.Tuple) (interface{}, error) {
+
+		return &point{}, nil
+	})
+	out, err := decoder.Decode()
+	if p, ok := out.(*point); !ok || err != nil || p.X != 1 || p.Y != 2 {
+		t.Errorf("Decode() => %#v, %v, want &point{1, 2}", out, err)
+	}
+
+	// Even set is refused by an empty Registry
+	decoder = NewDecoder(strings.NewReader(
+		"\x80\x02c__builtin__\nset\n]\x85R."))
+	decoder.Registry = NewRegistry()
+	if _, err := decoder.Decode(); !errors.Is(err, ErrForbiddenGlobal) {
+		t.Errorf("Decode(empty Registry) => %v, want ErrForbiddenGlobal", err)
+	}
+}
+
+// object is a Go version of Python class keeping its state as is.
+type object struct {
+	state interface{}
+}
+
+func (o *object) SetState(state interface{}) error {
+	o.state = state
+	return nil
+}
+
+func TestRegistryLaterItems(t *testing.T) {
+	// Python's pickle.dumps(l, 2) after l = [C()]; l[0].attr = l, list
+	// is in the state before C() is appended to it
+	data := []byte("\x80\x02]q\x00cm\nC\nq\x01)\x81q\x02}q\x03X\x04\x00\x00" +
+		"\x00attrq\x04h\x00sba.")
+
+	decoder := NewDecoder(bytes.NewReader(data))
+	decoder.Registry.Register("m", "C", func(
+		args listdict.Tuple) (interface{}, error) {
+
+		return &object{}, nil
+	})
+	out, err := decoder.Decode()
+	list, _ := out.(listdict.List)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Decode() => %#v, %v, want [C()]", out, err)
+	}
+	obj, ok := list[0].(*object)
+	if !ok {
+		t.Fatalf("Decode()[0] => %#v, want &object{}", list[0])
+	}
+	attr, _ := obj.state.(listdict.Dict)["attr"].(listdict.List)
+	if len(attr) != 1 || &attr[0] != &list[0] {
+		t.Errorf("Decode()[0].attr => %#v, want the decoded list", attr)
+	}
+
+	// Arguments holding the object itself can't be called
+	decoder = NewDecoder(strings.NewReader(
+		"\x80\x02cm\nC\n]q\x00\x85Rq\x010h\x00h\x01a0h\x01."))
+	decoder.Registry.Register("m", "C", func(
+		args listdict.Tuple) (interface{}, error) {
+
+		return &object{}, nil
+	})
+	if _, err := decoder.Decode(); !errors.Is(err, ErrInvalidPickle) {
+		t.Errorf("Decode(object in arguments) => %v, want ErrInvalidPickle",
+			err)
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf, -1)
+	for _, value := range []interface{}{1, "two", listdict.List{3}} {
+		if err := encoder.Encode(value); err != nil {
+			t.Fatalf("Encode(%v) => %v", value, err)
+		}
+	}
+
+	decoder := NewDecoder(&buf)
+	var out listdict.List
+	for {
+		value, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() => %v", err)
+		}
+		out = append(out, value)
+	}
+	if want := (listdict.List{1, "two", listdict.List{3}}); !reflect.
+		DeepEqual(out, want) {
+		t.Errorf("Decode() stream => %v, want %v", out, want)
+	}
+}
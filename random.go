@@ -0,0 +1,163 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Rand is a source of random numbers used by functions choosing random
+// elements. *math/rand.Rand satisfies it, so tests can use a seeded one:
+//
+//	r := rand.New(rand.NewSource(1))
+//	val, _ := list.Choice(r) // always the same element
+//
+// When nil is passed instead of Rand the top-level math/rand functions
+// are used. The package never seeds or changes math/rand global state.
+type Rand interface {
+	// Intn returns a random number in [0, n)
+	Intn(n int) int
+	// Float64 returns a random number in [0.0, 1.0)
+	Float64() float64
+}
+
+// globalRand uses the top-level math/rand functions.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int   { return rand.Intn(n) }
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+func randOrDefault(r Rand) Rand {
+	if r == nil {
+		return globalRand{}
+	}
+	return r
+}
+
+//=============================================================================
+
+// Choice returns a random element of the list, same as Python's
+// random.choice. It returns *IndexError for empty list.
+func (list List) Choice(r Rand) (interface{}, error) {
+	if len(list) == 0 {
+		return nil, &IndexError{Index: 0, Err: ErrEmptySequence}
+	}
+	return list[randOrDefault(r).Intn(len(list))], nil
+}
+
+// Choices returns a list of k elements of the list chosen with replacement,
+// same as Python's random.choices. If weights is nil all elements are
+// equally likely, otherwise weights[i] is the relative weight of list[i].
+// Weights must not be negative and must not sum up to zero.
+//
+//	list.Choices([]float64{10, 1}, 5, r) // mostly list[0]
+func (list List) Choices(weights []float64, k int, r Rand) (List, error) {
+	r = randOrDefault(r)
+	if len(list) == 0 {
+		return nil, &IndexError{Index: 0, Err: ErrEmptySequence}
+	}
+	if k < 0 {
+		k = 0
+	}
+	out := NewList(k)
+
+	if weights == nil {
+		for i := range out {
+			out[i] = list[r.Intn(len(list))]
+		}
+		return out, nil
+	}
+
+	if len(weights) != len(list) {
+		return nil, &ValueError{Value: len(weights), Err: ErrInvalidWeights}
+	}
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, weight := range weights {
+		if weight < 0 {
+			return nil, &ValueError{Value: weight, Err: ErrInvalidWeights}
+		}
+		total += weight
+		cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, &ValueError{Value: total, Err: ErrInvalidWeights}
+	}
+	for i := range out {
+		point := r.Float64() * total
+		index := sort.Search(len(cumulative), func(j int) bool {
+			return cumulative[j] > point
+		})
+		if index == len(list) {
+			index--
+		}
+		out[i] = list[index]
+	}
+	return out, nil
+}
+
+// Sample returns a new list of k unique elements chosen from the list
+// without replacement, same as Python's random.sample. Elements are unique
+// by position, not by value. It returns *ValueError if k is negative or
+// larger than the list.
+func (list List) Sample(k int, r Rand) (List, error) {
+	if k < 0 || k > len(list) {
+		return nil, &ValueError{Value: k, Err: ErrSampleSize}
+	}
+	r = randOrDefault(r)
+	pool := append(List{}, list...)
+	for i := 0; i < k; i++ {
+		j := i + r.Intn(len(pool)-i)
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+	return pool[:k], nil
+}
+
+// Shuffle the elements of the list in place, same as Python's
+// random.shuffle.
+func (list List) Shuffle(r Rand) {
+	r = randOrDefault(r)
+	for i := len(list) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		list[i], list[j] = list[j], list[i]
+	}
+}
+
+// selectNth returns the element that would be at index n if values were
+// sorted by cmp, in linear expected time. It reorders values.
+func selectNth[T any](values []T, n int, cmp func(a, b T) int) T {
+	lo, hi := 0, len(values)
+	for hi-lo > 1 {
+		// Partition into [lo, lt) < pivot, [lt, gt) == pivot and
+		// [gt, hi) > pivot
+		pivot := values[lo+(hi-lo)/2]
+		lt, i, gt := lo, lo, hi
+		for i < gt {
+			switch c := cmp(values[i], pivot); {
+			case c < 0:
+				values[lt], values[i] = values[i], values[lt]
+				lt++
+				i++
+			case c > 0:
+				gt--
+				values[i], values[gt] = values[gt], values[i]
+			default:
+				i++
+			}
+		}
+		switch {
+		case n < lt:
+			hi = lt
+		case n >= gt:
+			lo = gt
+		default:
+			return values[n]
+		}
+	}
+	return values[n]
+}
@@ -0,0 +1,175 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func newTestRand() Rand {
+	return rand.New(rand.NewSource(42))
+}
+
+//=============================================================================
+
+func TestChoice(t *testing.T) {
+	list := List{"a", "b", "c", "d"}
+	first, err := list.Choice(newTestRand())
+	second, _ := list.Choice(newTestRand())
+	if err != nil || first != second || list.Count(first) != 1 {
+		t.Errorf("Choice() with the same seed => %v, %v, %v",
+			first, second, err)
+	}
+	if _, err := (List{}).Choice(nil); !errors.Is(err, ErrEmptySequence) {
+		t.Errorf("Choice() on empty list => %v, want %v",
+			err, ErrEmptySequence)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	list := List{"a", "b", "c"}
+	first, err := list.Choices(nil, 10, newTestRand())
+	second, _ := list.Choices(nil, 10, newTestRand())
+	if err != nil || len(first) != 10 || !reflect.DeepEqual(first, second) {
+		t.Errorf("Choices(nil, 10) with the same seed => %v, %v, %v",
+			first, second, err)
+	}
+
+	weighted, err := list.Choices([]float64{0, 1, 0}, 20, newTestRand())
+	if err != nil || weighted.Count("b") != 20 {
+		t.Errorf("Choices([0 1 0], 20) => %v, %v, want only b", weighted, err)
+	}
+
+	for _, weights := range [][]float64{{1}, {1, -1, 1}, {0, 0, 0}} {
+		_, err := list.Choices(weights, 1, nil)
+		if !errors.Is(err, ErrInvalidWeights) {
+			t.Errorf("Choices(%v, 1) => %v, want %v",
+				weights, err, ErrInvalidWeights)
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	list := List{1, 2, 3, 4, 5, 6}
+	sample, err := list.Sample(4, newTestRand())
+	again, _ := list.Sample(4, newTestRand())
+	if err != nil || len(sample) != 4 || !reflect.DeepEqual(sample, again) {
+		t.Errorf("Sample(4) with the same seed => %v, %v, %v",
+			sample, again, err)
+	}
+	for _, val := range sample {
+		if sample.Count(val) != 1 || list.Count(val) != 1 {
+			t.Errorf("Sample(4) => %v, elements should be unique", sample)
+		}
+	}
+	if !reflect.DeepEqual(list, List{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("Sample() changed the list to %v", list)
+	}
+	if _, err := list.Sample(7, nil); !errors.Is(err, ErrSampleSize) {
+		t.Errorf("Sample(7) => %v, want %v", err, ErrSampleSize)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	first := List{1, 2, 3, 4, 5, 6, 7, 8}
+	second := append(List{}, first...)
+	first.Shuffle(newTestRand())
+	second.Shuffle(newTestRand())
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Shuffle() with the same seed => %v and %v", first, second)
+	}
+	sorted, _ := first.Sorted(nil, false)
+	if !reflect.DeepEqual(sorted, List{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("Shuffle() lost elements: %v", first)
+	}
+}
+
+func TestPopItemRand(t *testing.T) {
+	pop := func() List {
+		dict := Dict{"a": 1, "b": 2, "c": 3, "d": 4}
+		item, _ := dict.PopItemRand(newTestRand())
+		return item
+	}
+	first := pop()
+	for i := 0; i < 10; i++ {
+		if item := pop(); !reflect.DeepEqual(item, first) {
+			t.Fatalf("PopItemRand() with the same seed => %v and %v",
+				first, item)
+		}
+	}
+
+	typed := TypedDict[int, string]{1: "a", 2: "b", 3: "c"}
+	pair, err := typed.PopItemRand(newTestRand())
+	if err != nil || len(typed) != 2 || typed.HasKey(pair.Key) {
+		t.Errorf("TypedDict.PopItemRand() => %v, %v, dict %v",
+			pair, err, typed)
+	}
+}
+
+func TestPopItemRandOrder(t *testing.T) {
+	popAll := func() List {
+		dict := Dict{}
+		for i := 0; i < 50; i++ {
+			dict[fmt.Sprint(i)] = i
+		}
+		r := newTestRand()
+		out := List{}
+		for len(dict) > 0 {
+			item, _ := dict.PopItemRand(r)
+			out = append(out, item[0])
+		}
+		return out
+	}
+	// Unorderable keys are ordered by their repr
+	popAllTyped := func() List {
+		dict := TypedDict[interface{}, int]{}
+		for i := 0; i < 25; i++ {
+			dict[i] = i
+			dict[fmt.Sprint(i)] = i
+		}
+		r := newTestRand()
+		out := List{}
+		for len(dict) > 0 {
+			pair, _ := dict.PopItemRand(r)
+			out = append(out, pair.Key)
+		}
+		return out
+	}
+
+	for index, pop := range []func() List{popAll, popAllTyped} {
+		first := pop()
+		for i := 0; i < 10; i++ {
+			if out := pop(); !reflect.DeepEqual(out, first) {
+				t.Fatalf("%d. PopItemRand() with the same seed => %v and %v",
+					index, first, out)
+			}
+		}
+	}
+}
+
+func TestSelectNth(t *testing.T) {
+	values := []int{5, 3, 3, 9, 1, 7, 3, 0, 8}
+	sorted := append([]int{}, values...)
+	sort.Ints(sorted)
+	for n := range values {
+		shuffled := append([]int{}, values...)
+		rand.New(rand.NewSource(int64(n))).Shuffle(len(shuffled),
+			func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+		if out := selectNth(shuffled, n, cmp.Compare[int]); out != sorted[n] {
+			t.Errorf("%d. selectNth(%v) => %v, want %v",
+				n, values, out, sorted[n])
+		}
+	}
+}
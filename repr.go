@@ -0,0 +1,375 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// Repr returns value in Python literal syntax, same as Python's repr:
+//
+//	listdict.Repr(listdict.List{"a", 2, listdict.Dict{"k": nil}})
+//	// ['a', 2, {'k': None}]
+//
+// Keys of Dict and Go maps are sorted, other dictionaries and sets keep
+// their order. Counter, Deque, FrozenSet and OrderedDict are written the
+// same as their Python versions, e.g. deque([1, 2]). A List containing
+// itself is written as [...]. Values without a Python version are
+// formatted with %v. Output of Repr for lists, tuples, dicts, sets,
+// strings, []byte and numbers can be read back with ParseLiteral.
+func Repr(value interface{}) string {
+	r := &reprWriter{active: make(map[container.ID]bool)}
+	r.write(value)
+	return r.buf.String()
+}
+
+// Repr returns the list in Python literal syntax, see Repr.
+func (list List) Repr() string {
+	return Repr(list)
+}
+
+// Repr returns the dictionary in Python literal syntax with sorted keys,
+// see Repr.
+func (dict Dict) Repr() string {
+	return Repr(dict)
+}
+
+//=============================================================================
+
+// reprWriter holds state of a single Repr.
+type reprWriter struct {
+	buf    strings.Builder
+	active map[container.ID]bool // containers being written, to find cycles
+}
+
+func (r *reprWriter) write(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		r.buf.WriteString("None")
+		return
+	case bool:
+		if v {
+			r.buf.WriteString("True")
+		} else {
+			r.buf.WriteString("False")
+		}
+		return
+	case string:
+		r.buf.WriteString(reprString(v))
+		return
+	case []byte:
+		r.buf.WriteString(reprBytes(v))
+		return
+	case *big.Int:
+		if v == nil {
+			r.buf.WriteString("None")
+		} else {
+			r.buf.WriteString(v.String())
+		}
+		return
+	case complex64:
+		r.writeComplex(complex128(v))
+		return
+	case complex128:
+		r.writeComplex(v)
+		return
+	case Tuple:
+		r.enter(v, "(...)", func() {
+			if len(v) == 1 {
+				// (a,) is a tuple, (a) is not
+				r.writeItems("(", List(v), ",)")
+			} else {
+				r.writeItems("(", List(v), ")")
+			}
+		})
+		return
+	case Counter:
+		if v.Len() == 0 {
+			r.buf.WriteString("Counter()")
+			return
+		}
+		r.buf.WriteString("Counter({")
+		for i, pair := range v.MostCommon(Omit) {
+			if i > 0 {
+				r.buf.WriteString(", ")
+			}
+			r.writePair(pair.Key, pair.Value)
+		}
+		r.buf.WriteString("})")
+		return
+	case DefaultDict:
+		r.write(v.Dict)
+		return
+	case ChainMap:
+		r.buf.WriteString("ChainMap(")
+		for i, dict := range v.Maps {
+			if i > 0 {
+				r.buf.WriteString(", ")
+			}
+			r.write(dict)
+		}
+		r.buf.WriteString(")")
+		return
+	case *Deque:
+		r.enter(v, "[...]", func() {
+			r.buf.WriteString("deque(")
+			r.writeItems("[", v.ToList(), "]")
+			if v.bounded {
+				fmt.Fprintf(&r.buf, ", maxlen=%d", v.maxLen)
+			}
+			r.buf.WriteString(")")
+		})
+		return
+	case *HashDict:
+		r.enter(v, "{...}", func() {
+			r.writeDict(v.Keys(), v.Values())
+		})
+		return
+	case *OrderedDict:
+		r.enter(v, "...", func() {
+			if v.Len() == 0 {
+				r.buf.WriteString("OrderedDict()")
+				return
+			}
+			r.buf.WriteString("OrderedDict(")
+			r.writeDict(v.Keys(), v.Values())
+			r.buf.WriteString(")")
+		})
+		return
+	case *Set:
+		if v.Len() == 0 {
+			r.buf.WriteString("set()")
+			return
+		}
+		r.writeItems("{", v.ToList(), "}")
+		return
+	case FrozenSet:
+		if v.Len() == 0 {
+			r.buf.WriteString("frozenset()")
+			return
+		}
+		r.buf.WriteString("frozenset(")
+		r.writeItems("{", v.ToList(), "}")
+		r.buf.WriteString(")")
+		return
+	}
+
+	if n, ok := toNumber(value); ok {
+		switch n.kind {
+		case intNumber:
+			r.buf.WriteString(strconv.FormatInt(n.i, 10))
+		case uintNumber:
+			r.buf.WriteString(strconv.FormatUint(n.u, 10))
+		case bigNumber:
+			r.buf.WriteString(n.b.String())
+		default:
+			r.buf.WriteString(floatRepr(n.f))
+		}
+		return
+	}
+	if s, ok := toString(value); ok {
+		r.buf.WriteString(reprString(s))
+		return
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		r.write(val.Bool())
+		return
+	case reflect.Slice, reflect.Array:
+		r.enter(value, "[...]", func() {
+			r.writeItems("[", reflectList(val), "]")
+		})
+		return
+	case reflect.Map:
+		r.enter(value, "{...}", func() {
+			keys := make(List, 0, val.Len())
+			values := make(List, 0, val.Len())
+			iter := val.MapRange()
+			for iter.Next() {
+				keys = append(keys, iter.Key().Interface())
+				values = append(values, iter.Value().Interface())
+			}
+			r.writeSortedDict(keys, values)
+		})
+		return
+	}
+	fmt.Fprintf(&r.buf, "%v", value)
+}
+
+// enter runs write for container value, writing cycle instead if value is
+// already being written.
+func (r *reprWriter) enter(value interface{}, cycle string, write func()) {
+	id, ok := container.Identity(reflect.ValueOf(value))
+	if !ok {
+		write()
+		return
+	}
+	if r.active[id] {
+		r.buf.WriteString(cycle)
+		return
+	}
+	r.active[id] = true
+	defer delete(r.active, id)
+	write()
+}
+
+func (r *reprWriter) writeItems(open string, list List, close string) {
+	r.buf.WriteString(open)
+	for i, val := range list {
+		if i > 0 {
+			r.buf.WriteString(", ")
+		}
+		r.write(val)
+	}
+	r.buf.WriteString(close)
+}
+
+func (r *reprWriter) writePair(key, value interface{}) {
+	r.write(key)
+	r.buf.WriteString(": ")
+	r.write(value)
+}
+
+func (r *reprWriter) writeDict(keys, values List) {
+	r.buf.WriteString("{")
+	for i := range keys {
+		if i > 0 {
+			r.buf.WriteString(", ")
+		}
+		r.writePair(keys[i], values[i])
+	}
+	r.buf.WriteString("}")
+}
+
+// writeSortedDict writes dictionary with keys sorted, or sorted by their
+// repr if they are unorderable.
+func (r *reprWriter) writeSortedDict(keys, values List) {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sorted, err := sortedBy(order, func(i int) interface{} {
+		return keys[i]
+	}, false)
+	if err != nil {
+		sorted = order
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return Repr(keys[sorted[i]]) < Repr(keys[sorted[j]])
+		})
+	}
+	sortedKeys := make(List, len(keys))
+	sortedValues := make(List, len(keys))
+	for i, index := range sorted {
+		sortedKeys[i], sortedValues[i] = keys[index], values[index]
+	}
+	r.writeDict(sortedKeys, sortedValues)
+}
+
+func (r *reprWriter) writeComplex(c complex128) {
+	// Python writes 2j, (1+2j) and (1-2j)
+	if real(c) == 0 && !math.Signbit(real(c)) {
+		r.buf.WriteString(complexPart(imag(c)) + "j")
+		return
+	}
+	sign := "+"
+	if math.Signbit(imag(c)) {
+		sign = ""
+	}
+	fmt.Fprintf(&r.buf, "(%s%s%sj)", complexPart(real(c)), sign,
+		complexPart(imag(c)))
+}
+
+// complexPart returns part of complex number as Python writes it, without
+// ".0" for whole numbers.
+func complexPart(f float64) string {
+	return strings.TrimSuffix(floatRepr(f), ".0")
+}
+
+//=============================================================================
+
+// reprString returns s quoted the same as Python's repr of str. Single
+// quotes are used unless s contains ' and no ".
+func reprString(s string) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") && !strings.Contains(s, `"`) {
+		quote = '"'
+	}
+
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			// Invalid UTF-8, Python would need bytes for it
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == rune(quote) || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case unicode.IsPrint(r):
+			sb.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case r < 0x10000:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		}
+		i += size
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
+
+// reprBytes returns b quoted the same as Python's repr of bytes.
+func reprBytes(b []byte) string {
+	quote := byte('\'')
+	if strings.Contains(string(b), "'") && !strings.Contains(string(b), `"`) {
+		quote = '"'
+	}
+
+	var sb strings.Builder
+	sb.WriteString("b")
+	sb.WriteByte(quote)
+	for _, c := range b {
+		switch {
+		case c == quote || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < ' ' || c >= 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+//=============================================================================
+
+var reprTests = []struct {
+	in  interface{}
+	out string
+}{
+	{nil, "None"},
+	{List{"a", 2, Dict{"k": nil}}, "['a', 2, {'k': None}]"},
+	{List{"a, b"}, "['a, b']"},
+	{List{true, false, 1.0, 0.1, -2.5e-7, math.Inf(1)},
+		"[True, False, 1.0, 0.1, -2.5e-07, inf]"},
+	{Dict{"b": List{}, "a": Dict{}}, "{'a': {}, 'b': []}"},
+	{"it's", `"it's"`},
+	{`it's "x"`, `'it\'s "x"'`},
+	{"tab\t\\ ż\x00\u200b\U0001F600", `'tab\t\\ ż\x00\u200b😀'`},
+	{[]byte("a'\x00\xff"), `b"a'\x00\xff"`},
+	{Tuple{}, "()"},
+	{Tuple{1}, "(1,)"},
+	{Tuple{1, "a"}, "(1, 'a')"},
+	{complex(0, 2), "2j"},
+	{complex(1.5, -2), "(1.5-2j)"},
+	{new(big.Int).Lsh(big.NewInt(1), 64), "18446744073709551616"},
+	{uint64(math.MaxUint64), "18446744073709551615"},
+	{map[int]string{10: "x", 2: "y"}, "{2: 'y', 10: 'x'}"},
+	{[]int{1, 2}, "[1, 2]"},
+	{Counter{HashDictFromDict(Dict{"a": 1, "b": 3})},
+		"Counter({'b': 3, 'a': 1})"},
+	{NewCounter(), "Counter()"},
+	{DequeFromList(List{1, 2}, 3), "deque([1, 2], maxlen=3)"},
+	{NewSet(), "set()"},
+	{FrozenSet{}, "frozenset()"},
+	{NewChainMap(Dict{"a": 1}, Dict{}), "ChainMap({'a': 1}, {})"},
+}
+
+func TestRepr(t *testing.T) {
+	for index, rt := range reprTests {
+		if out := Repr(rt.in); out != rt.out {
+			t.Errorf("%d. Repr(%v) => %s, want %s", index, rt.in, out, rt.out)
+		}
+	}
+}
+
+func TestReprOrdered(t *testing.T) {
+	set := newTestSet(t, "b", 1, Tuple{2})
+	if out := Repr(set); out != "{'b', 1, (2,)}" {
+		t.Errorf("Repr(Set) => %s, want {'b', 1, (2,)}", out)
+	}
+
+	dict := NewOrderedDict()
+	dict.Set("z", 1)
+	dict.Set("a", List{})
+	if out := Repr(dict); out != "OrderedDict({'z': 1, 'a': []})" {
+		t.Errorf("Repr(OrderedDict) => %s, want OrderedDict({'z': 1, 'a': []})",
+			out)
+	}
+
+	hashDict := NewHashDict()
+	hashDict.Set(2, "x")
+	hashDict.Set(Tuple{1, "a"}, nil)
+	if out := Repr(hashDict); out != "{2: 'x', (1, 'a'): None}" {
+		t.Errorf("Repr(HashDict) => %s, want {2: 'x', (1, 'a'): None}", out)
+	}
+}
+
+func TestReprCycle(t *testing.T) {
+	list := List{1, nil}
+	list[1] = list
+	if out := list.Repr(); out != "[1, [...]]" {
+		t.Errorf("Repr(cycle) => %s, want [1, [...]]", out)
+	}
+
+	dict := Dict{"a": 1}
+	dict["self"] = dict
+	if out := dict.Repr(); out != "{'a': 1, 'self': {...}}" {
+		t.Errorf("Repr(cycle) => %s, want {'a': 1, 'self': {...}}", out)
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+)
+
+// AbstractSet is implemented by Set and FrozenSet, set operations accept
+// any AbstractSet as the other operand.
+type AbstractSet interface {
+	// Contains returns true if value is an element of the set
+	Contains(value interface{}) bool
+	// Iter returns an iterator over elements of the set
+	Iter() iter.Seq[interface{}]
+	// Len returns the number of elements in the set
+	Len() int
+}
+
+// Set is an unordered collection of unique hashable elements, same as
+// Python's set. Elements are compared the same way as HashDict keys,
+// so 1, 1.0 and true are the same element. Set remembers insertion order
+// of elements, so ToList and String are predictable.
+//
+//	s, _ := listdict.SetFromList(listdict.List{1, 2, 2, 1.0})
+//	s.Len() // 2
+type Set struct {
+	items *HashDict // elements are keys, values are not used
+}
+
+var (
+	// ErrRemoveFromEmptySet is returned when user want to remove element
+	// from empty set
+	ErrRemoveFromEmptySet = errors.
+		New("Trying to remove element from empty set")
+)
+
+// NewSet returns new empty Set.
+func NewSet() *Set {
+	return &Set{items: NewHashDict()}
+}
+
+// SetFromList returns new Set with unique elements of the list, in list
+// order. It returns *TypeError if any element is unhashable.
+func SetFromList(list List) (*Set, error) {
+	set := NewSet()
+	for _, value := range list {
+		if err := set.Add(value); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// SetFromDict returns new Set with keys of the dict, in sorted order.
+func SetFromDict(dict Dict) *Set {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	set := NewSet()
+	for _, key := range keys {
+		set.items.Set(key, nil)
+	}
+	return set
+}
+
+//=============================================================================
+
+// Add adds an element to the set. It returns *TypeError if value is
+// unhashable.
+func (set *Set) Add(value interface{}) error {
+	return set.items.Set(value, nil)
+}
+
+// Clear removes all elements from the set.
+func (set *Set) Clear() {
+	set.items.Clear()
+}
+
+// Contains returns true if value is an element of the set.
+// Set value is looked up as FrozenSet, same as in Python.
+func (set *Set) Contains(value interface{}) bool {
+	return set.items.HasKey(setElement(value))
+}
+
+// Copy returns a shallow copy of the set.
+func (set *Set) Copy() *Set {
+	return &Set{items: setUnion(set)}
+}
+
+// Difference returns a new set with elements of the set that are not
+// in the others.
+func (set *Set) Difference(others ...AbstractSet) *Set {
+	return &Set{items: setDifference(set, others)}
+}
+
+// DifferenceUpdate removes elements found in the others from the set.
+func (set *Set) DifferenceUpdate(others ...AbstractSet) {
+	set.items = setDifference(set, others)
+}
+
+// Discard removes value from the set if it is present.
+// It returns *TypeError if value is unhashable.
+func (set *Set) Discard(value interface{}) error {
+	err := set.items.Delete(setElement(value))
+	if _, ok := err.(*KeyError); ok {
+		return nil
+	}
+	return err
+}
+
+// Frozen returns elements of the set as FrozenSet.
+func (set *Set) Frozen() FrozenSet {
+	return newFrozenSet(setUnion(set))
+}
+
+// Intersection returns a new set with elements common to the set and
+// all the others.
+func (set *Set) Intersection(others ...AbstractSet) *Set {
+	return &Set{items: setIntersection(set, others)}
+}
+
+// IntersectionUpdate keeps only elements found in the set and all
+// the others.
+func (set *Set) IntersectionUpdate(others ...AbstractSet) {
+	set.items = setIntersection(set, others)
+}
+
+// IsDisjoint returns true if the set has no elements in common with other.
+func (set *Set) IsDisjoint(other AbstractSet) bool {
+	return isDisjoint(set, other)
+}
+
+// IsEqual returns true if the set and other have the same elements.
+func (set *Set) IsEqual(other AbstractSet) bool {
+	return set.Len() == other.Len() && isSubset(set, other)
+}
+
+// IsSubset returns true if every element of the set is in other.
+func (set *Set) IsSubset(other AbstractSet) bool {
+	return isSubset(set, other)
+}
+
+// IsSuperset returns true if every element of other is in the set.
+func (set *Set) IsSuperset(other AbstractSet) bool {
+	return isSubset(other, set)
+}
+
+// Iter returns an iterator over elements of the set, in insertion order.
+func (set *Set) Iter() iter.Seq[interface{}] {
+	return set.items.IterKeys()
+}
+
+// Len returns the number of elements in the set.
+func (set *Set) Len() int {
+	return set.items.Len()
+}
+
+// Pop removes and returns the first element of the set.
+// It returns *KeyError if the set is empty.
+func (set *Set) Pop() (interface{}, error) {
+	if set.Len() <= 0 {
+		return nil, &KeyError{Err: ErrRemoveFromEmptySet}
+	}
+	value := set.items.entries.first(false).key
+	set.items.Delete(value)
+	return value, nil
+}
+
+// Remove removes value from the set. It returns *KeyError if value is
+// NOT in the set and *TypeError if value is unhashable.
+func (set *Set) Remove(value interface{}) error {
+	return set.items.Delete(setElement(value))
+}
+
+// String returns set elements as string in braces, same as in Python.
+//
+//	s.String() => "{1, a}"
+func (set *Set) String() string {
+	return setString(set)
+}
+
+// SymmetricDifference returns a new set with elements in either the set
+// or other but not in both.
+func (set *Set) SymmetricDifference(other AbstractSet) *Set {
+	return &Set{items: setSymmetricDifference(set, other)}
+}
+
+// SymmetricDifferenceUpdate keeps elements found in either the set or
+// other but not in both.
+func (set *Set) SymmetricDifferenceUpdate(other AbstractSet) {
+	set.items = setSymmetricDifference(set, other)
+}
+
+// ToList returns elements of the set as a new List, in insertion order.
+func (set *Set) ToList() List {
+	return set.items.Keys()
+}
+
+// Union returns a new set with elements from the set and all the others.
+func (set *Set) Union(others ...AbstractSet) *Set {
+	return &Set{items: setUnion(set, others...)}
+}
+
+// Update adds elements from all the others to the set.
+func (set *Set) Update(others ...AbstractSet) {
+	for _, other := range others {
+		for value := range other.Iter() {
+			set.items.Set(value, nil)
+		}
+	}
+}
+
+//=============================================================================
+
+// setElement returns value as it's stored in a set, Set is converted
+// to FrozenSet.
+func setElement(value interface{}) interface{} {
+	if set, ok := value.(*Set); ok {
+		return set.Frozen()
+	}
+	return value
+}
+
+// setUnion returns elements of all sets.
+func setUnion(set AbstractSet, others ...AbstractSet) *HashDict {
+	items := NewHashDict()
+	for _, s := range append([]AbstractSet{set}, others...) {
+		for value := range s.Iter() {
+			items.Set(value, nil)
+		}
+	}
+	return items
+}
+
+// setIntersection returns elements of set found in all others.
+func setIntersection(set AbstractSet, others []AbstractSet) *HashDict {
+	items := NewHashDict()
+next:
+	for value := range set.Iter() {
+		for _, other := range others {
+			if !other.Contains(value) {
+				continue next
+			}
+		}
+		items.Set(value, nil)
+	}
+	return items
+}
+
+// setDifference returns elements of set not found in any of others.
+func setDifference(set AbstractSet, others []AbstractSet) *HashDict {
+	items := NewHashDict()
+next:
+	for value := range set.Iter() {
+		for _, other := range others {
+			if other.Contains(value) {
+				continue next
+			}
+		}
+		items.Set(value, nil)
+	}
+	return items
+}
+
+// setSymmetricDifference returns elements found in only one of the sets.
+func setSymmetricDifference(set, other AbstractSet) *HashDict {
+	items := setDifference(set, []AbstractSet{other})
+	for value := range other.Iter() {
+		if !set.Contains(value) {
+			items.Set(value, nil)
+		}
+	}
+	return items
+}
+
+// isSubset returns true if every element of set is in other.
+func isSubset(set, other AbstractSet) bool {
+	if set.Len() > other.Len() {
+		return false
+	}
+	for value := range set.Iter() {
+		if !other.Contains(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDisjoint returns true if sets have no elements in common.
+func isDisjoint(set, other AbstractSet) bool {
+	for value := range set.Iter() {
+		if other.Contains(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// setString returns set elements as string in braces.
+func setString(set AbstractSet) string {
+	out := make([]string, 0, set.Len())
+	for value := range set.Iter() {
+		out = append(out, fmt.Sprintf("%v", value))
+	}
+	return "{" + strings.Join(out, ", ") + "}"
+}
@@ -0,0 +1,146 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func newTestSet(t *testing.T, values ...interface{}) *Set {
+	set, err := SetFromList(List(values))
+	if err != nil {
+		t.Fatalf("SetFromList(%v) => %v", values, err)
+	}
+	return set
+}
+
+func TestSetFromList(t *testing.T) {
+	set := newTestSet(t, 3, 1, "1", 3.0, true, Tuple{1, 2}, Tuple{1, 2})
+
+	if list := set.ToList(); !reflect.DeepEqual(list,
+		List{3, 1, "1", Tuple{1, 2}}) {
+		t.Errorf("ToList() => %v, want [3 1 1 (1, 2)]", list)
+	}
+	if s := set.String(); s != "{3, 1, 1, (1, 2)}" {
+		t.Errorf("String() => %v, want {3, 1, 1, (1, 2)}", s)
+	}
+	if _, err := SetFromList(List{1, List{2}}); !errors.Is(err,
+		ErrUnhashable) {
+		t.Errorf("SetFromList([1 [2]]) => %v, want %v", err, ErrUnhashable)
+	}
+
+	keys := SetFromDict(Dict{"b": 1, "a": 2})
+	if list := keys.ToList(); !reflect.DeepEqual(list, List{"a", "b"}) {
+		t.Errorf("SetFromDict().ToList() => %v, want [a b]", list)
+	}
+}
+
+func TestSetAddRemove(t *testing.T) {
+	set := NewSet()
+	set.Add(1)
+	set.Add(2.0)
+	set.Add(1.0)
+
+	if set.Len() != 2 || !set.Contains(true) || !set.Contains(2) {
+		t.Errorf("set => %v, want {1, 2}", set)
+	}
+	if err := set.Add(Dict{}); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Add({}) => %v, want %v", err, ErrUnhashable)
+	}
+	if err := set.Discard(5); err != nil {
+		t.Errorf("Discard(5) => %v, want nil", err)
+	}
+	if err := set.Remove(5); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Remove(5) => %v, want %v", err, ErrKeyNotFound)
+	}
+	if err := set.Remove(2); err != nil || set.Contains(2) {
+		t.Errorf("Remove(2) => %v, set %v", err, set)
+	}
+	if val, err := set.Pop(); val != 1 || err != nil {
+		t.Errorf("Pop() => %v, %v, want 1, nil", val, err)
+	}
+	if _, err := set.Pop(); !errors.Is(err, ErrRemoveFromEmptySet) {
+		t.Errorf("Pop() on empty set => %v, want %v", err,
+			ErrRemoveFromEmptySet)
+	}
+
+	inner := newTestSet(t, 1, 2)
+	set.Add(inner.Frozen())
+	if !set.Contains(inner) || !set.Contains(newTestSet(t, 2, 1)) {
+		t.Errorf("%v Contains(%v) => false, want true", set, inner)
+	}
+}
+
+var setOperationTests = []struct {
+	name string
+	op   func(a, b *Set) *Set
+	out  List
+}{
+	{"Union", func(a, b *Set) *Set { return a.Union(b) }, List{1, 2, 3, 4}},
+	{"Intersection", func(a, b *Set) *Set { return a.Intersection(b) },
+		List{2, 3}},
+	{"Difference", func(a, b *Set) *Set { return a.Difference(b) },
+		List{1}},
+	{"SymmetricDifference",
+		func(a, b *Set) *Set { return a.SymmetricDifference(b) },
+		List{1, 4}},
+	{"Update", func(a, b *Set) *Set { a.Update(b); return a },
+		List{1, 2, 3, 4}},
+	{"IntersectionUpdate",
+		func(a, b *Set) *Set { a.IntersectionUpdate(b); return a },
+		List{2, 3}},
+	{"DifferenceUpdate",
+		func(a, b *Set) *Set { a.DifferenceUpdate(b); return a },
+		List{1}},
+	{"SymmetricDifferenceUpdate",
+		func(a, b *Set) *Set { a.SymmetricDifferenceUpdate(b); return a },
+		List{1, 4}},
+}
+
+func TestSetOperations(t *testing.T) {
+	for index, st := range setOperationTests {
+		a, b := newTestSet(t, 1, 2, 3), newTestSet(t, 2.0, 3, 4)
+		if out := st.op(a, b).ToList(); !reflect.DeepEqual(out, st.out) {
+			t.Errorf("%d. %s => %v, want %v", index, st.name, out, st.out)
+		}
+		if b.Len() != 3 {
+			t.Errorf("%d. %s changed other set to %v", index, st.name, b)
+		}
+	}
+}
+
+func TestSetComparisons(t *testing.T) {
+	small, big := newTestSet(t, 1, 2), newTestSet(t, 3, 2, 1)
+	other := newTestSet(t, "a")
+
+	if !small.IsSubset(big) || big.IsSubset(small) {
+		t.Errorf("IsSubset(%v, %v) wrong", small, big)
+	}
+	if !big.IsSuperset(small) || small.IsSuperset(big) {
+		t.Errorf("IsSuperset(%v, %v) wrong", big, small)
+	}
+	if !small.IsDisjoint(other) || small.IsDisjoint(big) {
+		t.Errorf("IsDisjoint(%v, %v) wrong", small, other)
+	}
+	if !small.IsEqual(newTestSet(t, 2.0, true)) || small.IsEqual(big) {
+		t.Errorf("IsEqual(%v) wrong", small)
+	}
+	if !Equal(small, small.Frozen()) || Equal(small, small.ToList()) {
+		t.Errorf("Equal(%v, ...) wrong", small)
+	}
+	if !(List{small}).IsEqual(List{small}) || (List{small}).Count(
+		newTestSet(t, 1, 2)) != 1 {
+		t.Errorf("List{%v}.Count(%v) => 0, want 1", small, small)
+	}
+	if Hashable(small) {
+		t.Errorf("Hashable(%v) => true, want false", small)
+	}
+}
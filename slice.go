@@ -0,0 +1,231 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"math"
+)
+
+// Omit marks slice start, stop or step that was left out, same as
+// leaving it empty in Python's l[:3] or l[::2].
+const Omit = math.MinInt
+
+var (
+	// ErrZeroSliceStep is returned when user want to use slice with step 0
+	ErrZeroSliceStep = errors.New("slice step cannot be zero")
+)
+
+// Slice is a Python slice object. Use Omit for values that were left out.
+//
+//	listdict.Slice{1, Omit, 2}       // l[1::2]
+//	listdict.Slice{Omit, Omit, -1}   // l[::-1]
+type Slice struct {
+	Start, Stop, Step int
+}
+
+//=============================================================================
+
+// Indices returns start, stop and step of the slice applied to a sequence
+// of given length, same as Python's slice.indices. Out of range bounds are
+// clipped to the sequence.
+func (s Slice) Indices(length int) (start, stop, step int, err error) {
+	step = s.Step
+	if step == Omit {
+		step = 1
+	}
+	if step == 0 {
+		return 0, 0, 0, &ValueError{Value: step, Err: ErrZeroSliceStep}
+	}
+
+	lower, upper := 0, length
+	if step < 0 {
+		lower, upper = -1, length-1
+	}
+
+	clip := func(index, omitted int) int {
+		if index == Omit {
+			return omitted
+		}
+		if index < 0 {
+			index += length
+			if index < lower {
+				return lower
+			}
+		} else if index > upper {
+			return upper
+		}
+		return index
+	}
+
+	if step < 0 {
+		return clip(s.Start, upper), clip(s.Stop, lower), step, nil
+	}
+	return clip(s.Start, lower), clip(s.Stop, upper), step, nil
+}
+
+// Len returns the number of elements the slice selects from a sequence
+// of given length.
+func (s Slice) Len(length int) (int, error) {
+	start, stop, step, err := s.Indices(length)
+	if err != nil {
+		return 0, err
+	}
+	return sliceLen(start, stop, step), nil
+}
+
+// sliceLen returns the number of elements between start and stop
+// (values returned by Indices) taking every step element.
+func sliceLen(start, stop, step int) int {
+	switch {
+	case step > 0 && start < stop:
+		return (stop-start-1)/step + 1
+	case step < 0 && stop < start:
+		return (start-stop-1)/(-step) + 1
+	}
+	return 0
+}
+
+// normIndex converts Python index (possibly negative) into Go index.
+// It returns false if index is out of range.
+func normIndex(index, length int) (int, bool) {
+	if index < 0 {
+		index += length
+	}
+	return index, index >= 0 && index < length
+}
+
+// clampIndex converts Python index (possibly negative) into Go index
+// clipped to [0, length], the way list.insert does.
+func clampIndex(index, length int) int {
+	if index < 0 {
+		index += length
+		if index < 0 {
+			return 0
+		}
+	}
+	if index > length {
+		return length
+	}
+	return index
+}
+
+//=============================================================================
+
+// deleteIndex removes element with given index from the slice.
+func deleteIndex[S ~[]T, T any](s *S, index int) error {
+	if len(*s) <= 0 {
+		return &IndexError{Index: index, Err: ErrRemoveFromEmptyList}
+	}
+
+	length := len(*s)
+	i, ok := normIndex(index, length)
+	if !ok {
+		return &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+
+	var zero T
+	copy((*s)[i:], (*s)[i+1:])
+	(*s)[length-1] = zero
+	*s = (*s)[:length-1]
+	return nil
+}
+
+// deleteSlice removes elements selected by start:stop:step from the slice.
+func deleteSlice[S ~[]T, T any](s *S, start, stop, step int) error {
+	length := len(*s)
+	start, stop, step, err := Slice{start, stop, step}.Indices(length)
+	if err != nil {
+		return err
+	}
+	count := sliceLen(start, stop, step)
+	if count == 0 {
+		return nil
+	}
+	if step < 0 {
+		// Walk the same elements from left to right
+		start, step = start+(count-1)*step, -step
+	}
+
+	kept := start
+	for i := start; i < length; i++ {
+		if (i-start)%step == 0 && (i-start)/step < count {
+			continue
+		}
+		(*s)[kept] = (*s)[i]
+		kept++
+	}
+	var zero T
+	for i := kept; i < length; i++ {
+		(*s)[i] = zero
+	}
+	*s = (*s)[:kept]
+	return nil
+}
+
+// insertAt inserts values before element with given index, index is
+// clipped to the slice.
+func insertAt[S ~[]T, T any](s *S, index int, values ...T) {
+	index = clampIndex(index, len(*s))
+	length := len(*s)
+
+	*s = append(*s, values...)
+	copy((*s)[index+len(values):], (*s)[index:length])
+	copy((*s)[index:], values)
+}
+
+// reverse reverses the elements of the slice in place.
+func reverse[S ~[]T, T any](s S) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// setSlice replaces elements selected by start:stop:step with values.
+func setSlice[S ~[]T, T any](s *S, start, stop, step int, values []T) error {
+	start, stop, step, err := Slice{start, stop, step}.Indices(len(*s))
+	if err != nil {
+		return err
+	}
+	// values can share memory with s
+	values = append([]T{}, values...)
+
+	if step == 1 {
+		if stop < start {
+			stop = start
+		}
+		tail := append(S{}, (*s)[stop:]...)
+		oldLen := len(*s)
+		*s = append(append((*s)[:start], values...), tail...)
+		var zero T
+		for i := len(*s); i < oldLen; i++ {
+			(*s)[:oldLen][i] = zero
+		}
+		return nil
+	}
+
+	if sliceLen(start, stop, step) != len(values) {
+		return &ValueError{Value: len(values), Err: ErrSliceSize}
+	}
+	for i, index := 0, start; i < len(values); i, index = i+1, index+step {
+		(*s)[index] = values[i]
+	}
+	return nil
+}
+
+// sliceOf returns a new slice with elements selected by start:stop:step.
+func sliceOf[S ~[]T, T any](s S, start, stop, step int) (S, error) {
+	start, stop, step, err := Slice{start, stop, step}.Indices(len(s))
+	if err != nil {
+		return nil, err
+	}
+	out := make(S, sliceLen(start, stop, step))
+	for i, index := 0, start; i < len(out); i, index = i+1, index+step {
+		out[i] = s[index]
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"testing"
+)
+
+//=============================================================================
+
+var sliceIndicesTests = []struct {
+	in                      Slice
+	length                  int
+	start, stop, step, size int
+	outError                error
+}{
+	{Slice{Omit, Omit, Omit}, 5, 0, 5, 1, 5, nil},
+	{Slice{Omit, Omit, -1}, 5, 4, -1, -1, 5, nil},
+	{Slice{1, -1, 1}, 5, 1, 4, 1, 3, nil},
+	{Slice{-10, 10, 2}, 5, 0, 5, 2, 3, nil},
+	{Slice{10, -10, -2}, 5, 4, -1, -2, 3, nil},
+	{Slice{3, 1, 1}, 5, 3, 1, 1, 0, nil},
+	{Slice{Omit, Omit, Omit}, 0, 0, 0, 1, 0, nil},
+	{Slice{1, 2, 0}, 5, 0, 0, 0, 0, ErrZeroSliceStep},
+}
+
+func TestSliceIndices(t *testing.T) {
+	for index, sit := range sliceIndicesTests {
+		start, stop, step, err := sit.in.Indices(sit.length)
+		if start != sit.start || stop != sit.stop || step != sit.step ||
+			!errors.Is(err, sit.outError) {
+			t.Errorf("%d. %v.Indices(%d) => %d, %d, %d, %v, want %d, %d, %d, %v",
+				index, sit.in, sit.length, start, stop, step, err,
+				sit.start, sit.stop, sit.step, sit.outError)
+		}
+		size, _ := sit.in.Len(sit.length)
+		if size != sit.size {
+			t.Errorf("%d. %v.Len(%d) => %d, want %d",
+				index, sit.in, sit.length, size, sit.size)
+		}
+	}
+}
@@ -0,0 +1,759 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package toml
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+const (
+	// explicitNest marks tables declared by a header or by dotted keys,
+	// they can't be declared again
+	explicitNest = 1 << iota
+	// frozen marks inline tables and arrays, they can't be changed
+	frozen
+)
+
+// flags holds flags of keys, same as tomllib's Flags.
+type flags struct {
+	flags     int
+	recursive int // flags of the key and all keys under it
+	nested    map[string]*flags
+}
+
+func newFlags() *flags {
+	return &flags{nested: make(map[string]*flags)}
+}
+
+// is reports whether key or any of its parents with recursive flags has
+// flag.
+func (f *flags) is(key []string, flag int) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for _, k := range key[:len(key)-1] {
+		inner, ok := f.nested[k]
+		if !ok {
+			return false
+		}
+		if inner.recursive&flag != 0 {
+			return true
+		}
+		f = inner
+	}
+	inner, ok := f.nested[key[len(key)-1]]
+	return ok && (inner.flags|inner.recursive)&flag != 0
+}
+
+func (f *flags) set(key []string, flag int, recursive bool) {
+	for _, k := range key {
+		inner, ok := f.nested[k]
+		if !ok {
+			inner = newFlags()
+			f.nested[k] = inner
+		}
+		f = inner
+	}
+	if recursive {
+		f.recursive |= flag
+	} else {
+		f.flags |= flag
+	}
+}
+
+// unsetAll removes flags of key and all keys under it.
+func (f *flags) unsetAll(key []string) {
+	for _, k := range key[:len(key)-1] {
+		inner, ok := f.nested[k]
+		if !ok {
+			return
+		}
+		f = inner
+	}
+	delete(f.nested, key[len(key)-1])
+}
+
+//=============================================================================
+
+// decoder holds state of a single Loads.
+type decoder struct {
+	src     string
+	pos     int // byte index in src
+	depth   int // number of arrays and inline tables being decoded
+	root    listdict.Dict
+	flags   *flags
+	pending [][]string // keys to mark explicitNest at the next header
+}
+
+func newDecoder(text string) *decoder {
+	return &decoder{src: strings.ReplaceAll(text, "\r\n", "\n"),
+		root: make(listdict.Dict), flags: newFlags()}
+}
+
+// error returns *DecodeError for byte index pos.
+func (d *decoder) error(msg string, pos int) error {
+	before := d.src[:pos]
+	return &DecodeError{Msg: msg, Line: strings.Count(before, "\n") + 1,
+		Col: utf8.RuneCountInString(before[strings.LastIndex(before,
+			"\n")+1:]) + 1}
+}
+
+func (d *decoder) peek() byte {
+	if d.pos < len(d.src) {
+		return d.src[d.pos]
+	}
+	return 0
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.src) && (d.src[d.pos] == ' ' || d.src[d.pos] == '\t') {
+		d.pos++
+	}
+}
+
+// skipComment skips comment at d.pos, if there is one.
+func (d *decoder) skipComment() error {
+	if d.peek() != '#' {
+		return nil
+	}
+	for d.pos++; d.pos < len(d.src) && d.src[d.pos] != '\n'; d.pos++ {
+		if isControl(d.src[d.pos], "\t") {
+			return d.error("Illegal character in a comment", d.pos)
+		}
+	}
+	return nil
+}
+
+// skipArraySpace skips whitespace, new lines and comments in arrays.
+func (d *decoder) skipArraySpace() error {
+	for {
+		d.skipSpace()
+		switch d.peek() {
+		case '\n':
+			d.pos++
+		case '#':
+			if err := d.skipComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// isControl reports whether c is an ASCII control character not in
+// allowed.
+func isControl(c byte, allowed string) bool {
+	return (c < 0x20 || c == 0x7f) && strings.IndexByte(allowed, c) < 0
+}
+
+func isBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// keyText returns key as it's written in error messages.
+func keyText(key []string) string {
+	parts := make([]string, len(key))
+	for i, part := range key {
+		parts[i] = formatKey(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// nest returns table of root at key, creating missing tables. Arrays of
+// tables give their last table if lists is true.
+func nest(root listdict.Dict, key []string, lists bool) (listdict.Dict, bool) {
+	table := root
+	for _, k := range key {
+		value, ok := table[k]
+		if !ok {
+			value = make(listdict.Dict)
+			table[k] = value
+		}
+		if list, ok := value.(listdict.List); ok && lists && len(list) > 0 {
+			value = list[len(list)-1]
+		}
+		if table, ok = value.(listdict.Dict); !ok {
+			return nil, false
+		}
+	}
+	return table, true
+}
+
+//=============================================================================
+
+// document decodes all statements of the document.
+func (d *decoder) document() error {
+	for i, r := range d.src {
+		if r == utf8.RuneError && !strings.HasPrefix(d.src[i:], "\uFFFD") {
+			return d.error("Invalid UTF-8", i)
+		}
+	}
+	var header []string
+	for {
+		d.skipSpace()
+		if d.pos >= len(d.src) {
+			return nil
+		}
+		var err error
+		switch c := d.peek(); {
+		case c == '\n':
+			d.pos++
+			continue
+		case c == '[':
+			for _, key := range d.pending {
+				d.flags.set(key, explicitNest, false)
+			}
+			d.pending = nil
+			if strings.HasPrefix(d.src[d.pos:], "[[") {
+				header, err = d.arrayTableHeader()
+			} else {
+				header, err = d.tableHeader()
+			}
+		case isBareKey(c) || c == '"' || c == '\'':
+			err = d.keyValue(header)
+		case c != '#':
+			return d.error("Invalid statement", d.pos)
+		}
+		if err != nil {
+			return err
+		}
+		d.skipSpace()
+		if err := d.skipComment(); err != nil {
+			return err
+		}
+		if d.pos < len(d.src) && d.src[d.pos] != '\n' {
+			return d.error(
+				"Expected newline or end of document after a statement", d.pos)
+		}
+		d.pos++
+	}
+}
+
+// tableHeader decodes [key] and returns key.
+func (d *decoder) tableHeader() ([]string, error) {
+	start := d.pos
+	d.pos++
+	d.skipSpace()
+	key, err := d.key()
+	if err != nil {
+		return nil, err
+	}
+	if d.flags.is(key, explicitNest) || d.flags.is(key, frozen) {
+		return nil, d.error(fmt.Sprintf("Cannot declare %s twice",
+			keyText(key)), start)
+	}
+	d.flags.set(key, explicitNest, false)
+	if _, ok := nest(d.root, key, true); !ok {
+		return nil, d.error("Cannot overwrite a value", start)
+	}
+	if d.peek() != ']' {
+		return nil, d.error(
+			"Expected ']' at the end of a table declaration", d.pos)
+	}
+	d.pos++
+	return key, nil
+}
+
+// arrayTableHeader decodes [[key]], adding a table to array key.
+func (d *decoder) arrayTableHeader() ([]string, error) {
+	start := d.pos
+	d.pos += 2
+	d.skipSpace()
+	key, err := d.key()
+	if err != nil {
+		return nil, err
+	}
+	if d.flags.is(key, frozen) {
+		return nil, d.error(fmt.Sprintf("Cannot mutate immutable namespace %s",
+			keyText(key)), start)
+	}
+	d.flags.unsetAll(key)
+	d.flags.set(key, explicitNest, false)
+
+	parent, ok := nest(d.root, key[:len(key)-1], true)
+	if !ok {
+		return nil, d.error("Cannot overwrite a value", start)
+	}
+	last := key[len(key)-1]
+	if value, ok := parent[last]; ok {
+		list, ok := value.(listdict.List)
+		if !ok {
+			return nil, d.error(
+				"An object other than list found behind this key", start)
+		}
+		parent[last] = append(list, make(listdict.Dict))
+	} else {
+		parent[last] = listdict.List{make(listdict.Dict)}
+	}
+
+	if !strings.HasPrefix(d.src[d.pos:], "]]") {
+		return nil, d.error(
+			"Expected ']]' at the end of an array declaration", d.pos)
+	}
+	d.pos += 2
+	return key, nil
+}
+
+// keyValue decodes key = value in table header.
+func (d *decoder) keyValue(header []string) error {
+	start := d.pos
+	key, value, err := d.keyValuePair()
+	if err != nil {
+		return err
+	}
+	absKey := append(append([]string{}, header...), key...)
+	parentKey := absKey[:len(absKey)-1]
+	for i := len(header) + 1; i < len(absKey); i++ {
+		if d.flags.is(absKey[:i], explicitNest) {
+			return d.error(fmt.Sprintf("Cannot redefine namespace %s",
+				keyText(absKey[:i])), start)
+		}
+		d.pending = append(d.pending, absKey[:i])
+	}
+	if d.flags.is(parentKey, frozen) {
+		return d.error(fmt.Sprintf("Cannot mutate immutable namespace %s",
+			keyText(parentKey)), start)
+	}
+	table, ok := nest(d.root, parentKey, true)
+	if !ok {
+		return d.error("Cannot overwrite a value", start)
+	}
+	stem := key[len(key)-1]
+	if _, ok := table[stem]; ok {
+		return d.error("Cannot overwrite a value", start)
+	}
+	switch value.(type) {
+	case listdict.Dict, listdict.List:
+		d.flags.set(absKey, frozen, true)
+	}
+	table[stem] = value
+	return nil
+}
+
+func (d *decoder) keyValuePair() ([]string, interface{}, error) {
+	key, err := d.key()
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.peek() != '=' {
+		return nil, nil, d.error(
+			"Expected '=' after a key in a key/value pair", d.pos)
+	}
+	d.pos++
+	d.skipSpace()
+	value, err := d.value()
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, value, nil
+}
+
+// key decodes dotted key and whitespace after it.
+func (d *decoder) key() ([]string, error) {
+	var key []string
+	for {
+		part, err := d.keyPart()
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, part)
+		d.skipSpace()
+		if d.peek() != '.' {
+			return key, nil
+		}
+		d.pos++
+		d.skipSpace()
+	}
+}
+
+func (d *decoder) keyPart() (string, error) {
+	switch c := d.peek(); {
+	case c == '"':
+		return d.basicString(false)
+	case c == '\'':
+		return d.literalString(false)
+	case isBareKey(c):
+		start := d.pos
+		for d.pos < len(d.src) && isBareKey(d.src[d.pos]) {
+			d.pos++
+		}
+		return d.src[start:d.pos], nil
+	}
+	return "", d.error("Invalid initial character for a key part", d.pos)
+}
+
+//=============================================================================
+
+var (
+	numberRe = regexp.MustCompile(`^(?:0(?:x[0-9A-Fa-f](?:_?[0-9A-Fa-f])*|` +
+		`b[01](?:_?[01])*|o[0-7](?:_?[0-7])*)|` +
+		`[+-]?(?:0|[1-9](?:_?[0-9])*)((?:\.[0-9](?:_?[0-9])*)?` +
+		`(?:[eE][+-]?[0-9](?:_?[0-9])*)?))`)
+	timeRe = `([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9])(?:\.([0-9]+))?`
+	// Groups: year, month, day, hour, minute, second, fraction, Z, sign,
+	// offset hour, offset minute
+	dateTimeRe = regexp.MustCompile(`^([0-9]{4})-(0[1-9]|1[0-2])-` +
+		`(0[1-9]|[12][0-9]|3[01])(?:[Tt ]` + timeRe +
+		`(?:([Zz])|([+-])([01][0-9]|2[0-3]):([0-5][0-9]))?)?`)
+	localTimeRe = regexp.MustCompile(`^` + timeRe)
+)
+
+// value decodes a value starting at d.pos.
+func (d *decoder) value() (interface{}, error) {
+	rest := d.src[d.pos:]
+	switch c := d.peek(); {
+	case strings.HasPrefix(rest, `"""`):
+		return d.basicString(true)
+	case c == '"':
+		return d.basicString(false)
+	case strings.HasPrefix(rest, "'''"):
+		return d.literalString(true)
+	case c == '\'':
+		return d.literalString(false)
+	case strings.HasPrefix(rest, "true"):
+		d.pos += 4
+		return true, nil
+	case strings.HasPrefix(rest, "false"):
+		d.pos += 5
+		return false, nil
+	case c == '[':
+		return d.nested(d.array)
+	case c == '{':
+		return d.nested(d.inlineTable)
+	}
+
+	if m := dateTimeRe.FindStringSubmatch(rest); m != nil {
+		return d.dateTime(m)
+	}
+	if m := localTimeRe.FindStringSubmatch(rest); m != nil {
+		d.pos += len(m[0])
+		return date(0, 1, 1, m[1:], LocalTime), nil
+	}
+	if m := numberRe.FindStringSubmatch(rest); m != nil {
+		d.pos += len(m[0])
+		return number(m[0], m[1] != "")
+	}
+	for _, special := range []string{"inf", "+inf", "-inf", "nan", "+nan",
+		"-nan"} {
+		if strings.HasPrefix(rest, special) {
+			d.pos += len(special)
+			if strings.HasSuffix(special, "nan") {
+				return math.NaN(), nil
+			}
+			return math.Inf(1 - 2*strings.Count(special, "-")), nil
+		}
+	}
+	return nil, d.error("Invalid value", d.pos)
+}
+
+// number returns value of integer or float s matched by numberRe.
+func number(s string, float bool) (interface{}, error) {
+	s = strings.ReplaceAll(s, "_", "")
+	if float {
+		// Out of range values are infinities, as in Python
+		f, _ := strconv.ParseFloat(s, 64)
+		return f, nil
+	}
+	base := 10
+	if len(s) > 1 && s[0] == '0' {
+		base = map[byte]int{'x': 16, 'o': 8, 'b': 2}[s[1]]
+		s = s[2:]
+	}
+	n, _ := new(big.Int).SetString(strings.TrimPrefix(s, "+"), base)
+	if n.IsInt64() && int64(int(n.Int64())) == n.Int64() {
+		return int(n.Int64()), nil
+	}
+	return n, nil
+}
+
+// dateTime returns value of date or date-time matched by dateTimeRe.
+func (d *decoder) dateTime(m []string) (interface{}, error) {
+	start := d.pos
+	d.pos += len(m[0])
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	loc := LocalDateTime
+	switch {
+	case m[4] == "":
+		loc = LocalDate
+	case m[8] != "":
+		loc = time.UTC
+	case m[9] != "":
+		hours, _ := strconv.Atoi(m[10])
+		minutes, _ := strconv.Atoi(m[11])
+		offset := hours*3600 + minutes*60
+		if m[9] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+	t := date(year, month, day, m[4:8], loc)
+	if t.Day() != day {
+		return nil, d.error("Invalid date or datetime", start)
+	}
+	return t, nil
+}
+
+// date returns time.Time of date and clock of hour, minute, second and
+// fraction, which can be empty.
+func date(year, month, day int, clock []string, loc *time.Location) time.Time {
+	var parts [3]int
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(clock[i])
+	}
+	// Nanoseconds, further digits are dropped
+	fraction := (clock[3] + "000000000")[:9]
+	nsec, _ := strconv.Atoi(fraction)
+	return time.Date(year, time.Month(month), day, parts[0], parts[1],
+		parts[2], nsec, loc)
+}
+
+// nested returns result of decode for an array or inline table, or
+// *DecodeError if it's nested deeper than container.MaxDepth.
+func (d *decoder) nested(decode func() (interface{}, error)) (interface{},
+	error) {
+
+	if d.depth >= container.MaxDepth {
+		return nil, d.error("Maximum nesting depth exceeded", d.pos)
+	}
+	d.depth++
+	value, err := decode()
+	d.depth--
+	return value, err
+}
+
+// array decodes array starting at d.pos.
+func (d *decoder) array() (interface{}, error) {
+	start := d.pos
+	d.pos++
+	list := listdict.List{}
+	for {
+		if err := d.skipArraySpace(); err != nil {
+			return nil, err
+		}
+		if d.peek() == ']' {
+			d.pos++
+			return list, nil
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if err := d.skipArraySpace(); err != nil {
+			return nil, err
+		}
+		switch d.peek() {
+		case ']':
+			d.pos++
+			return list, nil
+		case ',':
+			d.pos++
+		default:
+			if d.pos >= len(d.src) {
+				return nil, d.error("Unclosed array", start)
+			}
+			return nil, d.error("Unclosed array", d.pos)
+		}
+	}
+}
+
+// inlineTable decodes inline table starting at d.pos.
+func (d *decoder) inlineTable() (interface{}, error) {
+	d.pos++
+	table := make(listdict.Dict)
+	flags := newFlags()
+	d.skipSpace()
+	if d.peek() == '}' {
+		d.pos++
+		return table, nil
+	}
+	for {
+		start := d.pos
+		key, value, err := d.keyValuePair()
+		if err != nil {
+			return nil, err
+		}
+		if flags.is(key, frozen) {
+			return nil, d.error(fmt.Sprintf(
+				"Cannot mutate immutable namespace %s", keyText(key)), start)
+		}
+		parent, ok := nest(table, key[:len(key)-1], false)
+		if !ok {
+			return nil, d.error("Cannot overwrite a value", start)
+		}
+		stem := key[len(key)-1]
+		if _, ok := parent[stem]; ok {
+			return nil, d.error(fmt.Sprintf("Duplicate inline table key %s",
+				formatKey(stem)), start)
+		}
+		parent[stem] = value
+		d.skipSpace()
+		switch d.peek() {
+		case '}':
+			d.pos++
+			return table, nil
+		case ',':
+			d.pos++
+		default:
+			return nil, d.error("Unclosed inline table", d.pos)
+		}
+		switch value.(type) {
+		case listdict.Dict, listdict.List:
+			flags.set(key, frozen, true)
+		}
+		d.skipSpace()
+	}
+}
+
+//=============================================================================
+
+// literalString decodes literal string, multi-line if multiline is true.
+func (d *decoder) literalString(multiline bool) (string, error) {
+	if !multiline {
+		d.pos++
+		start := d.pos
+		for ; d.pos < len(d.src) && d.src[d.pos] != '\''; d.pos++ {
+			if d.src[d.pos] == '\n' {
+				return "", d.error("Unterminated string", d.pos)
+			}
+			if isControl(d.src[d.pos], "\t") {
+				return "", d.error("Illegal character in a string", d.pos)
+			}
+		}
+		if d.pos >= len(d.src) {
+			return "", d.error("Unterminated string", d.pos)
+		}
+		d.pos++
+		return d.src[start : d.pos-1], nil
+	}
+
+	d.pos += 3
+	if d.peek() == '\n' {
+		d.pos++
+	}
+	start := d.pos
+	end := strings.Index(d.src[d.pos:], "'''")
+	if end < 0 {
+		return "", d.error("Unterminated string", len(d.src))
+	}
+	for ; d.pos < start+end; d.pos++ {
+		if isControl(d.src[d.pos], "\t\n") {
+			return "", d.error("Illegal character in a string", d.pos)
+		}
+	}
+	d.pos += 3
+	return d.src[start:start+end] + d.extraQuotes('\''), nil
+}
+
+// extraQuotes returns up to two quotes after the closing delimiter of
+// multi-line string, which belong to the string.
+func (d *decoder) extraQuotes(quote byte) string {
+	start := d.pos
+	for d.pos < start+2 && d.peek() == quote {
+		d.pos++
+	}
+	return d.src[start:d.pos]
+}
+
+var escapes = map[byte]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f",
+	'r': "\r", '"': `"`, '\\': `\`}
+
+// basicString decodes basic string with escapes, multi-line if multiline
+// is true.
+func (d *decoder) basicString(multiline bool) (string, error) {
+	allowed, delimiter := "\t", `"`
+	if multiline {
+		allowed, delimiter = "\t\n", `"""`
+		d.pos += 3
+		if d.peek() == '\n' {
+			d.pos++
+		}
+	} else {
+		d.pos++
+	}
+
+	var sb strings.Builder
+	for {
+		if d.pos >= len(d.src) {
+			return "", d.error("Unterminated string", d.pos)
+		}
+		c := d.src[d.pos]
+		switch {
+		case strings.HasPrefix(d.src[d.pos:], delimiter):
+			d.pos += len(delimiter)
+			if multiline {
+				sb.WriteString(d.extraQuotes('"'))
+			}
+			return sb.String(), nil
+		case c == '\\':
+			if err := d.escape(&sb, multiline); err != nil {
+				return "", err
+			}
+		case c == '\n' && !multiline:
+			return "", d.error("Unterminated string", d.pos)
+		case isControl(c, allowed):
+			return "", d.error("Illegal character in a string", d.pos)
+		default:
+			sb.WriteByte(c)
+			d.pos++
+		}
+	}
+}
+
+// escape decodes escape sequence at d.pos into sb.
+func (d *decoder) escape(sb *strings.Builder, multiline bool) error {
+	start := d.pos
+	d.pos++
+	c := d.peek()
+	if multiline && (c == ' ' || c == '\t' || c == '\n') {
+		// Line ending backslash trims whitespace up to the next text
+		d.skipSpace()
+		if d.peek() != '\n' {
+			return d.error("Unescaped '\\' in a string", d.pos)
+		}
+		for d.pos < len(d.src) && strings.IndexByte(" \t\n", d.src[d.pos]) >= 0 {
+			d.pos++
+		}
+		return nil
+	}
+	if s, ok := escapes[c]; ok {
+		sb.WriteString(s)
+		d.pos++
+		return nil
+	}
+	size := map[byte]int{'u': 4, 'U': 8}[c]
+	if size == 0 || d.pos+1+size > len(d.src) {
+		return d.error("Unescaped '\\' in a string", start)
+	}
+	hex := d.src[d.pos+1 : d.pos+1+size]
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || strings.ContainsAny(hex, "+-") {
+		return d.error("Invalid hex value", d.pos+1)
+	}
+	r := rune(code)
+	if !utf8.ValidRune(r) {
+		return d.error("Escaped character is not a Unicode scalar value",
+			d.pos+1)
+	}
+	sb.WriteRune(r)
+	d.pos += 1 + size
+	return nil
+}
@@ -0,0 +1,424 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package toml
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// maxLineLength is the length of lines above which arrays are split.
+const maxLineLength = 100
+
+// encoder holds state of a single Dumps.
+type encoder struct {
+	buf    strings.Builder
+	active map[container.ID]bool // containers being encoded, to find cycles
+}
+
+// document writes table as the whole document.
+func (e *encoder) document(value interface{}) error {
+	keys, values, ok, err := e.mapping(value, "")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &EncodeError{Value: value, Err: ErrUnrepresentable}
+	}
+	return e.enter(value, "", func() error {
+		return e.table(nil, "", keys, values, false)
+	})
+}
+
+// enter runs encode for container value, returning *EncodeError if value
+// is already being encoded.
+func (e *encoder) enter(value interface{}, path string,
+	encode func() error) error {
+
+	id, ok := container.Identity(reflect.ValueOf(value))
+	if !ok {
+		return encode()
+	}
+	if e.active[id] {
+		return &EncodeError{Path: path, Value: value, Err: ErrCircularReference}
+	}
+	e.active[id] = true
+	defer delete(e.active, id)
+	return encode()
+}
+
+// join returns path of key in table at path.
+func join(path, key string) string {
+	if path == "" {
+		return formatKey(key)
+	}
+	return path + "." + formatKey(key)
+}
+
+//=============================================================================
+
+// table writes table at header, its values go before its sub-tables.
+// Header is written if the table has values or nothing else, or it's
+// a table of array.
+func (e *encoder) table(header []string, path string, keys []string,
+	values listdict.List, inArray bool) error {
+
+	type subTable struct {
+		key          string
+		keys         []string
+		values       listdict.List
+		tables       listdict.List // tables of array of tables
+		value        interface{}
+		arrayOfTable bool
+	}
+	var pairs []int
+	var tables []subTable
+	for i, key := range keys {
+		keyPath := join(path, key)
+		subKeys, subValues, ok, err := e.mapping(values[i], keyPath)
+		if err != nil {
+			return err
+		}
+		if ok {
+			tables = append(tables, subTable{key: key, keys: subKeys,
+				values: subValues, value: values[i]})
+			continue
+		}
+		if list, ok := e.tables(values[i]); ok {
+			tables = append(tables, subTable{key: key, tables: list,
+				value: values[i], arrayOfTable: true})
+			continue
+		}
+		pairs = append(pairs, i)
+	}
+
+	if inArray || len(header) > 0 && (len(pairs) > 0 || len(tables) == 0) {
+		if e.buf.Len() > 0 {
+			e.buf.WriteByte('\n')
+		}
+		if inArray {
+			e.buf.WriteString("[[" + keyText(header) + "]]\n")
+		} else {
+			e.buf.WriteString("[" + keyText(header) + "]\n")
+		}
+	}
+	for _, i := range pairs {
+		prefix := formatKey(keys[i]) + " = "
+		text, err := e.value(values[i], join(path, keys[i]), 0, len(prefix))
+		if err != nil {
+			return err
+		}
+		e.buf.WriteString(prefix + text + "\n")
+	}
+	for _, sub := range tables {
+		subHeader := append(header[:len(header):len(header)], sub.key)
+		subPath := join(path, sub.key)
+		err := e.enter(sub.value, subPath, func() error {
+			if !sub.arrayOfTable {
+				return e.table(subHeader, subPath, sub.keys, sub.values, false)
+			}
+			for i, item := range sub.tables {
+				itemPath := fmt.Sprintf("%s[%d]", subPath, i)
+				keys, values, _, err := e.mapping(item, itemPath)
+				if err != nil {
+					return err
+				}
+				err = e.enter(item, itemPath, func() error {
+					return e.table(subHeader, itemPath, keys, values, true)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapping returns keys and values of table value, sorted unless value is
+// ordered. It returns false if value isn't a table.
+func (e *encoder) mapping(value interface{}, path string) ([]string,
+	listdict.List, bool, error) {
+
+	switch v := value.(type) {
+	case listdict.Counter:
+		return e.mapping(v.HashDict, path)
+	case listdict.DefaultDict:
+		return e.mapping(v.Dict, path)
+	case listdict.ChainMap:
+		return e.mapping(v.ToDict(), path)
+	case *listdict.OrderedDict:
+		return stringKeys(v.Keys(), v.Values(), path)
+	case *listdict.HashDict:
+		return stringKeys(v.Keys(), v.Values(), path)
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Map {
+		return nil, nil, false, nil
+	}
+	if val.Type().Key().Kind() != reflect.String {
+		return nil, nil, false, &EncodeError{Path: path, Value: value,
+			Err: ErrInvalidKey}
+	}
+	keys := make([]string, 0, val.Len())
+	for _, key := range val.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	values := make(listdict.List, len(keys))
+	for i, key := range keys {
+		values[i] = val.MapIndex(reflect.ValueOf(key).Convert(
+			val.Type().Key())).Interface()
+	}
+	return keys, values, true, nil
+}
+
+// stringKeys returns keys of ordered dictionary as strings.
+func stringKeys(keys, values listdict.List, path string) ([]string,
+	listdict.List, bool, error) {
+
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		s, ok := key.(string)
+		if !ok {
+			return nil, nil, false, &EncodeError{Path: path, Value: key,
+				Err: ErrInvalidKey}
+		}
+		out[i] = s
+	}
+	return out, values, true, nil
+}
+
+// tables returns value as list if it's non-empty list of tables.
+func (e *encoder) tables(value interface{}) (listdict.List, bool) {
+	list, ok := asList(value)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	for _, item := range list {
+		if _, _, ok, err := e.mapping(item, ""); !ok || err != nil {
+			return nil, false
+		}
+	}
+	return list, true
+}
+
+// asList returns value as List if it's an array.
+func asList(value interface{}) (listdict.List, bool) {
+	switch v := value.(type) {
+	case listdict.List:
+		return v, true
+	case listdict.Tuple:
+		return listdict.List(v), true
+	case *listdict.Deque:
+		return v.ToList(), true
+	case []byte:
+		return nil, false
+	}
+	val := reflect.ValueOf(value)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, false
+	}
+	list := make(listdict.List, val.Len())
+	for i := range list {
+		list[i] = val.Index(i).Interface()
+	}
+	return list, true
+}
+
+//=============================================================================
+
+// value returns value in inline form. Arrays longer than maxLineLength,
+// counting col characters before them, are split into lines indented by
+// level, arrays in inline tables have col -1 and are never split.
+func (e *encoder) value(value interface{}, path string, level, col int) (
+	string, error) {
+
+	switch v := value.(type) {
+	case string:
+		return formatString(v, path)
+	case time.Time:
+		return formatTime(v), nil
+	case *big.Int:
+		if v != nil {
+			return v.String(), nil
+		}
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(val.Float()), nil
+	case reflect.String:
+		return formatString(val.String(), path)
+	}
+
+	keys, values, ok, err := e.mapping(value, path)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		var text string
+		err = e.enter(value, path, func() error {
+			text, err = e.inlineTable(path, keys, values)
+			return err
+		})
+		return text, err
+	}
+	if list, ok := asList(value); ok {
+		var text string
+		err = e.enter(value, path, func() error {
+			text, err = e.array(list, path, level, col)
+			return err
+		})
+		return text, err
+	}
+	return "", &EncodeError{Path: path, Value: value, Err: ErrUnrepresentable}
+}
+
+func (e *encoder) inlineTable(path string, keys []string,
+	values listdict.List) (string, error) {
+
+	if len(keys) == 0 {
+		return "{}", nil
+	}
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		text, err := e.value(values[i], join(path, key), 0, -1)
+		if err != nil {
+			return "", err
+		}
+		items[i] = formatKey(key) + " = " + text
+	}
+	return "{ " + strings.Join(items, ", ") + " }", nil
+}
+
+func (e *encoder) array(list listdict.List, path string, level, col int) (
+	string, error) {
+
+	items := make([]string, len(list))
+	for i, item := range list {
+		text, err := e.value(item, fmt.Sprintf("%s[%d]", path, i), level, -1)
+		if err != nil {
+			return "", err
+		}
+		items[i] = text
+	}
+	text := "[" + strings.Join(items, ", ") + "]"
+	if col < 0 || col+len(text) <= maxLineLength {
+		return text, nil
+	}
+
+	// One item on a line, each of them can be split again
+	indent := strings.Repeat("    ", level+1)
+	var sb strings.Builder
+	sb.WriteString("[\n")
+	for i, item := range list {
+		text, err := e.value(item, fmt.Sprintf("%s[%d]", path, i), level+1,
+			len(indent))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(indent + text + ",\n")
+	}
+	sb.WriteString(strings.Repeat("    ", level) + "]")
+	return sb.String(), nil
+}
+
+//=============================================================================
+
+// formatKey returns key as bare key if possible, or as basic string.
+func formatKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isBareKey(key[i]) {
+			s, _ := formatString(key, "")
+			return s
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// formatString returns s as basic string.
+func formatString(s, path string) (string, error) {
+	if !utf8.ValidString(s) {
+		return "", &EncodeError{Path: path, Value: s, Err: ErrUnrepresentable}
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\b':
+			sb.WriteString(`\b`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String(), nil
+}
+
+// formatFloat returns f as Python's repr, which is valid TOML.
+func formatFloat(f float64) string {
+	if math.IsNaN(f) {
+		return "nan"
+	}
+	return listdict.Repr(f)
+}
+
+// formatTime returns t as local date, local time, local date-time or
+// offset date-time, depending on its location.
+func formatTime(t time.Time) string {
+	switch t.Location() {
+	case LocalDate:
+		return t.Format("2006-01-02")
+	case LocalTime:
+		return t.Format("15:04:05.999999999")
+	case LocalDateTime:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
@@ -0,0 +1,131 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package toml reads and writes TOML 1.0 documents with listdict types, same
+as Python's tomllib.
+
+	dict, _ := toml.Loads("[project]\nname = \"web\"\nports = [80, 443]\n")
+	// dict = listdict.Dict{"project": listdict.Dict{"name": "web",
+	//	"ports": listdict.List{80, 443}}}
+	s, _ := toml.Dumps(dict)	// s = "[project]\nname = \"web\"\nports = [80, 443]\n"
+
+TOML values are decoded as:
+
+	table, inline table        listdict.Dict
+	array, array of tables     listdict.List ([[x]] gives List of Dict)
+	string                     string
+	integer                    int, or *big.Int if it doesn't fit
+	float                      float64
+	boolean                    bool
+	offset date-time           time.Time in time.UTC or time.FixedZone
+	local date-time            time.Time in LocalDateTime
+	local date                 time.Time in LocalDate
+	local time                 time.Time in LocalTime, on January 1, year 0
+
+Dumps writes time.Time in these locations back the same way, other times
+as offset date-times.
+*/
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+var (
+	// LocalDateTime is location of decoded local date-times, e.g.
+	// 1979-05-27T07:32:00, which have no offset
+	LocalDateTime = time.FixedZone("LocalDateTime", 0)
+	// LocalDate is location of decoded local dates, e.g. 1979-05-27
+	LocalDate = time.FixedZone("LocalDate", 0)
+	// LocalTime is location of decoded local times, e.g. 07:32:00
+	LocalTime = time.FixedZone("LocalTime", 0)
+)
+
+var (
+	// ErrInvalidTOML is returned when user want to decode invalid TOML
+	ErrInvalidTOML = errors.New("invalid TOML")
+	// ErrUnrepresentable is returned when user want to encode value of
+	// a type TOML doesn't support, e.g. nil, or a document that is not
+	// a table
+	ErrUnrepresentable = errors.New("value can't be represented in TOML")
+	// ErrInvalidKey is returned when user want to encode table with key
+	// that is not a string
+	ErrInvalidKey = errors.New("keys must be str")
+	// ErrCircularReference is returned when user want to encode value
+	// that contains itself
+	ErrCircularReference = errors.New("circular reference detected")
+)
+
+// DecodeError is returned by Loads for invalid TOML, same as Python's
+// tomllib.TOMLDecodeError. It wraps ErrInvalidTOML.
+type DecodeError struct {
+	Msg  string // what is wrong, e.g. "Invalid value"
+	Line int    // line where decoding failed, from 1
+	Col  int    // column of Line, from 1
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s (at line %d, column %d)", e.Msg, e.Line, e.Col)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return ErrInvalidTOML
+}
+
+// EncodeError is returned by Dumps for values it can't encode. It wraps
+// ErrUnrepresentable, ErrInvalidKey or ErrCircularReference.
+type EncodeError struct {
+	Path  string      // where the value is, e.g. servers[1].port
+	Value interface{} // value that can't be encoded
+	Err   error
+}
+
+func (e *EncodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%v: %T", e.Err, e.Value)
+	}
+	return fmt.Sprintf("%v: %T at %s", e.Err, e.Value, e.Path)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+//=============================================================================
+
+// Loads decodes TOML document, same as Python's tomllib.loads. Invalid
+// documents return *DecodeError.
+//
+//	toml.Loads("a.b = 1\n[[c]]\n") => listdict.Dict{"a": listdict.Dict{"b": 1},
+//		"c": listdict.List{listdict.Dict{}}}
+func Loads(text string) (listdict.Dict, error) {
+	d := newDecoder(text)
+	if err := d.document(); err != nil {
+		return nil, err
+	}
+	return d.root, nil
+}
+
+// Dumps returns table encoded as TOML document. Keys of Dict and Go maps
+// are sorted, ordered dictionaries keep their order. Values of a table are
+// written before its sub-tables, non-empty lists holding only tables are
+// written as arrays of tables. Values TOML can't represent return
+// *EncodeError.
+//
+//	toml.Dumps(listdict.Dict{"b": listdict.Dict{"c": 1}, "a": 2}) => "a = 2\n\n[b]\nc = 1\n"
+func Dumps(table interface{}) (string, error) {
+	e := &encoder{active: make(map[container.ID]bool)}
+	if err := e.document(table); err != nil {
+		return "", err
+	}
+	return e.buf.String(), nil
+}
@@ -0,0 +1,317 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package toml
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+//=============================================================================
+
+// Values checked against Python's tomllib.loads
+var loadsTests = []struct {
+	in   string
+	repr string
+}{
+	{"", "{}"},
+	{"# only comment\n\n", "{}"},
+	{"title = \"TOML\"\n[owner]\nname = \"Tom\"\n[database]\nports = [8000, 8001]\n" +
+		"temp = { cpu = 79.5, case = 72.0 }\n",
+		"{'database': {'ports': [8000, 8001], 'temp': {'case': 72.0, 'cpu': 79.5}}, " +
+			"'owner': {'name': 'Tom'}, 'title': 'TOML'}"},
+	{"bare-key_1 = 1\n\"a.b\" = 2\n'c d' = 3\n\"\" = 4\nsite.\"google.com\" = true\n" +
+		"fruit . flavor = 5\n3.14 = 6\n",
+		"{'': 4, '3': {'14': 6}, 'a.b': 2, 'bare-key_1': 1, 'c d': 3, " +
+			"'fruit': {'flavor': 5}, 'site': {'google.com': True}}"},
+	{"a = \"I'm \\\"q\\\"\\tJos\\u00E9\\n\\U0001F600\"\nb = 'C:\\Users\\x'\n" +
+		"c = \"\"\"\nRoses\nViolets\"\"\"\nd = \"\"\"\\\n    quick \\\n\n    fox\"\"\"\n" +
+		"e = '''\nraw \\n'''\nf = \"\"\"\"quoted\"\"\"\"\ng = ''''a''''\n",
+		"{'a': 'I\\'m \"q\"\\tJosé\\n\U0001F600', 'b': 'C:\\\\Users\\\\x', " +
+			"'c': 'Roses\\nViolets', 'd': 'quick fox', 'e': 'raw \\\\n', " +
+			"'f': '\"quoted\"', 'g': \"'a'\"}"},
+	{"a = +99\nb = -17\nc = 1_000\nd = 0xDEAD_beef\ne = 0o755\nf = 0b1101\n" +
+		"g = -0\nh = 99999999999999999999\n",
+		"{'a': 99, 'b': -17, 'c': 1000, 'd': 3735928559, 'e': 493, 'f': 13, " +
+			"'g': 0, 'h': 99999999999999999999}"},
+	{"a = +1.0\nb = -0.01\nc = 5e+22\nd = 1e06\ne = 224_617.445_991\n" +
+		"f = -inf\ng = 1e400\n",
+		"{'a': 1.0, 'b': -0.01, 'c': 5e+22, 'd': 1000000.0, 'e': 224617.445991, " +
+			"'f': -inf, 'g': inf}"},
+	{"a = [\n  1,\n  \"b\", # comment\n  [true, false],\n  { x = [] },\n]\nb = []\n",
+		"{'a': [1, 'b', [True, False], {'x': []}], 'b': []}"},
+	{"[a.b.c]\n[ d . \"e\" . 'f' ]\n[g]\nh.i = 1\n[g.h.j]\n",
+		"{'a': {'b': {'c': {}}}, 'd': {'e': {'f': {}}}, 'g': {'h': {'i': 1, " +
+			"'j': {}}}}"},
+	{"a = { b.c = 1, b.d = 2, e = {} }\n", "{'a': {'b': {'c': 1, 'd': 2}, 'e': {}}}"},
+	{"[[products]]\nname = \"Hammer\"\n[[products]]\n[[products]]\nname = \"Nail\"\n",
+		"{'products': [{'name': 'Hammer'}, {}, {'name': 'Nail'}]}"},
+	{"[[fruits]]\nname = \"apple\"\n[fruits.physical]\ncolor = \"red\"\n" +
+		"[[fruits.varieties]]\nname = \"red\"\n[[fruits]]\nname = \"banana\"\n" +
+		"[[fruits.varieties]]\nname = \"plantain\"\n",
+		"{'fruits': [{'name': 'apple', 'physical': {'color': 'red'}, " +
+			"'varieties': [{'name': 'red'}]}, {'name': 'banana', 'varieties': " +
+			"[{'name': 'plantain'}]}]}"},
+	{"[[a]]\nb.c = 1\n[[a]]\nb.c = 2\n", "{'a': [{'b': {'c': 1}}, {'b': {'c': 2}}]}"},
+	{"a = 1\r\nb = \"\"\"x\r\ny\"\"\"\r\n", "{'a': 1, 'b': 'x\\ny'}"},
+	{"a\t=\t1\t\n[\tb\t]", "{'a': 1, 'b': {}}"},
+}
+
+func TestLoads(t *testing.T) {
+	for index, lt := range loadsTests {
+		out, err := Loads(lt.in)
+		if repr := listdict.Repr(out); repr != lt.repr || err != nil {
+			t.Errorf("%d. Loads(%q) => %s, %v, want %s",
+				index, lt.in, repr, err, lt.repr)
+		}
+	}
+
+	out, _ := Loads("a = [1, 2.5, \"x\"]\n[b]\n")
+	want := listdict.Dict{"a": listdict.List{1, 2.5, "x"}, "b": listdict.Dict{}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Loads() => %#v, want %#v", out, want)
+	}
+	if out, _ := Loads("a = nan\n"); !math.IsNaN(out["a"].(float64)) {
+		t.Errorf("Loads(nan) => %v, want NaN", out)
+	}
+	out, _ = Loads("a = 9223372036854775808\n")
+	if n, ok := out["a"].(*big.Int); !ok || n.String() != "9223372036854775808" {
+		t.Errorf("Loads(2**63) => %#v, want *big.Int", out["a"])
+	}
+}
+
+func TestLoadsDates(t *testing.T) {
+	out, err := Loads("odt1 = 1979-05-27T07:32:00Z\nodt2 = 1979-05-27 00:32:00.999999-07:00\n" +
+		"ldt = 1979-05-27T07:32:00.5\nld = 1979-05-27\nlt = 07:32:00.123456789\n" +
+		"arr = [2000-02-29]\n")
+	if err != nil {
+		t.Fatalf("Loads(dates) => %v", err)
+	}
+	want := listdict.Dict{
+		"odt1": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		"odt2": time.Date(1979, 5, 27, 0, 32, 0, 999999000,
+			time.FixedZone("", -7*3600)),
+		"ldt": time.Date(1979, 5, 27, 7, 32, 0, 5e8, LocalDateTime),
+		"ld":  time.Date(1979, 5, 27, 0, 0, 0, 0, LocalDate),
+		"lt":  time.Date(0, 1, 1, 7, 32, 0, 123456789, LocalTime),
+		"arr": listdict.List{time.Date(2000, 2, 29, 0, 0, 0, 0, LocalDate)},
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Loads(dates) => %v, want %v", out, want)
+	}
+}
+
+var loadsErrorTests = []struct {
+	in  string
+	msg string
+}{
+	{"a = 1\na = 2\n", "Cannot overwrite a value (at line 2, column 1)"},
+	{"a.b = 1\na.b.c = 2\n", "Cannot overwrite a value (at line 2, column 1)"},
+	{"[a]\n[a]\n", "Cannot declare a twice (at line 2, column 1)"},
+	{"[x.y]\n[x]\n[x]\n", "Cannot declare x twice (at line 3, column 1)"},
+	{"[f]\napple.color = 1\n[f.apple]\n",
+		"Cannot declare f.apple twice (at line 3, column 1)"},
+	{"[a.b]\nc = 1\n[a]\nb.d = 2\n",
+		"Cannot redefine namespace a.b (at line 4, column 1)"},
+	{"[p]\nt = { n = 1 }\nt.e = 2\n",
+		"Cannot mutate immutable namespace p.t (at line 3, column 1)"},
+	{"a = []\n[[a]]\n", "Cannot mutate immutable namespace a (at line 2, column 1)"},
+	{"[a]\n[[a]]\n",
+		"An object other than list found behind this key (at line 2, column 1)"},
+	{"a = { b = 1, b = 2 }\n",
+		"Duplicate inline table key b (at line 1, column 14)"},
+	{"a = { b = 1, }\n",
+		"Invalid initial character for a key part (at line 1, column 14)"},
+	{"a = { b = 1,\n c = 2 }\n",
+		"Invalid initial character for a key part (at line 1, column 13)"},
+	{"a = [1 2]\n", "Unclosed array (at line 1, column 8)"},
+	{"a = [1, 2\n", "Unclosed array (at line 1, column 5)"},
+	{"a = [,]\n", "Invalid value (at line 1, column 6)"},
+	{"a = 01\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 6)"},
+	{"a = 1__2\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 6)"},
+	{"a = .7\n", "Invalid value (at line 1, column 5)"},
+	{"a = True\n", "Invalid value (at line 1, column 5)"},
+	{"a = 1979-02-30\n", "Invalid date or datetime (at line 1, column 5)"},
+	{"a = 07:32\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 6)"},
+	{"a = \"\\x41\"\n", "Unescaped '\\' in a string (at line 1, column 6)"},
+	{"a = \"\\uD800\"\n",
+		"Escaped character is not a Unicode scalar value (at line 1, column 8)"},
+	{"a = \"abc\ndef\"\n", "Unterminated string (at line 1, column 9)"},
+	{"a = 'abc\n", "Unterminated string (at line 1, column 9)"},
+	{"a = \"a\x01b\"\n", "Illegal character in a string (at line 1, column 7)"},
+	{"a = \"\"\"abc\n", "Unterminated string (at line 2, column 1)"},
+	{"a = 1 # x\x00y\n", "Illegal character in a comment (at line 1, column 10)"},
+	{"a = 1\rb = 2\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 6)"},
+	{"a 1\n", "Expected '=' after a key in a key/value pair (at line 1, column 3)"},
+	{"a = 1 b = 2\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 7)"},
+	{"= 1\n", "Invalid statement (at line 1, column 1)"},
+	{"[a\nb = 1\n",
+		"Expected ']' at the end of a table declaration (at line 1, column 3)"},
+	{"[[a]\n", "Expected ']]' at the end of an array declaration " +
+		"(at line 1, column 4)"},
+	{"[a] b = 1\n", "Expected newline or end of document after a statement " +
+		"(at line 1, column 5)"},
+	{"é = 1\n", "Invalid statement (at line 1, column 1)"},
+	{"a = \"\xff\"\n", "Invalid UTF-8 (at line 1, column 6)"},
+	{"a = " + strings.Repeat("[", container.MaxDepth+1),
+		"Maximum nesting depth exceeded (at line 1, column 10005)"},
+	{"a = " + strings.Repeat("{b = ", container.MaxDepth+1),
+		"Maximum nesting depth exceeded (at line 1, column 50005)"},
+}
+
+func TestLoadsErrors(t *testing.T) {
+	for index, lt := range loadsErrorTests {
+		_, err := Loads(lt.in)
+		var decodeError *DecodeError
+		if !errors.Is(err, ErrInvalidTOML) || !errors.As(err, &decodeError) ||
+			err.Error() != lt.msg {
+			t.Errorf("%d. Loads(%q) => %v, want %s", index, lt.in, err, lt.msg)
+		}
+	}
+	nested := "a = " + strings.Repeat("[{b = ", container.MaxDepth/2) + "1" +
+		strings.Repeat("}]", container.MaxDepth/2)
+	if _, err := Loads(nested); err != nil {
+		t.Errorf("Loads(nested %d times) => %v, want nil", container.MaxDepth, err)
+	}
+}
+
+//=============================================================================
+
+var dumpsTests = []struct {
+	in  interface{}
+	out string
+}{
+	{listdict.Dict{}, ""},
+	{listdict.Dict{"title": "TOML", "owner": listdict.Dict{"name": "Tom"},
+		"db": listdict.Dict{"ports": listdict.List{80, 443}, "on": true,
+			"temp": listdict.Dict{"cpu": 79.5}},
+		"servers": listdict.Dict{"a": listdict.Dict{"ip": "10.0.0.1"},
+			"b": listdict.Dict{}}},
+		"title = \"TOML\"\n\n[db]\non = true\nports = [80, 443]\n\n[db.temp]\n" +
+			"cpu = 79.5\n\n[owner]\nname = \"Tom\"\n\n[servers.a]\n" +
+			"ip = \"10.0.0.1\"\n\n[servers.b]\n"},
+	{listdict.Dict{"": 1, "a b": 2, "a.b": 3, "bare-key_1": 4, "ż": 5},
+		"\"\" = 1\n\"a b\" = 2\n\"a.b\" = 3\nbare-key_1 = 4\n\"ż\" = 5\n"},
+	{listdict.Dict{"s": "a\"b\\c\nd\te\x01\x7fé\U0001F600"},
+		"s = \"a\\\"b\\\\c\\nd\\te\\u0001\\u007fé\U0001F600\"\n"},
+	{listdict.Dict{"a": -17, "b": 1.0, "c": 1e16, "d": 1e-7, "e": math.Inf(-1),
+		"f": math.NaN(), "g": uint8(7), "h": float32(1.5)},
+		"a = -17\nb = 1.0\nc = 1e+16\nd = 1e-07\ne = -inf\nf = nan\ng = 7\nh = 1.5\n"},
+	{listdict.Dict{
+		"ld":  time.Date(1979, 5, 27, 0, 0, 0, 0, LocalDate),
+		"lt":  time.Date(0, 1, 1, 7, 32, 0, 999999000, LocalTime),
+		"ldt": time.Date(1979, 5, 27, 7, 32, 0, 5e8, LocalDateTime),
+		"odt": time.Date(1979, 5, 27, 7, 32, 0, 0, time.FixedZone("", -8*3600)),
+		"utc": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)},
+		"ld = 1979-05-27\nldt = 1979-05-27T07:32:00.5\nlt = 07:32:00.999999\n" +
+			"odt = 1979-05-27T07:32:00-08:00\nutc = 1979-05-27T07:32:00Z\n"},
+	{listdict.Dict{"p": listdict.List{listdict.Dict{"name": "Hammer"},
+		listdict.Dict{}, listdict.Dict{"sub": listdict.Dict{"x": 1},
+			"v": listdict.List{listdict.Dict{"n": 1}}}}},
+		"[[p]]\nname = \"Hammer\"\n\n[[p]]\n\n[[p]]\n\n[p.sub]\nx = 1\n\n" +
+			"[[p.v]]\nn = 1\n"},
+	{listdict.Dict{"arr": listdict.List{1, "a", listdict.Dict{"x": 1,
+		"y": listdict.List{1, 2}}, listdict.List{}, listdict.Dict{}}},
+		"arr = [1, \"a\", { x = 1, y = [1, 2] }, [], {}]\n"},
+	{listdict.Dict{"a": listdict.Dict{"b": listdict.Dict{"c": listdict.Dict{"d": 1}},
+		"e": 2}}, "[a]\ne = 2\n\n[a.b.c]\nd = 1\n"},
+	{map[string]interface{}{"b": []int{1, 2}, "a": map[string]string{"x": "y"}},
+		"b = [1, 2]\n\n[a]\nx = \"y\"\n"},
+	{listdict.Dict{"long": listdict.List{"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "cccccccccccccccccccccccccccccc",
+		listdict.List{1}}},
+		"long = [\n    \"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\",\n" +
+			"    \"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbb\",\n" +
+			"    \"cccccccccccccccccccccccccccccc\",\n    [1],\n]\n"},
+}
+
+func TestDumps(t *testing.T) {
+	for index, dt := range dumpsTests {
+		if out, err := Dumps(dt.in); out != dt.out || err != nil {
+			t.Errorf("%d. Dumps(%v) => %q, %v, want %q",
+				index, dt.in, out, err, dt.out)
+		}
+	}
+
+	ordered := listdict.NewOrderedDict()
+	ordered.Set("z", 1)
+	ordered.Set("a", listdict.Dict{"y": 2})
+	ordered.Set("m", 3)
+	if out, err := Dumps(ordered); out != "z = 1\nm = 3\n\n[a]\ny = 2\n" ||
+		err != nil {
+		t.Errorf("Dumps(OrderedDict) => %q, %v", out, err)
+	}
+}
+
+func TestDumpsErrors(t *testing.T) {
+	cycle := listdict.Dict{}
+	cycle["a"] = listdict.Dict{"b": cycle}
+	hashDict := listdict.NewHashDict()
+	hashDict.Set(1, "one")
+	tests := []struct {
+		in  interface{}
+		err error
+		msg string
+	}{
+		{nil, ErrUnrepresentable, "value can't be represented in TOML: <nil>"},
+		{listdict.List{1}, ErrUnrepresentable,
+			"value can't be represented in TOML: listdict.List"},
+		{listdict.Dict{"a": nil}, ErrUnrepresentable,
+			"value can't be represented in TOML: <nil> at a"},
+		{listdict.Dict{"s": listdict.List{listdict.Dict{"port": 1},
+			listdict.Dict{"port": nil}}}, ErrUnrepresentable,
+			"value can't be represented in TOML: <nil> at s[1].port"},
+		{listdict.Dict{"a b": listdict.Dict{"c": listdict.List{1, nil}}},
+			ErrUnrepresentable,
+			"value can't be represented in TOML: <nil> at \"a b\".c[1]"},
+		{listdict.Dict{"a": []byte("x")}, ErrUnrepresentable,
+			"value can't be represented in TOML: []uint8 at a"},
+		{listdict.Dict{"a": "\xff"}, ErrUnrepresentable,
+			"value can't be represented in TOML: string at a"},
+		{listdict.Dict{"a": hashDict}, ErrInvalidKey, "keys must be str: int at a"},
+		{map[int]int{1: 1}, ErrInvalidKey, "keys must be str: map[int]int"},
+		{cycle, ErrCircularReference,
+			"circular reference detected: listdict.Dict at a.b"},
+	}
+	for index, dt := range tests {
+		_, err := Dumps(dt.in)
+		var encodeError *EncodeError
+		if !errors.Is(err, dt.err) || !errors.As(err, &encodeError) ||
+			err.Error() != dt.msg {
+			t.Errorf("%d. Dumps(%v) => %v, want %s", index, dt.in, err, dt.msg)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for index, lt := range loadsTests {
+		value, _ := Loads(lt.in)
+		out, err := Dumps(value)
+		if err != nil {
+			t.Errorf("%d. Dumps(%s) => %v", index, lt.repr, err)
+			continue
+		}
+		back, err := Loads(out)
+		if !reflect.DeepEqual(back, value) || err != nil {
+			t.Errorf("%d. Loads(Dumps(%s)) => %s, %v\n%s", index, lt.repr,
+				listdict.Repr(back), err, out)
+		}
+	}
+}
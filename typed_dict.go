@@ -0,0 +1,194 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypedDict is a Dict with keys of type K and values of type V.
+//
+//	d := listdict.TypedDict[string, int]{"one": 1, "two": 2}
+//	n := d.Get("three", 3) // n is int, no type assertion needed
+type TypedDict[K comparable, V any] map[K]V
+
+// Pair is a key-value pair of TypedDict.
+type Pair[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// NewTypedDict returns new TypedDict.
+func NewTypedDict[K comparable, V any]() TypedDict[K, V] {
+	return make(TypedDict[K, V])
+}
+
+// TypedDictFromKeys creates a new dictionary with keys from list and values
+// set to defaultVal.
+func TypedDictFromKeys[K comparable, V any](list TypedList[K],
+	defaultVal V) TypedDict[K, V] {
+
+	newDict := NewTypedDict[K, V]()
+	for _, key := range list {
+		newDict[key] = defaultVal
+	}
+	return newDict
+}
+
+// ToTypedDict converts Dict to TypedDict. It returns *TypeError if any
+// value of the dictionary is not of type V.
+func ToTypedDict[V any](dict Dict) (TypedDict[string, V], error) {
+	out := make(TypedDict[string, V], len(dict))
+	for key, val := range dict {
+		typed, err := toType[V](val)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = typed
+	}
+	return out, nil
+}
+
+//=============================================================================
+
+// Clear removes all elements from the dictionary.
+func (dict TypedDict[K, V]) Clear() {
+	clear(dict)
+}
+
+// Get returns value for the given key or defaultVal if key is NOT in
+// the dictionary.
+func (dict TypedDict[K, V]) Get(key K, defaultVal V) V {
+	if val, ok := dict[key]; ok {
+		return val
+	}
+	return defaultVal
+}
+
+// HasKey returns true if key is in the dictionary, false otherwise.
+func (dict TypedDict[K, V]) HasKey(key K) bool {
+	_, ok := dict[key]
+	return ok
+}
+
+// IsEqual returns true if dicts are equal.
+func (dict TypedDict[K, V]) IsEqual(otherDict TypedDict[K, V]) bool {
+	return reflect.DeepEqual(dict, otherDict)
+}
+
+// Items returns an unordered list of the dictionary's key-value pairs.
+func (dict TypedDict[K, V]) Items() []Pair[K, V] {
+	items := make([]Pair[K, V], 0, len(dict))
+	for key, value := range dict {
+		items = append(items, Pair[K, V]{key, value})
+	}
+	return items
+}
+
+// Keys returns a list of the dictionary's keys, unordered.
+func (dict TypedDict[K, V]) Keys() TypedList[K] {
+	list := make(TypedList[K], 0, len(dict))
+	for key := range dict {
+		list = append(list, key)
+	}
+	return list
+}
+
+// Pop returns value and remove the given key from the dictionary.
+// If the given key is NOT in the dictionary return defaultVal.
+func (dict TypedDict[K, V]) Pop(key K, defaultVal V) (V, error) {
+	if len(dict) <= 0 {
+		return defaultVal, &KeyError{Key: key, Err: ErrRemoveFromEmptyDict}
+	}
+	if val, ok := dict[key]; ok {
+		delete(dict, key)
+		return val, nil
+	}
+	return defaultVal, nil
+}
+
+// PopItem return and remove a random key-value pair from the dictionary.
+func (dict TypedDict[K, V]) PopItem() (Pair[K, V], error) {
+	return dict.PopItemRand(nil)
+}
+
+// PopItemRand is the same as PopItem but the key is chosen using r.
+// If r is nil the default source is used (see Rand).
+func (dict TypedDict[K, V]) PopItemRand(r Rand) (Pair[K, V], error) {
+	if len(dict) <= 0 {
+		return Pair[K, V]{}, &KeyError{Err: ErrRemoveFromEmptyDict}
+	}
+
+	// Take the n-th smallest key so the same r gives the same key. Keys
+	// that can't be ordered are ordered by their repr, as Repr does.
+	keys := dict.Keys()
+	n := randOrDefault(r).Intn(len(keys))
+	var err error
+	key := selectNth(keys, n, func(a, b K) int {
+		c, cmpErr := Compare(a, b)
+		if cmpErr != nil {
+			err = cmpErr
+		}
+		return c
+	})
+	if err != nil {
+		type reprKey struct {
+			repr string
+			key  K
+		}
+		reprKeys := make([]reprKey, len(keys))
+		for i, key := range keys {
+			reprKeys[i] = reprKey{Repr(key), key}
+		}
+		key = selectNth(reprKeys, n, func(a, b reprKey) int {
+			return strings.Compare(a.repr, b.repr)
+		}).key
+	}
+	pair := Pair[K, V]{key, dict[key]}
+	delete(dict, key)
+
+	return pair, nil
+}
+
+// SetDefault is like Get but will set dict[key] to defaultVal if key is not
+// already in dict.
+func (dict TypedDict[K, V]) SetDefault(key K, defaultVal V) V {
+	if val, ok := dict[key]; ok {
+		return val
+	}
+	dict[key] = defaultVal
+	return defaultVal
+}
+
+// Update updates the dictionary with the key-value pairs in the dict2
+// dictionary replacing current values and adding new if found.
+func (dict TypedDict[K, V]) Update(dict2 TypedDict[K, V]) {
+	for key, value := range dict2 {
+		dict[key] = value
+	}
+}
+
+// Untyped returns the dictionary as Dict. Keys are converted to strings
+// the same way as in DictFromKeys.
+func (dict TypedDict[K, V]) Untyped() Dict {
+	out := make(Dict, len(dict))
+	for key, val := range dict {
+		out[fmt.Sprintf("%v", key)] = val
+	}
+	return out
+}
+
+// Values returns a list of the dictionary's values, unordered.
+func (dict TypedDict[K, V]) Values() TypedList[V] {
+	list := make(TypedList[V], 0, len(dict))
+	for _, value := range dict {
+		list = append(list, value)
+	}
+	return list
+}
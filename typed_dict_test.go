@@ -0,0 +1,92 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+//=============================================================================
+
+func TestToTypedDict(t *testing.T) {
+	typed, err := ToTypedDict[int](Dict{"one": 1, "two": 2})
+	want := TypedDict[string, int]{"one": 1, "two": 2}
+	if err != nil || !typed.IsEqual(want) {
+		t.Errorf("ToTypedDict[int]() => %v, %v, want %v", typed, err, want)
+	}
+
+	_, err = ToTypedDict[int](Dict{"one": "1"})
+	if !errors.Is(err, ErrWrongType) {
+		t.Errorf("ToTypedDict[int]({one: \"1\"}) => %v, want %v",
+			err, ErrWrongType)
+	}
+}
+
+func TestTypedDictUntyped(t *testing.T) {
+	dict := TypedDict[int, string]{1: "one", 2: "two"}.Untyped()
+	if want := (Dict{"1": "one", "2": "two"}); !dict.IsEqual(want) {
+		t.Errorf("Untyped() => %v, want %v", dict, want)
+	}
+}
+
+//=============================================================================
+
+func TestTypedDictMethods(t *testing.T) {
+	dict := TypedDictFromKeys(TypedList[string]{"one", "two"}, 0)
+	dict.Update(TypedDict[string, int]{"two": 2, "three": 3})
+
+	if val := dict.Get("two", 5); val != 2 {
+		t.Errorf("%v.Get(two, 5) => %v, want 2", dict, val)
+	}
+	if val := dict.Get("four", 4); val != 4 || dict.HasKey("four") {
+		t.Errorf("%v.Get(four, 4) => %v, want 4 and no key", dict, val)
+	}
+	if val := dict.SetDefault("four", 4); val != 4 || !dict.HasKey("four") {
+		t.Errorf("%v.SetDefault(four, 4) => %v, want 4 and key", dict, val)
+	}
+	if val, err := dict.Pop("four", 0); val != 4 || err != nil {
+		t.Errorf("%v.Pop(four, 0) => %v, %v, want 4, nil", dict, val, err)
+	}
+
+	keys := dict.Keys()
+	sort.Strings(keys)
+	if want := (TypedList[string]{"one", "three", "two"}); !keys.IsEqual(want) {
+		t.Errorf("%v.Keys() => %v, want %v", dict, keys, want)
+	}
+
+	values := dict.Values()
+	sort.Ints(values)
+	if want := (TypedList[int]{0, 2, 3}); !values.IsEqual(want) {
+		t.Errorf("%v.Values() => %v, want %v", dict, values, want)
+	}
+
+	items := dict.Items()
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	wantItems := []Pair[string, int]{{"one", 0}, {"three", 3}, {"two", 2}}
+	if !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("%v.Items() => %v, want %v", dict, items, wantItems)
+	}
+
+	dict = TypedDict[string, int]{"one": 1}
+	pair, err := dict.PopItem()
+	if err != nil || pair != (Pair[string, int]{"one", 1}) || len(dict) != 0 {
+		t.Errorf("PopItem() => %v, %v, dict %v", pair, err, dict)
+	}
+	if _, err := dict.PopItem(); !errors.Is(err, ErrRemoveFromEmptyDict) {
+		t.Errorf("PopItem() on empty dict => %v, want %v",
+			err, ErrRemoveFromEmptyDict)
+	}
+
+	dict = TypedDict[string, int]{"one": 1}
+	dict.Clear()
+	if len(dict) != 0 {
+		t.Errorf("Clear() => %v, want empty dict", dict)
+	}
+}
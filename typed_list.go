@@ -0,0 +1,247 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypedList is a List which elements all have type T.
+//
+//	l := listdict.TypedList[int]{3, 1, 2}
+//	last, _ := l.Pop() // last is int, no type assertion needed
+type TypedList[T any] []T
+
+// NewTypedList returns new TypedList with specified length.
+func NewTypedList[T any](length int) TypedList[T] {
+	return make(TypedList[T], length)
+}
+
+var (
+	// ErrWrongType is returned when user want to convert value to
+	// a different type than it has
+	ErrWrongType = errors.New("wrong type")
+)
+
+// ToTypedList converts List to TypedList. It returns *TypeError if any
+// element of the list is not of type T.
+func ToTypedList[T any](list List) (TypedList[T], error) {
+	out := make(TypedList[T], len(list))
+	for i, val := range list {
+		typed, err := toType[T](val)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = typed
+	}
+	return out, nil
+}
+
+// toType returns value as T. nil is accepted only if T can be nil.
+func toType[T any](value interface{}) (T, error) {
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+	var zero T
+	if value == nil {
+		switch reflect.TypeOf(&zero).Elem().Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map,
+			reflect.Chan, reflect.Func:
+			return zero, nil
+		}
+	}
+	return zero, &TypeError{Values: []interface{}{value}, Err: ErrWrongType}
+}
+
+//=============================================================================
+
+// Append adds an element to the end of the list.
+func (list *TypedList[T]) Append(values ...T) {
+	*list = append(*list, values...)
+}
+
+// AppendIfMissing adds an element to the end of the list if it's not already
+// in the list. Elements are compared with Equal.
+func (list *TypedList[T]) AppendIfMissing(value T) {
+	if list.Count(value) == 0 {
+		*list = append(*list, value)
+	}
+}
+
+// At returns element with given index. Negative index counts from the end
+// of the list.
+func (list TypedList[T]) At(index int) (T, error) {
+	i, ok := normIndex(index, len(list))
+	if !ok {
+		var zero T
+		return zero, &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	return list[i], nil
+}
+
+// Count returns the number of times value appears in the list.
+// Elements are compared with Equal.
+func (list TypedList[T]) Count(value T) int {
+	counter := 0
+	for _, listValue := range list {
+		if Equal(listValue, value) {
+			counter++
+		}
+	}
+	return counter
+}
+
+// Delete removes element with given index from the list.
+// Negative index counts from the end of the list.
+func (list *TypedList[T]) Delete(index int) error {
+	return deleteIndex(list, index)
+}
+
+// DeleteSlice removes elements selected by slice start:stop:step from
+// the list. Use Omit for values that were left out.
+func (list *TypedList[T]) DeleteSlice(start, stop, step int) error {
+	return deleteSlice(list, start, stop, step)
+}
+
+// Extend one list with the contents of the other list.
+func (list *TypedList[T]) Extend(otherList TypedList[T]) {
+	*list = append(*list, otherList...)
+}
+
+// Get returns element with given index or defaultVal if index is outside
+// the list. Negative index counts from the end of the list.
+func (list TypedList[T]) Get(index int, defaultVal T) T {
+	if i, ok := normIndex(index, len(list)); ok {
+		return list[i]
+	}
+	return defaultVal
+}
+
+// Index returns the index of the first item in the list whose value is val.
+// It is -1 and *ValueError if there is no such item.
+func (list TypedList[T]) Index(val T) (int, error) {
+	for index, listValue := range list {
+		if Equal(listValue, val) {
+			return index, nil
+		}
+	}
+	return -1, &ValueError{Value: val, Err: ErrNotInList}
+}
+
+// Insert an element at a given position. If the position is past the end
+// of the list, append to the end. Negative position counts from the end
+// of the list.
+func (list *TypedList[T]) Insert(index int, values ...T) {
+	insertAt(list, index, values...)
+}
+
+// IsEqual returns true if lists are equal.
+func (list TypedList[T]) IsEqual(otherList TypedList[T]) bool {
+	return reflect.DeepEqual(list, otherList)
+}
+
+// Remove and returns the last element in the list.
+func (list *TypedList[T]) Pop() (T, error) {
+	return list.PopItem(-1)
+}
+
+// Remove and returns the element at the given position in the list.
+// Negative position counts from the end of the list.
+func (list *TypedList[T]) PopItem(index int) (T, error) {
+	var zero T
+	if len(*list) <= 0 {
+		return zero, &IndexError{Index: index, Err: ErrRemoveFromEmptyList}
+	}
+
+	val, err := list.At(index)
+	if err != nil {
+		return zero, err
+	}
+	list.Delete(index)
+
+	return val, nil
+}
+
+// Remove the first element from the list whose value matches the given value.
+// *ValueError if no match is found.
+func (list *TypedList[T]) Remove(val T) error {
+	index, err := list.Index(val)
+	if err != nil {
+		return err
+	}
+	return list.Delete(index)
+}
+
+// Reverse the elements of the list in place.
+func (list *TypedList[T]) Reverse() {
+	reverse(*list)
+}
+
+// Set replaces element with given index. Negative index counts from the end
+// of the list.
+func (list TypedList[T]) Set(index int, value T) error {
+	i, ok := normIndex(index, len(list))
+	if !ok {
+		return &IndexError{Index: index, Err: ErrIndexOutOfRange}
+	}
+	list[i] = value
+	return nil
+}
+
+// SetSlice replaces elements selected by slice start:stop:step with values,
+// see List.SetSlice.
+func (list *TypedList[T]) SetSlice(start, stop, step int,
+	values TypedList[T]) error {
+
+	return setSlice(list, start, stop, step, values)
+}
+
+// Slice returns a new list with elements selected by slice
+// start:stop:step, see List.Slice.
+func (list TypedList[T]) Slice(start, stop, step int) (TypedList[T], error) {
+	return sliceOf(list, start, stop, step)
+}
+
+// Sort the list in place ordering elements from smallest to largest,
+// see List.Sort.
+func (list *TypedList[T]) Sort(key func(T) interface{}, reverse bool) error {
+	sorted, err := sortedBy(*list, key, reverse)
+	if err != nil {
+		return err
+	}
+	copy(*list, sorted)
+	return nil
+}
+
+// Sorted returns a new sorted list, same as Sort but without changing
+// the list.
+func (list TypedList[T]) Sorted(key func(T) interface{}, reverse bool) (
+	TypedList[T], error) {
+
+	return sortedBy(list, key, reverse)
+}
+
+// String returns list values as string, same as List.String.
+func (list TypedList[T]) String() string {
+	out := make([]string, len(list))
+	for i, val := range list {
+		out[i] = fmt.Sprintf("%v", val)
+	}
+	return strings.Join(out, ", ")
+}
+
+// Untyped returns the list as List.
+func (list TypedList[T]) Untyped() List {
+	out := NewList(len(list))
+	for i, val := range list {
+		out[i] = val
+	}
+	return out
+}
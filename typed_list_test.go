@@ -0,0 +1,104 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+//=============================================================================
+
+func TestToTypedList(t *testing.T) {
+	typed, err := ToTypedList[string](List{"one", "two"})
+	if err != nil || !reflect.DeepEqual(typed, TypedList[string]{"one", "two"}) {
+		t.Errorf("ToTypedList[string]([one two]) => %v, %v", typed, err)
+	}
+
+	_, err = ToTypedList[string](List{"one", 2})
+	if !errors.Is(err, ErrWrongType) {
+		t.Errorf("ToTypedList[string]([one 2]) => error %v, want %v",
+			err, ErrWrongType)
+	}
+
+	_, err = ToTypedList[int](List{1, nil})
+	if !errors.Is(err, ErrWrongType) {
+		t.Errorf("ToTypedList[int]([1 nil]) => error %v, want %v",
+			err, ErrWrongType)
+	}
+
+	lists, err := ToTypedList[List](List{List{1}, nil})
+	if err != nil || !reflect.DeepEqual(lists, TypedList[List]{List{1}, nil}) {
+		t.Errorf("ToTypedList[List]([[1] nil]) => %v, %v", lists, err)
+	}
+}
+
+func TestTypedListUntyped(t *testing.T) {
+	list := TypedList[int]{1, 2}.Untyped()
+	if !reflect.DeepEqual(list, List{1, 2}) {
+		t.Errorf("TypedList[int]{1, 2}.Untyped() => %v, want [1 2]", list)
+	}
+}
+
+//=============================================================================
+
+func TestTypedListMethods(t *testing.T) {
+	list := NewTypedList[int](0)
+	list.Append(3, 1, 2)
+	list.AppendIfMissing(1)
+	list.AppendIfMissing(4)
+	list.Insert(-1, 0)
+	if want := (TypedList[int]{3, 1, 2, 0, 4}); !list.IsEqual(want) {
+		t.Fatalf("list => %v, want %v", list, want)
+	}
+
+	if val, err := list.Pop(); val != 4 || err != nil {
+		t.Errorf("%v.Pop() => %v, %v, want 4, nil", list, val, err)
+	}
+	if val, err := list.PopItem(0); val != 3 || err != nil {
+		t.Errorf("%v.PopItem(0) => %v, %v, want 3, nil", list, val, err)
+	}
+	if _, err := list.PopItem(5); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("%v.PopItem(5) => %v, want %v",
+			list, err, ErrIndexOutOfRange)
+	}
+	if index, err := list.Index(2); index != 1 || err != nil {
+		t.Errorf("%v.Index(2) => %v, %v, want 1, nil", list, index, err)
+	}
+	if err := list.Remove(7); !errors.Is(err, ErrNotInList) {
+		t.Errorf("%v.Remove(7) => %v, want %v", list, err, ErrNotInList)
+	}
+	if val := list.Get(-1, 9); val != 0 {
+		t.Errorf("%v.Get(-1, 9) => %v, want 0", list, val)
+	}
+
+	if err := list.Sort(nil, false); err != nil {
+		t.Fatalf("%v.Sort() => %v", list, err)
+	}
+	if want := (TypedList[int]{0, 1, 2}); !list.IsEqual(want) {
+		t.Errorf("Sort() => %v, want %v", list, want)
+	}
+
+	reversed, _ := list.Slice(Omit, Omit, -1)
+	if want := (TypedList[int]{2, 1, 0}); !reversed.IsEqual(want) {
+		t.Errorf("%v.Slice(::-1) => %v, want %v", list, reversed, want)
+	}
+
+	list.SetSlice(1, 2, 1, TypedList[int]{7, 8})
+	list.Reverse()
+	if want := (TypedList[int]{2, 8, 7, 0}); !list.IsEqual(want) {
+		t.Errorf("list => %v, want %v", list, want)
+	}
+	list.DeleteSlice(Omit, Omit, 2)
+	if want := (TypedList[int]{8, 0}); !list.IsEqual(want) {
+		t.Errorf("DeleteSlice(::2) => %v, want %v", list, want)
+	}
+	if list.String() != "8, 0" {
+		t.Errorf("%v.String() => %q, want \"8, 0\"", list, list.String())
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"iter"
+)
+
+// KeysView is a live view of Dict keys, same as Python's dict_keys.
+// It reflects later changes of the Dict and works as AbstractSet.
+//
+//	a := listdict.Dict{"x": 1, "y": 2}
+//	b := listdict.Dict{"y": 3, "z": 4}
+//	a.KeysView().Intersection(b.KeysView()) // {y}
+type KeysView struct {
+	dict Dict
+}
+
+// ValuesView is a live view of Dict values, same as Python's dict_values.
+// Unlike KeysView it's not a set, values can repeat.
+type ValuesView struct {
+	dict Dict
+}
+
+// ItemsView is a live view of Dict key-value pairs as Tuples{key, value},
+// same as Python's dict_items. It works as AbstractSet, but items with
+// unhashable values are left out when it's used by Set methods.
+type ItemsView struct {
+	dict Dict
+}
+
+//=============================================================================
+
+// KeysView returns a live view of the dictionary's keys.
+func (dict Dict) KeysView() KeysView {
+	return KeysView{dict}
+}
+
+// ValuesView returns a live view of the dictionary's values.
+func (dict Dict) ValuesView() ValuesView {
+	return ValuesView{dict}
+}
+
+// ItemsView returns a live view of the dictionary's key-value pairs.
+func (dict Dict) ItemsView() ItemsView {
+	return ItemsView{dict}
+}
+
+//=============================================================================
+
+// Contains returns true if value is a key of the Dict.
+func (view KeysView) Contains(value interface{}) bool {
+	key, ok := value.(string)
+	return ok && view.dict.HasKey(key)
+}
+
+// Difference returns a new Set with keys that are not in the others.
+func (view KeysView) Difference(others ...AbstractSet) *Set {
+	return &Set{items: setDifference(view, others)}
+}
+
+// Intersection returns a new Set with keys found in all the others.
+func (view KeysView) Intersection(others ...AbstractSet) *Set {
+	return &Set{items: setIntersection(view, others)}
+}
+
+// IsDisjoint returns true if no key is in other.
+func (view KeysView) IsDisjoint(other AbstractSet) bool {
+	if keys, ok := other.(KeysView); ok {
+		for key := range view.dict {
+			if keys.dict.HasKey(key) {
+				return false
+			}
+		}
+		return true
+	}
+	return isDisjoint(view, other)
+}
+
+// IsEqual returns true if the keys and other have the same elements.
+func (view KeysView) IsEqual(other AbstractSet) bool {
+	return view.Len() == other.Len() && view.IsSubset(other)
+}
+
+// IsSubset returns true if every key is in other. Keys of two Dicts are
+// compared without copying them.
+func (view KeysView) IsSubset(other AbstractSet) bool {
+	if keys, ok := other.(KeysView); ok {
+		if len(view.dict) > len(keys.dict) {
+			return false
+		}
+		for key := range view.dict {
+			if !keys.dict.HasKey(key) {
+				return false
+			}
+		}
+		return true
+	}
+	return isSubset(view, other)
+}
+
+// IsSuperset returns true if every element of other is a key.
+func (view KeysView) IsSuperset(other AbstractSet) bool {
+	if keys, ok := other.(KeysView); ok {
+		return keys.IsSubset(view)
+	}
+	return isSubset(other, view)
+}
+
+// Iter returns an iterator over keys of the Dict, unordered.
+func (view KeysView) Iter() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for key := range view.dict {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of keys in the Dict.
+func (view KeysView) Len() int {
+	return len(view.dict)
+}
+
+// SymmetricDifference returns a new Set with elements in either the keys
+// or other but not in both.
+func (view KeysView) SymmetricDifference(other AbstractSet) *Set {
+	return &Set{items: setSymmetricDifference(view, other)}
+}
+
+// Union returns a new Set with keys and elements of all the others.
+func (view KeysView) Union(others ...AbstractSet) *Set {
+	return &Set{items: setUnion(view, others...)}
+}
+
+//=============================================================================
+
+// Contains returns true if value is equal to any value of the Dict.
+// Values are compared with Equal.
+func (view ValuesView) Contains(value interface{}) bool {
+	for _, val := range view.dict {
+		if Equal(val, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Iter returns an iterator over values of the Dict, unordered.
+func (view ValuesView) Iter() iter.Seq[interface{}] {
+	return view.dict.IterValues()
+}
+
+// Len returns the number of values in the Dict.
+func (view ValuesView) Len() int {
+	return len(view.dict)
+}
+
+//=============================================================================
+
+// Contains returns true if value is a Tuple{key, value} and the Dict has
+// the key with value equal to it.
+func (view ItemsView) Contains(value interface{}) bool {
+	item, ok := value.(Tuple)
+	if !ok || len(item) != 2 {
+		return false
+	}
+	key, ok := item[0].(string)
+	if !ok {
+		return false
+	}
+	val, ok := view.dict[key]
+	return ok && Equal(val, item[1])
+}
+
+// Difference returns a new Set with items that are not in the others.
+// It returns *TypeError if any value or element of others is unhashable.
+func (view ItemsView) Difference(others ...AbstractSet) (*Set, error) {
+	if err := view.checkHashable(others...); err != nil {
+		return nil, err
+	}
+	return &Set{items: setDifference(view, others)}, nil
+}
+
+// Intersection returns a new Set with items found in all the others.
+// It returns *TypeError if any value is unhashable.
+func (view ItemsView) Intersection(others ...AbstractSet) (*Set, error) {
+	if err := view.checkHashable(); err != nil {
+		return nil, err
+	}
+	return &Set{items: setIntersection(view, others)}, nil
+}
+
+// IsDisjoint returns true if no item is in other.
+func (view ItemsView) IsDisjoint(other AbstractSet) bool {
+	return isDisjoint(view, other)
+}
+
+// IsEqual returns true if the items and other have the same elements.
+func (view ItemsView) IsEqual(other AbstractSet) bool {
+	return view.Len() == other.Len() && isSubset(view, other)
+}
+
+// IsSubset returns true if every item is in other.
+func (view ItemsView) IsSubset(other AbstractSet) bool {
+	return isSubset(view, other)
+}
+
+// IsSuperset returns true if every element of other is an item.
+func (view ItemsView) IsSuperset(other AbstractSet) bool {
+	return isSubset(other, view)
+}
+
+// Iter returns an iterator over items of the Dict as Tuple{key, value},
+// unordered.
+func (view ItemsView) Iter() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for key, val := range view.dict {
+			if !yield(Tuple{key, val}) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of items in the Dict.
+func (view ItemsView) Len() int {
+	return len(view.dict)
+}
+
+// SymmetricDifference returns a new Set with elements in either the items
+// or other but not in both. It returns *TypeError if any value or element
+// of other is unhashable.
+func (view ItemsView) SymmetricDifference(other AbstractSet) (*Set, error) {
+	if err := view.checkHashable(other); err != nil {
+		return nil, err
+	}
+	return &Set{items: setSymmetricDifference(view, other)}, nil
+}
+
+// Union returns a new Set with items and elements of all the others.
+// It returns *TypeError if any value or element of others is unhashable.
+func (view ItemsView) Union(others ...AbstractSet) (*Set, error) {
+	if err := view.checkHashable(others...); err != nil {
+		return nil, err
+	}
+	return &Set{items: setUnion(view, others...)}, nil
+}
+
+// checkHashable returns *TypeError for the first unhashable value or
+// element of others, same as Python.
+func (view ItemsView) checkHashable(others ...AbstractSet) error {
+	for _, val := range view.dict {
+		if _, err := hashKey(val); err != nil {
+			return err
+		}
+	}
+	for _, other := range others {
+		switch other.(type) {
+		case *Set, FrozenSet, KeysView:
+			continue // elements are always hashable
+		}
+		for value := range other.Iter() {
+			if _, err := hashKey(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package listdict
+
+import (
+	"errors"
+	"testing"
+)
+
+//=============================================================================
+
+func TestKeysView(t *testing.T) {
+	dict := Dict{"a": 1, "b": 2}
+	keys := dict.KeysView()
+
+	dict["c"] = 3
+	delete(dict, "a")
+	if keys.Len() != 2 || keys.Contains("a") || !keys.Contains("c") {
+		t.Errorf("KeysView doesn't reflect changes of %v", dict)
+	}
+	if keys.Contains(1) {
+		t.Errorf("Contains(1) => true, want false")
+	}
+
+	other := Dict{"c": 0, "d": 0}.KeysView()
+	set := newTestSet(t, "b", "x")
+	var viewTests = []struct {
+		name string
+		out  *Set
+		want List
+	}{
+		{"Union", keys.Union(other), List{"b", "c", "d"}},
+		{"Intersection", keys.Intersection(other), List{"c"}},
+		{"Difference", keys.Difference(other), List{"b"}},
+		{"SymmetricDifference", keys.SymmetricDifference(set),
+			List{"c", "x"}},
+		{"Set.Intersection", set.Intersection(keys), List{"b"}},
+	}
+	for index, vt := range viewTests {
+		want, _ := SetFromList(vt.want)
+		if !vt.out.IsEqual(want) {
+			t.Errorf("%d. %s => %v, want %v", index, vt.name, vt.out, want)
+		}
+	}
+
+	same := Dict{"b": 0, "c": 0}.KeysView()
+	if !keys.IsEqual(same) || !Equal(keys, same) || keys.IsEqual(other) {
+		t.Errorf("IsEqual(%v) wrong", same.dict)
+	}
+	if !keys.IsSubset(Dict{"b": 0, "c": 0, "d": 0}.KeysView()) ||
+		!keys.IsSuperset(newTestSet(t, "b")) || keys.IsDisjoint(other) ||
+		!keys.IsDisjoint(Dict{"z": 0}.KeysView()) {
+		t.Errorf("IsSubset/IsSuperset/IsDisjoint wrong for %v", dict)
+	}
+}
+
+func TestKeysViewIsEqualAllocs(t *testing.T) {
+	a, b := NewDict(), NewDict()
+	for _, key := range []string{"one", "two", "three", "four"} {
+		a[key], b[key] = 1, 2
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		a.KeysView().IsEqual(b.KeysView())
+	})
+	if allocs != 0 {
+		t.Errorf("KeysView.IsEqual() => %v allocations, want 0", allocs)
+	}
+}
+
+func TestValuesView(t *testing.T) {
+	dict := Dict{"a": 1, "b": 1}
+	values := dict.ValuesView()
+
+	dict["c"] = List{2}
+	if values.Len() != 3 || !values.Contains(1.0) ||
+		!values.Contains(List{2}) || values.Contains(3) {
+		t.Errorf("ValuesView doesn't reflect changes of %v", dict)
+	}
+	if n := CollectList(values.Iter()).Count(1); n != 2 {
+		t.Errorf("Iter() => %d values 1, want 2", n)
+	}
+	if Equal(values, values) || Equal(dict.KeysView(), values) {
+		t.Errorf("Equal(ValuesView, ...) => true, want false")
+	}
+}
+
+func TestItemsView(t *testing.T) {
+	dict := Dict{"a": 1, "b": 2}
+	items := dict.ItemsView()
+
+	if !items.Contains(Tuple{"a", 1.0}) || items.Contains(Tuple{"a", 2}) ||
+		items.Contains(List{"a", 1}) {
+		t.Errorf("Contains() wrong for %v", dict)
+	}
+
+	other, _ := SetFromList(List{Tuple{"b", 2}, Tuple{"c", 3}})
+	union, err := items.Union(other)
+	if err != nil || union.Len() != 3 {
+		t.Errorf("Union(%v) => %v, %v, want 3 items", other, union, err)
+	}
+	common, err := items.Intersection(other)
+	if err != nil || !common.Contains(Tuple{"b", 2}) || common.Len() != 1 {
+		t.Errorf("Intersection(%v) => %v, %v, want {(b, 2)}", other,
+			common, err)
+	}
+	if !items.IsSuperset(other.Difference(newTestSet(t, Tuple{"c", 3}))) {
+		t.Errorf("IsSuperset() => false, want true")
+	}
+
+	dict["c"] = List{}
+	if _, err := items.Union(other); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Union() with unhashable value => %v, want %v", err,
+			ErrUnhashable)
+	}
+
+	delete(dict, "c")
+	unhashable := Dict{"c": List{}}.ItemsView()
+	if _, err := items.Union(unhashable); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Union() with unhashable other => %v, want %v", err,
+			ErrUnhashable)
+	}
+	if _, err := items.Difference(unhashable); !errors.Is(err, ErrUnhashable) {
+		t.Errorf("Difference() with unhashable other => %v, want %v", err,
+			ErrUnhashable)
+	}
+	if _, err := items.SymmetricDifference(unhashable); !errors.Is(err,
+		ErrUnhashable) {
+		t.Errorf("SymmetricDifference() with unhashable other => %v, want %v",
+			err, ErrUnhashable)
+	}
+	if common, err := items.Intersection(unhashable); err != nil ||
+		common.Len() != 0 {
+		t.Errorf("Intersection() with unhashable other => %v, %v, want {}",
+			common, err)
+	}
+}
@@ -0,0 +1,415 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package yaml
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/gosimple/listdict"
+)
+
+const (
+	tagStr    = defaultTagPrefix + "str"
+	tagInt    = defaultTagPrefix + "int"
+	tagFloat  = defaultTagPrefix + "float"
+	tagBool   = defaultTagPrefix + "bool"
+	tagNull   = defaultTagPrefix + "null"
+	tagBinary = defaultTagPrefix + "binary"
+	tagSeq    = defaultTagPrefix + "seq"
+	tagMap    = defaultTagPrefix + "map"
+	tagSet    = defaultTagPrefix + "set"
+	tagOmap   = defaultTagPrefix + "omap"
+	tagMerge  = defaultTagPrefix + "merge"
+)
+
+// decoder turns nodes of a single document into values.
+type decoder struct {
+	parser  *parser
+	options *LoadOptions
+	values  map[*node]interface{}        // values of anchored nodes
+	pairs   map[*node]*listdict.HashDict // pairs of mappings, for merging
+}
+
+// decodeDocuments returns values of documents parsed by p.
+func decodeDocuments(p *parser, docs []*node, options *LoadOptions) (
+	listdict.List, error) {
+
+	if options == nil {
+		options = &LoadOptions{}
+	}
+	out := make(listdict.List, len(docs))
+	for i, doc := range docs {
+		d := &decoder{parser: p, options: options,
+			values: make(map[*node]interface{}),
+			pairs:  make(map[*node]*listdict.HashDict)}
+		var err error
+		if out[i], err = d.decode(doc); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (d *decoder) error(n *node, err error, msg string) error {
+	return d.parser.fail(err, msg, n.pos)
+}
+
+func (d *decoder) decode(n *node) (interface{}, error) {
+	if n.kind == aliasNode {
+		n = n.target
+	}
+	if value, ok := d.values[n]; ok {
+		return value, nil
+	}
+
+	var value interface{}
+	var err error
+	switch n.kind {
+	case scalarNode:
+		value, err = d.scalar(n)
+	case sequenceNode:
+		value, err = d.sequence(n)
+	default:
+		value, err = d.mapping(n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n.anchor != "" {
+		d.values[n] = value
+	}
+	return value, nil
+}
+
+// custom returns value of node with unknown tag, made by TagHook.
+func (d *decoder) custom(n *node, value interface{}) (interface{}, error) {
+	if d.options.TagHook == nil {
+		return nil, d.error(n, ErrUnknownTag, fmt.Sprintf(
+			"could not determine a constructor for the tag %q", n.tag))
+	}
+	value, err := d.options.TagHook(n.tag, value)
+	if err != nil {
+		return nil, d.error(n, err, fmt.Sprintf("tag %q", n.tag))
+	}
+	return value, nil
+}
+
+func (d *decoder) scalar(n *node) (interface{}, error) {
+	value := resolve(n.value)
+	switch n.tag {
+	case "":
+		if n.plain {
+			return value, nil
+		}
+		return n.value, nil
+	case "!", tagStr:
+		return n.value, nil
+	case tagNull:
+		return nil, nil
+	case tagBool:
+		if _, ok := value.(bool); ok {
+			return value, nil
+		}
+	case tagInt:
+		switch value.(type) {
+		case int, *big.Int:
+			return value, nil
+		}
+	case tagFloat:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case *big.Int:
+			f, _ := new(big.Float).SetInt(v).Float64()
+			return f, nil
+		}
+	case tagBinary:
+		b, err := base64.StdEncoding.DecodeString(strings.Join(
+			strings.Fields(n.value), ""))
+		if err == nil {
+			return b, nil
+		}
+	default:
+		if !n.plain {
+			value = n.value
+		}
+		return d.custom(n, value)
+	}
+	return nil, d.error(n, ErrInvalidYAML,
+		fmt.Sprintf("invalid value %q for the tag %q", n.value, n.tag))
+}
+
+func (d *decoder) sequence(n *node) (interface{}, error) {
+	list := make(listdict.List, len(n.children))
+	for i, child := range n.children {
+		var err error
+		if list[i], err = d.decode(child); err != nil {
+			return nil, err
+		}
+	}
+	switch n.tag {
+	case "", "!", tagSeq:
+		return list, nil
+	case tagOmap:
+		// Sequence of single pair mappings
+		pairs := listdict.NewHashDict()
+		for _, child := range n.children {
+			if child.kind == aliasNode {
+				child = child.target
+			}
+			if child.kind != mappingNode || len(child.children) != 2 {
+				return nil, d.error(child, ErrInvalidYAML,
+					"expected a single pair mapping in !!omap")
+			}
+			if err := d.setPair(pairs, child.children[0],
+				child.children[1]); err != nil {
+				return nil, err
+			}
+		}
+		return d.dict(pairs), nil
+	}
+	return d.custom(n, list)
+}
+
+func (d *decoder) mapping(n *node) (interface{}, error) {
+	pairs := listdict.NewHashDict()
+	var merged []*listdict.HashDict
+	for i := 0; i < len(n.children); i += 2 {
+		key, value := n.children[i], n.children[i+1]
+		if key.kind == scalarNode && (key.tag == tagMerge ||
+			(key.tag == "" && key.plain && key.value == "<<")) {
+			sources, err := d.mergeSources(value)
+			if err != nil {
+				return nil, err
+			}
+			merged = append(merged, sources...)
+			continue
+		}
+		if err := d.setPair(pairs, key, value); err != nil {
+			return nil, err
+		}
+	}
+	if len(merged) > 0 {
+		// Merged keys go first, explicit keys override them, earlier
+		// merged mappings override later ones
+		all := listdict.NewHashDict()
+		for _, source := range merged {
+			for key, value := range source.All() {
+				if !all.HasKey(key) {
+					all.Set(key, value)
+				}
+			}
+		}
+		for key, value := range pairs.All() {
+			all.Set(key, value)
+		}
+		pairs = all
+	}
+	d.pairs[n] = pairs
+
+	switch n.tag {
+	case "", "!", tagMap:
+		return d.dict(pairs), nil
+	case tagSet:
+		set := listdict.NewSet()
+		for key, value := range pairs.All() {
+			if value != nil {
+				return nil, d.error(n, ErrInvalidYAML,
+					"expected null values in !!set")
+			}
+			set.Add(key)
+		}
+		return set, nil
+	}
+	return d.custom(n, d.dict(pairs))
+}
+
+// setPair decodes key and value of a mapping entry and adds them to pairs.
+func (d *decoder) setPair(pairs *listdict.HashDict, keyNode,
+	valueNode *node) error {
+
+	key, err := d.decode(keyNode)
+	if err != nil {
+		return err
+	}
+	if list, ok := key.(listdict.List); ok {
+		key = listdict.Tuple(list)
+	}
+	if _, ok := key.(string); !ok {
+		switch d.options.Keys {
+		case KeysToString:
+			if keyNode.kind == aliasNode {
+				keyNode = keyNode.target
+			}
+			if keyNode.kind != scalarNode {
+				return d.error(keyNode, ErrNonStringKey,
+					"collection can't be converted to a string key")
+			}
+			key = keyNode.value
+		case KeysError:
+			return d.error(keyNode, ErrNonStringKey,
+				fmt.Sprintf("found key %s", listdict.Repr(key)))
+		}
+	}
+	if pairs.HasKey(key) {
+		return d.error(keyNode, ErrDuplicateKey,
+			fmt.Sprintf("found duplicate key %s", listdict.Repr(key)))
+	}
+	value, err := d.decode(valueNode)
+	if err != nil {
+		return err
+	}
+	if err := pairs.Set(key, value); err != nil {
+		return d.error(keyNode, err, "found unhashable key")
+	}
+	return nil
+}
+
+// mergeSources returns pairs of mappings merged by merge key with value n.
+func (d *decoder) mergeSources(n *node) ([]*listdict.HashDict, error) {
+	if n.kind == aliasNode {
+		n = n.target
+	}
+	nodes := []*node{n}
+	if n.kind == sequenceNode {
+		nodes = n.children
+	}
+	sources := make([]*listdict.HashDict, len(nodes))
+	for i, source := range nodes {
+		if source.kind == aliasNode {
+			source = source.target
+		}
+		if source.kind != mappingNode {
+			return nil, d.error(source, ErrInvalidYAML,
+				"expected a mapping or list of mappings for merging")
+		}
+		if _, err := d.decode(source); err != nil {
+			return nil, err
+		}
+		sources[i] = d.pairs[source]
+	}
+	return sources, nil
+}
+
+// dict returns pairs as Dict, OrderedDict or, if not all keys are strings,
+// HashDict.
+func (d *decoder) dict(pairs *listdict.HashDict) interface{} {
+	for key := range pairs.IterKeys() {
+		if _, ok := key.(string); !ok {
+			return pairs
+		}
+	}
+	if d.options.Ordered {
+		dict := listdict.NewOrderedDict()
+		for key, value := range pairs.All() {
+			dict.Set(key.(string), value)
+		}
+		return dict
+	}
+	dict := make(listdict.Dict, pairs.Len())
+	for key, value := range pairs.All() {
+		dict[key.(string)] = value
+	}
+	return dict
+}
+
+//=============================================================================
+
+// resolve returns value of plain scalar s by YAML 1.2 core schema.
+func resolve(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+	if n, ok := parseInt(s); ok {
+		return n
+	}
+	if isFloat(s) {
+		// Out of range values are infinities, as in Python
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+	return s
+}
+
+// parseInt parses decimal, 0o octal and 0x hexadecimal integer.
+func parseInt(s string) (interface{}, bool) {
+	digits, base := s, 10
+	switch {
+	case strings.HasPrefix(s, "0o"):
+		digits, base = s[2:], 8
+	case strings.HasPrefix(s, "0x"):
+		digits, base = s[2:], 16
+	case strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+"):
+		digits = s[1:]
+	}
+	if digits == "" {
+		return nil, false
+	}
+	for i := 0; i < len(digits); i++ {
+		if d := strings.IndexByte("0123456789abcdef",
+			digits[i]|0x20); d < 0 || d >= base {
+			return nil, false
+		}
+	}
+	n, _ := new(big.Int).SetString(digits, base)
+	if strings.HasPrefix(s, "-") {
+		n.Neg(n)
+	}
+	if n.IsInt64() && int64(int(n.Int64())) == n.Int64() {
+		return int(n.Int64()), true
+	}
+	return n, true
+}
+
+// isFloat reports whether s is [-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?
+func isFloat(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(s), "e")
+	whole, fraction, hasDot := strings.Cut(mantissa, ".")
+	if !isDigits(whole) && !(whole == "" && hasDot && isDigits(fraction)) {
+		return false
+	}
+	if fraction != "" && !isDigits(fraction) {
+		return false
+	}
+	if hasExponent {
+		if exponent != "" && (exponent[0] == '-' || exponent[0] == '+') {
+			exponent = exponent[1:]
+		}
+		return isDigits(exponent)
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,637 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package yaml
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+// encoder holds state of a single DumpAll.
+type encoder struct {
+	options *DumpOptions
+	indent  int
+	buf     strings.Builder
+	counts  map[container.ID]int    // uses of containers in the current document
+	anchors map[container.ID]string // anchors of written shared containers
+	active  map[container.ID]bool   // containers being written, to find cycles
+}
+
+// place is where a block node is written, after "key:", "-", "---" or at
+// the start of a document.
+type place struct {
+	mapIndent int  // column of nested block mapping entries
+	seqIndent int  // column of nested block sequence entries
+	compact   bool // nested collection can start on the line of "-"
+	lineStart bool // nothing is written on the current line
+}
+
+type representationKind int
+
+const (
+	scalarKind representationKind = iota
+	stringKind
+	sequenceKind
+	mappingKind
+)
+
+// representation is a value prepared for writing.
+type representation struct {
+	kind   representationKind
+	text   string // scalar text, or the string
+	tag    string // "!!set" or "!!binary"
+	items  listdict.List
+	values listdict.List // values of mapping with keys in items
+	id     container.ID
+	hasID  bool
+}
+
+func (e *encoder) document(value interface{}, explicit bool) error {
+	e.counts = make(map[container.ID]int)
+	e.anchors = make(map[container.ID]string)
+	e.active = make(map[container.ID]bool)
+	e.count(value)
+	if explicit {
+		e.buf.WriteString("---")
+	}
+	if err := e.block(value, place{lineStart: !explicit}); err != nil {
+		return err
+	}
+	// Plain scalar could go on in the next document, PyYAML ends it
+	r, _ := e.represent(value)
+	if r.kind == scalarKind && r.tag == "" || r.kind == stringKind &&
+		!strings.Contains(r.text, "\n") && plainAllowed(r.text, false) {
+		e.buf.WriteString("...\n")
+	}
+	return nil
+}
+
+// count counts uses of containers in value, to anchor the shared ones.
+func (e *encoder) count(value interface{}) {
+	r, err := e.represent(value)
+	if err != nil || r.kind < sequenceKind {
+		return
+	}
+	if r.hasID {
+		if e.counts[r.id]++; e.counts[r.id] > 1 {
+			return
+		}
+	}
+	if r.kind == sequenceKind {
+		for _, item := range r.items {
+			e.count(item)
+		}
+	}
+	for _, item := range r.values {
+		e.count(item)
+	}
+}
+
+// enter writes value using write, or an alias if the container is already
+// written. It returns properties of the value: its anchor and tag.
+func (e *encoder) enter(r *representation, alias func(name string),
+	write func(props string) error) error {
+
+	props := r.tag
+	if r.hasID {
+		if e.active[r.id] {
+			return &listdict.ValueError{Err: ErrCircularReference}
+		}
+		if name, ok := e.anchors[r.id]; ok {
+			alias(name)
+			return nil
+		}
+		if e.counts[r.id] > 1 {
+			name := fmt.Sprintf("id%03d", len(e.anchors)+1)
+			e.anchors[r.id] = name
+			props = strings.TrimSpace("&" + name + " " + props)
+		}
+		e.active[r.id] = true
+		defer delete(e.active, r.id)
+	}
+	return write(props)
+}
+
+//=============================================================================
+
+// block writes value in block context at pl, ending with a line break.
+func (e *encoder) block(value interface{}, pl place) error {
+	r, err := e.represent(value)
+	if err != nil {
+		return err
+	}
+	inline := func(s string) {
+		if !pl.lineStart {
+			e.buf.WriteByte(' ')
+		}
+		e.buf.WriteString(s)
+		e.buf.WriteByte('\n')
+	}
+	return e.enter(r, func(name string) {
+		inline("*" + name)
+	}, func(props string) error {
+		if r.kind == stringKind && e.literalAllowed(r.text) {
+			inline(join(props, e.literalHeader(r.text)))
+			e.literal(r.text, max(pl.mapIndent, e.indent))
+			return nil
+		}
+		if r.kind == stringKind {
+			inline(join(props, quote(r.text, false)))
+			return nil
+		}
+		if r.kind < sequenceKind || len(r.items) == 0 ||
+			e.options.Style == Flow ||
+			(e.options.Style == FlowLeaves && r.leaf()) {
+			text, err := e.flowText(r, false)
+			if err != nil {
+				return err
+			}
+			inline(join(props, text))
+			return nil
+		}
+
+		indent := pl.mapIndent
+		if r.kind == sequenceKind {
+			indent = pl.seqIndent
+		}
+		skipIndent := false
+		switch {
+		case props != "":
+			inline(props)
+		case pl.compact:
+			e.buf.WriteString(strings.Repeat(" ", e.indent-1))
+			skipIndent = true
+		case !pl.lineStart:
+			e.buf.WriteByte('\n')
+		}
+		for i, item := range r.items {
+			if i > 0 || !skipIndent {
+				e.buf.WriteString(strings.Repeat(" ", indent))
+			}
+			if r.kind == sequenceKind {
+				e.buf.WriteByte('-')
+				err := e.block(item, place{mapIndent: indent + e.indent,
+					seqIndent: indent + e.indent, compact: true})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			key, err := e.keyText(item)
+			if err != nil {
+				return err
+			}
+			e.buf.WriteString(key + ":")
+			err = e.block(r.values[i], place{mapIndent: indent + e.indent,
+				seqIndent: indent})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// keyText returns mapping key in flow style, collections aren't anchored.
+func (e *encoder) keyText(key interface{}) (string, error) {
+	r, err := e.represent(key)
+	if err != nil {
+		return "", err
+	}
+	text, err := e.flowText(r, true)
+	if err != nil {
+		return "", err
+	}
+	return join(r.tag, text), nil
+}
+
+// flow writes value in flow context into sb.
+func (e *encoder) flow(value interface{}, sb *strings.Builder) error {
+	r, err := e.represent(value)
+	if err != nil {
+		return err
+	}
+	return e.enter(r, func(name string) {
+		sb.WriteString("*" + name)
+	}, func(props string) error {
+		text, err := e.flowText(r, false)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(join(props, text))
+		return nil
+	})
+}
+
+// flowText returns r in flow style without its properties. Keys of
+// mapping keys are written without anchors.
+func (e *encoder) flowText(r *representation, key bool) (string, error) {
+	switch r.kind {
+	case scalarKind:
+		return r.text, nil
+	case stringKind:
+		return quote(r.text, true), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteByte("[{"[r.kind-sequenceKind])
+	for i, item := range r.items {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if r.kind == sequenceKind {
+			if err := e.itemFlow(item, key, &sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		text, err := e.keyText(item)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(text + ": ")
+		if err := e.itemFlow(r.values[i], key, &sb); err != nil {
+			return "", err
+		}
+	}
+	sb.WriteByte("]}"[r.kind-sequenceKind])
+	return sb.String(), nil
+}
+
+func (e *encoder) itemFlow(value interface{}, key bool,
+	sb *strings.Builder) error {
+
+	if !key {
+		return e.flow(value, sb)
+	}
+	text, err := e.keyText(value)
+	sb.WriteString(text)
+	return err
+}
+
+// leaf reports whether r is a collection of scalars.
+func (r *representation) leaf() bool {
+	for _, list := range []listdict.List{r.items, r.values} {
+		for _, item := range list {
+			switch reflect.ValueOf(item).Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map, reflect.Pointer,
+				reflect.Struct:
+				if _, ok := item.([]byte); !ok {
+					if _, ok := item.(*big.Int); !ok {
+						return false
+					}
+				}
+			}
+		}
+	}
+	return true
+}
+
+func join(props, text string) string {
+	if props == "" {
+		return text
+	}
+	return props + " " + text
+}
+
+//=============================================================================
+
+// represent prepares value for writing.
+func (e *encoder) represent(value interface{}) (*representation, error) {
+	r := &representation{kind: scalarKind}
+	switch v := value.(type) {
+	case nil:
+		r.text = "null"
+		return r, nil
+	case []byte:
+		// Lines of 76 characters, as Python's base64.encodebytes
+		text := base64.StdEncoding.EncodeToString(v)
+		var sb strings.Builder
+		for len(text) > 0 {
+			n := min(len(text), 76)
+			sb.WriteString(text[:n] + "\n")
+			text = text[n:]
+		}
+		r.kind, r.tag, r.text = stringKind, "!!binary", sb.String()
+		return r, nil
+	case *big.Int:
+		r.text = "null"
+		if v != nil {
+			r.text = v.String()
+		}
+		return r, nil
+	case listdict.List:
+		return e.sequence(r, value, v), nil
+	case listdict.Tuple:
+		return e.sequence(r, value, listdict.List(v)), nil
+	case *listdict.Deque:
+		return e.sequence(r, value, v.ToList()), nil
+	case listdict.Dict:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		r.kind, r.items, r.values = mappingKind, make(listdict.List, len(v)),
+			make(listdict.List, len(v))
+		for i, key := range keys {
+			r.items[i], r.values[i] = key, v[key]
+		}
+		r.id, r.hasID = container.Identity(reflect.ValueOf(value))
+		return r, nil
+	case listdict.Counter:
+		return e.represent(v.HashDict)
+	case listdict.DefaultDict:
+		return e.represent(v.Dict)
+	case listdict.ChainMap:
+		return e.represent(v.ToDict())
+	case *listdict.OrderedDict:
+		return e.mapping(r, value, v.Keys(), v.Values(),
+			!e.options.SortKeys), nil
+	case *listdict.HashDict:
+		return e.mapping(r, value, v.Keys(), v.Values(),
+			!e.options.SortKeys), nil
+	case *listdict.Set:
+		r.tag = "!!set"
+		return e.mapping(r, value, v.ToList(), make(listdict.List, v.Len()),
+			!e.options.SortKeys), nil
+	case listdict.FrozenSet:
+		r.tag = "!!set"
+		return e.mapping(r, value, v.ToList(), make(listdict.List, v.Len()),
+			!e.options.SortKeys), nil
+	}
+
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.Bool:
+		r.text = strconv.FormatBool(val.Bool())
+		return r, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		r.text = strconv.FormatInt(val.Int(), 10)
+		return r, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		r.text = strconv.FormatUint(val.Uint(), 10)
+		return r, nil
+	case reflect.Float32, reflect.Float64:
+		r.text = floatText(val.Float())
+		return r, nil
+	case reflect.String:
+		if !utf8.ValidString(val.String()) {
+			break
+		}
+		r.kind, r.text = stringKind, val.String()
+		return r, nil
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			r.text = "null"
+			return r, nil
+		}
+		list := make(listdict.List, val.Len())
+		for i := range list {
+			list[i] = val.Index(i).Interface()
+		}
+		return e.sequence(r, value, list), nil
+	case reflect.Map:
+		keys := make(listdict.List, 0, val.Len())
+		values := make(listdict.List, 0, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key().Interface())
+			values = append(values, iter.Value().Interface())
+		}
+		return e.mapping(r, value, keys, values, false), nil
+	}
+
+	if e.options.Default == nil {
+		return nil, &listdict.TypeError{Values: []interface{}{value},
+			Err: ErrUnrepresentable}
+	}
+	replaced, err := e.options.Default(value)
+	if err != nil {
+		return nil, err
+	}
+	return e.represent(replaced)
+}
+
+func (e *encoder) sequence(r *representation, value interface{},
+	items listdict.List) *representation {
+
+	r.kind, r.items = sequenceKind, items
+	r.id, r.hasID = container.Identity(reflect.ValueOf(value))
+	return r
+}
+
+// mapping returns r as mapping, keys are sorted unless ordered is true or
+// they can't be compared, as in PyYAML.
+func (e *encoder) mapping(r *representation, value interface{}, keys,
+	values listdict.List, ordered bool) *representation {
+
+	r.kind, r.items, r.values = mappingKind, keys, values
+	r.id, r.hasID = container.Identity(reflect.ValueOf(value))
+	if ordered {
+		return r
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	comparable := true
+	sort.SliceStable(order, func(i, j int) bool {
+		c, err := listdict.Compare(keys[order[i]], keys[order[j]])
+		comparable = comparable && err == nil
+		return c < 0
+	})
+	if !comparable {
+		return r
+	}
+	r.items, r.values = make(listdict.List, len(keys)),
+		make(listdict.List, len(keys))
+	for i, index := range order {
+		r.items[i], r.values[i] = keys[index], values[index]
+	}
+	return r
+}
+
+// floatText returns f as PyYAML writes it, always with a dot.
+func floatText(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	}
+	s := listdict.Repr(f)
+	if !strings.Contains(s, ".") {
+		mantissa, exponent, _ := strings.Cut(s, "e")
+		s = mantissa + ".0"
+		if exponent != "" {
+			s += "e" + exponent
+		}
+	}
+	return s
+}
+
+//=============================================================================
+
+// isPrintable reports whether r can be written in a quoted or block scalar
+// without escaping.
+func isPrintable(r rune) bool {
+	return (r >= 0x20 && r < 0x7f) || (r >= 0xa0 && r != 0x2028 &&
+		r != 0x2029 && r != 0xfeff && r != utf8.RuneError)
+}
+
+// plainAllowed reports whether s can be a plain scalar that is loaded
+// back as the same string, by this package and by YAML 1.1 parsers.
+func plainAllowed(s string, flow bool) bool {
+	if s == "" || s == "<<" || s[0] == ' ' || s[len(s)-1] == ' ' ||
+		s[len(s)-1] == ':' || strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") || strings.HasPrefix(s, "...") ||
+		strings.HasPrefix(s, "---") {
+		return false
+	}
+	if strings.IndexByte(indicators, s[0]) >= 0 && !((s[0] == '-' ||
+		s[0] == '?' || s[0] == ':') && len(s) > 1 && s[1] != ' ' &&
+		!isFlowIndicator(s[1])) {
+		return false
+	}
+	// PyYAML doesn't read ':' and leading '?' in flow plain scalars
+	if flow && (strings.ContainsAny(s, ",[]{}:") || s[0] == '?') {
+		return false
+	}
+	for _, r := range s {
+		if !isPrintable(r) {
+			return false
+		}
+	}
+	if _, ok := resolve(s).(string); !ok {
+		return false
+	}
+	return !yaml11Implicit.MatchString(s)
+}
+
+// yaml11Implicit matches plain scalars that PyYAML doesn't load as strings.
+var yaml11Implicit = regexp.MustCompile(`^(?:` +
+	// bool
+	`yes|Yes|YES|no|No|NO|true|True|TRUE|false|False|FALSE|on|On|ON|off|Off|OFF` +
+	// float
+	`|[-+]?[0-9][0-9_]*\.[0-9_]*(?:[eE][-+][0-9]+)?` +
+	`|\.[0-9][0-9_]*(?:[eE][-+][0-9]+)?` +
+	`|[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+\.[0-9_]*` +
+	`|[-+]?\.(?:inf|Inf|INF)|\.(?:nan|NaN|NAN)` +
+	// int
+	`|[-+]?0b[0-1_]+|[-+]?0[0-7_]+|[-+]?(?:0|[1-9][0-9_]*)` +
+	`|[-+]?0x[0-9a-fA-F_]+|[-+]?[1-9][0-9_]*(?::[0-5]?[0-9])+` +
+	// null, value and timestamp
+	`|~|null|Null|NULL|=` +
+	`|[0-9]{4}-[0-9]{2}-[0-9]{2}` +
+	`|[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(?:[Tt]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}` +
+	`(?:\.[0-9]*)?(?:[ \t]*(?:Z|[-+][0-9]{1,2}(?::[0-9]{2})?))?` +
+	`)$`)
+
+// quote returns s as plain, single quoted or double quoted scalar.
+func quote(s string, flow bool) string {
+	if plainAllowed(s, flow) {
+		return s
+	}
+	single := true
+	for _, r := range s {
+		if !isPrintable(r) {
+			single = false
+			break
+		}
+	}
+	if single {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case isPrintable(r):
+			sb.WriteRune(r)
+		case r < 0x100:
+			if c := strings.IndexRune("\x00\a\b\t\n\v\f\r\x1b\u0085", r); c >= 0 {
+				sb.WriteString("\\" + string("0abtnvfreN"[min(c, 9)]))
+			} else {
+				fmt.Fprintf(&sb, "\\x%02X", r)
+			}
+		case r == 0x2028:
+			sb.WriteString("\\L")
+		case r == 0x2029:
+			sb.WriteString("\\P")
+		default:
+			fmt.Fprintf(&sb, "\\u%04X", r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// literalAllowed reports whether multiline string s can be a literal block
+// scalar: printable, with no trailing spaces.
+func (e *encoder) literalAllowed(s string) bool {
+	if !strings.Contains(s, "\n") || strings.Trim(s, "\n") == "" ||
+		e.options.Style == Flow {
+		return false
+	}
+	for _, line := range strings.Split(s, "\n") {
+		if strings.HasSuffix(line, " ") {
+			return false
+		}
+		for _, r := range line {
+			if !isPrintable(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// literalHeader returns "|" with indentation and chomping indicators.
+func (e *encoder) literalHeader(s string) string {
+	header := "|"
+	if strings.HasPrefix(strings.TrimLeft(s, "\n"), " ") {
+		header += strconv.Itoa(e.indent)
+	}
+	switch {
+	case !strings.HasSuffix(s, "\n"):
+		header += "-"
+	case strings.HasSuffix(s, "\n\n"):
+		header += "+"
+	}
+	return header
+}
+
+// literal writes lines of literal block scalar with indentation indent.
+func (e *encoder) literal(s string, indent int) {
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if line != "" {
+			e.buf.WriteString(strings.Repeat(" ", indent))
+			e.buf.WriteString(line)
+		}
+		e.buf.WriteByte('\n')
+	}
+}
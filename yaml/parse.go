@@ -0,0 +1,975 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosimple/listdict/internal/container"
+)
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	sequenceNode
+	mappingNode
+	aliasNode
+)
+
+// node is a parsed YAML node, Load decodes nodes into values.
+type node struct {
+	kind     nodeKind
+	tag      string  // full tag, "!" for non-specific, "" if none
+	anchor   string  // anchor name, "" if none
+	value    string  // scalar text or alias name
+	plain    bool    // plain scalar, resolved by the schema
+	children []*node // items, or keys and values one after another
+	target   *node   // anchored node of an alias
+	pos      int     // byte offset in source
+}
+
+const (
+	// defaultTagPrefix is the prefix of standard tags, the "!!" handle
+	defaultTagPrefix = "tag:yaml.org,2002:"
+	// indicators can't start a plain scalar, except -?: followed by
+	// a non-space
+	indicators = "-?:,[]{}#&*!|>'\"%@`"
+)
+
+// parser turns YAML stream into nodes.
+type parser struct {
+	src     string
+	pos     int
+	anchors map[string]*node  // anchored nodes of the current document
+	tags    map[string]string // tag handles of %TAG directives
+	depth   int               // number of collections being parsed
+}
+
+func newParser(text string) *parser {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return &parser{src: text}
+}
+
+func (p *parser) error(msg string, pos int) error {
+	return p.fail(ErrInvalidYAML, msg, pos)
+}
+
+// fail returns *LoadError with position of pos.
+func (p *parser) fail(err error, msg string, pos int) error {
+	start := p.lineStart(pos)
+	return &LoadError{Msg: msg, Line: strings.Count(p.src[:start], "\n") + 1,
+		Col: utf8.RuneCountInString(p.src[start:pos]) + 1, Err: err}
+}
+
+// enter counts a nested collection, it returns *LoadError if collections
+// are nested deeper than container.MaxDepth.
+func (p *parser) enter() error {
+	if p.depth >= container.MaxDepth {
+		return p.error("maximum nesting depth exceeded", p.pos)
+	}
+	p.depth++
+	return nil
+}
+
+// unexpected returns error for content found where it can't be.
+func (p *parser) unexpected() error {
+	if p.atValueIndicator() {
+		return p.error("mapping values are not allowed here", p.pos)
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return p.error(fmt.Sprintf("found unexpected %q", r), p.pos)
+}
+
+//=============================================================================
+
+func (p *parser) peek() byte {
+	return p.at(p.pos)
+}
+
+func (p *parser) at(i int) byte {
+	if i < len(p.src) {
+		return p.src[i]
+	}
+	return 0
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+// isSpace reports whether i is the end of source, a blank or a line break.
+func (p *parser) isSpace(i int) bool {
+	return i >= len(p.src) || isBlank(p.src[i]) || p.src[i] == '\n'
+}
+
+// plainSafe reports whether character at i can continue a plain scalar
+// after an indicator.
+func (p *parser) plainSafe(i int, flow bool) bool {
+	return !p.isSpace(i) && !(flow && isFlowIndicator(p.src[i]))
+}
+
+func (p *parser) lineStart(pos int) int {
+	return strings.LastIndexByte(p.src[:pos], '\n') + 1
+}
+
+func (p *parser) column(pos int) int {
+	return pos - p.lineStart(pos)
+}
+
+// atLineStart reports whether only blanks are before p.pos on its line.
+func (p *parser) atLineStart() bool {
+	return strings.Trim(p.src[p.lineStart(p.pos):p.pos], " \t") == ""
+}
+
+// atMarker reports whether document marker "---" or "..." is at pos.
+func (p *parser) atMarker(pos int, marker string) bool {
+	return p.column(pos) == 0 && strings.HasPrefix(p.src[pos:], marker) &&
+		p.isSpace(pos+3)
+}
+
+// atEnd reports whether the current document ends at p.pos.
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.src) || p.atMarker(p.pos, "---") ||
+		p.atMarker(p.pos, "...")
+}
+
+func (p *parser) atSeqEntry() bool {
+	return p.peek() == '-' && p.isSpace(p.pos+1)
+}
+
+func (p *parser) atValueIndicator() bool {
+	return p.peek() == ':' && p.isSpace(p.pos+1)
+}
+
+func (p *parser) skipBlanks() {
+	for p.pos < len(p.src) && isBlank(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// skipSpace skips blanks, comments and line breaks.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n':
+			p.pos++
+		case '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) empty(pos int) *node {
+	return &node{kind: scalarNode, plain: true, pos: pos}
+}
+
+// attach sets properties of n and registers its anchor.
+func (p *parser) attach(n *node, anchor, tag string, pos int) error {
+	if anchor == "" && tag == "" {
+		return nil
+	}
+	if n.kind == aliasNode {
+		return p.error("alias can't have an anchor or a tag", pos)
+	}
+	if (anchor != "" && n.anchor != "") || (tag != "" && n.tag != "") {
+		return p.error("node has two anchors or two tags", pos)
+	}
+	if anchor != "" {
+		n.anchor = anchor
+		p.anchors[anchor] = n
+	}
+	if tag != "" {
+		n.tag = tag
+	}
+	// Errors of the node point at its properties
+	n.pos = pos
+	return nil
+}
+
+//=============================================================================
+
+// stream parses all documents, an empty document is a null scalar.
+func (p *parser) stream() ([]*node, error) {
+	var docs []*node
+	for {
+		p.anchors = make(map[string]*node)
+		p.tags = map[string]string{"!": "!", "!!": defaultTagPrefix}
+		p.skipSpace()
+		directives := false
+		for p.peek() == '%' && p.column(p.pos) == 0 {
+			if err := p.directive(); err != nil {
+				return nil, err
+			}
+			directives = true
+			p.skipSpace()
+		}
+		switch {
+		case p.atMarker(p.pos, "---"):
+			p.pos += 3
+		case directives:
+			return nil, p.error("expected '---' after directives", p.pos)
+		case p.pos >= len(p.src):
+			return docs, nil
+		case p.atMarker(p.pos, "..."):
+			p.pos += 3
+			continue
+		}
+
+		doc, err := p.blockNode(-1, false, false)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+		p.skipSpace()
+		switch {
+		case p.atMarker(p.pos, "..."):
+			p.pos += 3
+		case p.pos < len(p.src) && !p.atMarker(p.pos, "---"):
+			return nil, p.unexpected()
+		}
+	}
+}
+
+// directive parses %YAML or %TAG directive line, others are ignored.
+func (p *parser) directive() error {
+	start := p.pos
+	end := strings.IndexByte(p.src[start:], '\n')
+	if end < 0 {
+		end = len(p.src) - start
+	}
+	p.pos = start + end
+	line, _, _ := strings.Cut(p.src[start:p.pos], " #")
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "%YAML":
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "1.") {
+			return p.error("found incompatible YAML document", start)
+		}
+	case "%TAG":
+		if len(fields) != 3 || !strings.HasPrefix(fields[1], "!") ||
+			!strings.HasSuffix(fields[1], "!") {
+			return p.error("invalid %TAG directive", start)
+		}
+		p.tags[fields[1]] = fields[2]
+	}
+	return nil
+}
+
+//=============================================================================
+
+// blockNode parses node of a block collection with indentation indent,
+// -1 at the top level. inline is true for mapping values on the line of
+// their key, where block collections can't start, seqIndent allows block
+// sequence at indent, as mapping values can be.
+func (p *parser) blockNode(indent int, inline, seqIndent bool) (*node, error) {
+	p.skipSpace()
+	if p.atLineStart() {
+		inline = false
+	}
+	if p.atEnd() || (!inline && !p.indented(indent, seqIndent)) {
+		return p.empty(p.pos), nil
+	}
+
+	start := p.pos
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != start {
+		end := p.pos
+		p.skipSpace()
+		if p.atLineStart() {
+			// Properties of a node on the following lines
+			n := p.empty(end)
+			if !p.atEnd() && p.indented(indent, seqIndent) {
+				if n, err = p.blockNode(indent, false, seqIndent); err != nil {
+					return nil, err
+				}
+			}
+			return n, p.attach(n, anchor, tag, start)
+		}
+	}
+	return p.blockContent(indent, inline, start, anchor, tag)
+}
+
+// indented reports whether node at p.pos belongs to block collection with
+// indentation indent.
+func (p *parser) indented(indent int, seqIndent bool) bool {
+	col := p.column(p.pos)
+	return col > indent || (seqIndent && col == indent && p.atSeqEntry())
+}
+
+// blockContent parses node at p.pos, its properties start at start.
+// Scalar followed by ':' is the first key of a block mapping.
+func (p *parser) blockContent(indent int, inline bool, start int, anchor,
+	tag string) (*node, error) {
+
+	col := p.column(start)
+	var n *node
+	var err error
+	switch c := p.peek(); {
+	case p.atEnd():
+		n = p.empty(p.pos)
+	case (c == '-' || c == '?') && p.isSpace(p.pos+1):
+		if inline || p.pos != start {
+			return nil, p.error("block collection is not allowed here", p.pos)
+		}
+		if c == '-' {
+			return p.blockSequence(col)
+		}
+		return p.blockMapping(col, nil)
+	case c == '|' || c == '>':
+		n, err = p.blockScalar(indent)
+	default:
+		if n, err = p.flowOrScalar(indent); err != nil {
+			return nil, err
+		}
+		if err = p.attach(n, anchor, tag, start); err != nil {
+			return nil, err
+		}
+		end := p.pos
+		p.skipBlanks()
+		if !p.atValueIndicator() {
+			return n, nil
+		}
+		if inline {
+			return nil, p.unexpected()
+		}
+		if strings.Contains(p.src[start:end], "\n") {
+			return nil, p.error("implicit key must be on a single line", start)
+		}
+		return p.blockMapping(col, n)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, p.attach(n, anchor, tag, start)
+}
+
+// blockSequence parses block sequence with entries at column indent.
+func (p *parser) blockSequence(indent int) (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	n := &node{kind: sequenceNode, pos: p.pos}
+	for {
+		p.pos++ // '-'
+		item, err := p.blockNode(indent, false, false)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, item)
+
+		p.skipSpace()
+		if p.atEnd() {
+			return n, nil
+		}
+		if !p.atLineStart() {
+			return nil, p.unexpected()
+		}
+		col := p.column(p.pos)
+		if col > indent {
+			return nil, p.error("bad indentation of a sequence entry", p.pos)
+		}
+		if col < indent || !p.atSeqEntry() {
+			return n, nil
+		}
+	}
+}
+
+// blockMapping parses block mapping with keys at column indent. If key is
+// not nil it's the first key and p.pos is at its ':'.
+func (p *parser) blockMapping(indent int, key *node) (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	n := &node{kind: mappingNode, pos: p.pos}
+	if key != nil {
+		n.pos = key.pos
+	}
+	for {
+		var value *node
+		var err error
+		if key == nil && p.peek() == '?' && p.isSpace(p.pos+1) {
+			p.pos++
+			if key, err = p.blockNode(indent, false, false); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			value = p.empty(p.pos)
+			if !p.atEnd() && p.column(p.pos) == indent && p.atValueIndicator() {
+				p.pos++
+				if value, err = p.blockNode(indent, false, true); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			if key == nil {
+				if key, err = p.keyNode(); err != nil {
+					return nil, err
+				}
+			}
+			p.pos++ // ':'
+			if value, err = p.blockNode(indent, true, true); err != nil {
+				return nil, err
+			}
+		}
+		n.children = append(n.children, key, value)
+		key = nil
+
+		p.skipSpace()
+		if p.atEnd() {
+			return n, nil
+		}
+		if !p.atLineStart() {
+			return nil, p.unexpected()
+		}
+		col := p.column(p.pos)
+		if col > indent {
+			return nil, p.error("bad indentation of a mapping entry", p.pos)
+		}
+		if col < indent {
+			return n, nil
+		}
+	}
+}
+
+// keyNode parses implicit key of a block mapping entry, keys are on
+// a single line. p.pos is left at ':'.
+func (p *parser) keyNode() (*node, error) {
+	start := p.pos
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	n := p.empty(p.pos)
+	if !p.atValueIndicator() {
+		if n, err = p.flowOrScalar(math.MaxInt); err != nil {
+			return nil, err
+		}
+	}
+	if err = p.attach(n, anchor, tag, start); err != nil {
+		return nil, err
+	}
+	end := p.pos
+	p.skipBlanks()
+	if !p.atValueIndicator() {
+		return nil, p.error("could not find expected ':'", p.pos)
+	}
+	if strings.Contains(p.src[start:end], "\n") {
+		return nil, p.error("implicit key must be on a single line", start)
+	}
+	return n, nil
+}
+
+// flowOrScalar parses alias, flow collection or scalar in block context,
+// plain scalars continue on lines indented more than indent.
+func (p *parser) flowOrScalar(indent int) (*node, error) {
+	switch p.peek() {
+	case '*':
+		return p.alias()
+	case '[', '{':
+		return p.flowCollection()
+	case '"':
+		return p.doubleQuoted()
+	case '\'':
+		return p.singleQuoted()
+	}
+	return p.plain(indent, false)
+}
+
+//=============================================================================
+
+// properties parses anchor and tag of a node, in any order.
+func (p *parser) properties() (anchor, tag string, err error) {
+	for {
+		start := p.pos
+		switch p.peek() {
+		case '&':
+			if anchor != "" {
+				return "", "", p.error("node has two anchors", start)
+			}
+			p.pos++
+			if anchor = p.name(); anchor == "" {
+				return "", "", p.error("expected anchor name", start)
+			}
+		case '!':
+			if tag != "" {
+				return "", "", p.error("node has two tags", start)
+			}
+			if tag, err = p.tag(); err != nil {
+				return "", "", err
+			}
+		default:
+			return anchor, tag, nil
+		}
+		p.skipBlanks()
+	}
+}
+
+// name returns anchor or alias name at p.pos.
+func (p *parser) name() string {
+	start := p.pos
+	for !p.isSpace(p.pos) && !isFlowIndicator(p.src[p.pos]) &&
+		!p.atValueIndicator() {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// tag returns full tag at p.pos, with its handle replaced by prefix.
+func (p *parser) tag() (string, error) {
+	start := p.pos
+	if strings.HasPrefix(p.src[p.pos:], "!<") {
+		end := strings.IndexByte(p.src[p.pos:], '>')
+		if end < 0 {
+			return "", p.error("expected '>' of verbatim tag", start)
+		}
+		p.pos += end + 1
+		return p.unescapeTag(p.src[start+2:p.pos-1], start)
+	}
+	for !p.isSpace(p.pos) && !isFlowIndicator(p.src[p.pos]) {
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if text == "!" {
+		return text, nil
+	}
+	handle, suffix := "!", text[1:]
+	if i := strings.IndexByte(text[1:], '!'); i >= 0 {
+		handle, suffix = text[:i+2], text[i+2:]
+	}
+	prefix, ok := p.tags[handle]
+	if !ok {
+		return "", p.error(fmt.Sprintf("found undefined tag handle %q", handle),
+			start)
+	}
+	return p.unescapeTag(prefix+suffix, start)
+}
+
+func (p *parser) unescapeTag(tag string, pos int) (string, error) {
+	out, err := url.PathUnescape(tag)
+	if err != nil || out == "" {
+		return "", p.error(fmt.Sprintf("invalid tag %q", tag), pos)
+	}
+	return out, nil
+}
+
+func (p *parser) alias() (*node, error) {
+	start := p.pos
+	p.pos++ // '*'
+	name := p.name()
+	if name == "" {
+		return nil, p.error("expected alias name", start)
+	}
+	target, ok := p.anchors[name]
+	if !ok {
+		return nil, p.error(fmt.Sprintf("found undefined alias %q", name), start)
+	}
+	return &node{kind: aliasNode, value: name, target: target, pos: start}, nil
+}
+
+//=============================================================================
+
+// plain parses plain scalar. In block context it continues on lines
+// indented more than indent, in flow context on any line.
+func (p *parser) plain(indent int, flow bool) (*node, error) {
+	start := p.pos
+	c := p.peek()
+	if strings.IndexByte(indicators, c) >= 0 && !((c == '-' || c == '?' ||
+		c == ':') && p.plainSafe(p.pos+1, flow)) {
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		return nil, p.error(fmt.Sprintf(
+			"found character %q that cannot start any token", r), p.pos)
+	}
+
+	text := p.plainLine(flow)
+	for {
+		i := p.pos
+		for i < len(p.src) && isBlank(p.src[i]) {
+			i++
+		}
+		if p.at(i) != '\n' {
+			break
+		}
+		breaks := 0
+		for p.at(i) == '\n' {
+			breaks++
+			i++
+			for i < len(p.src) && isBlank(p.src[i]) {
+				i++
+			}
+		}
+		if i >= len(p.src) || p.src[i] == '#' || p.atMarker(i, "---") ||
+			p.atMarker(i, "...") || (!flow && p.column(i) <= indent) {
+			break
+		}
+		end := p.pos
+		p.pos = i
+		line := p.plainLine(flow)
+		if line == "" {
+			p.pos = end
+			break
+		}
+		if breaks == 1 {
+			text += " " + line
+		} else {
+			text += strings.Repeat("\n", breaks-1) + line
+		}
+	}
+	return &node{kind: scalarNode, value: text, plain: true, pos: start}, nil
+}
+
+// plainLine returns part of plain scalar on the current line.
+func (p *parser) plainLine(flow bool) string {
+	start, end := p.pos, p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '\n' || (flow && isFlowIndicator(c)) ||
+			(c == ':' && !p.plainSafe(p.pos+1, flow)) {
+			break
+		}
+		p.pos++
+		if isBlank(c) {
+			if p.peek() == '#' {
+				break
+			}
+			continue
+		}
+		end = p.pos
+	}
+	p.pos = end
+	return p.src[start:end]
+}
+
+// fold removes trailing blanks of buf (but not of its first keep bytes),
+// skips line breaks at p.pos and adds their folded version.
+func (p *parser) fold(buf []byte, keep int) []byte {
+	for len(buf) > keep && isBlank(buf[len(buf)-1]) {
+		buf = buf[:len(buf)-1]
+	}
+	breaks := 0
+	for p.peek() == '\n' {
+		breaks++
+		p.pos++
+		p.skipBlanks()
+	}
+	if breaks == 1 {
+		return append(buf, ' ')
+	}
+	return append(buf, strings.Repeat("\n", breaks-1)...)
+}
+
+func (p *parser) singleQuoted() (*node, error) {
+	start := p.pos
+	p.pos++
+	var buf []byte
+	for {
+		switch {
+		case p.pos >= len(p.src) || p.atMarker(p.pos, "---") ||
+			p.atMarker(p.pos, "..."):
+			return nil, p.error("found unclosed quoted scalar", start)
+		case strings.HasPrefix(p.src[p.pos:], "''"):
+			buf = append(buf, '\'')
+			p.pos += 2
+		case p.src[p.pos] == '\'':
+			p.pos++
+			return &node{kind: scalarNode, value: string(buf), pos: start}, nil
+		case p.src[p.pos] == '\n':
+			buf = p.fold(buf, 0)
+		default:
+			buf = append(buf, p.src[p.pos])
+			p.pos++
+		}
+	}
+}
+
+var simpleEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028",
+	'P': "\u2029",
+}
+
+func (p *parser) doubleQuoted() (*node, error) {
+	start := p.pos
+	p.pos++
+	var buf []byte
+	keep := 0 // escaped blanks are not trimmed by folding
+	for {
+		switch {
+		case p.pos >= len(p.src) || p.atMarker(p.pos, "---") ||
+			p.atMarker(p.pos, "..."):
+			return nil, p.error("found unclosed quoted scalar", start)
+		case p.src[p.pos] == '"':
+			p.pos++
+			return &node{kind: scalarNode, value: string(buf), pos: start}, nil
+		case p.src[p.pos] == '\n':
+			buf = p.fold(buf, keep)
+		case p.src[p.pos] != '\\':
+			buf = append(buf, p.src[p.pos])
+			p.pos++
+		case p.at(p.pos+1) == '\n':
+			// Escaped line break joins lines without a space
+			p.pos += 2
+			p.skipBlanks()
+		default:
+			escape := p.pos
+			c := p.at(p.pos + 1)
+			p.pos += 2
+			if s, ok := simpleEscapes[c]; ok {
+				buf = append(buf, s...)
+			} else if size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]; size > 0 &&
+				p.pos+size <= len(p.src) {
+				code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+				if err != nil || code > utf8.MaxRune {
+					return nil, p.error("invalid escape code", escape)
+				}
+				buf = utf8.AppendRune(buf, rune(code))
+				p.pos += size
+			} else {
+				return nil, p.error("found unknown escape character", escape)
+			}
+			keep = len(buf)
+		}
+	}
+}
+
+// blockScalar parses literal (|) or folded (>) scalar of a node in block
+// collection with indentation indent.
+func (p *parser) blockScalar(indent int) (*node, error) {
+	start := p.pos
+	literal := p.peek() == '|'
+	p.pos++
+	chomp, increment := byte(0), 0
+	for i := 0; i < 2; i++ {
+		switch c := p.peek(); {
+		case (c == '+' || c == '-') && chomp == 0:
+			chomp = c
+		case c >= '1' && c <= '9' && increment == 0:
+			increment = int(c - '0')
+		default:
+			continue
+		}
+		p.pos++
+	}
+	p.skipBlanks()
+	if p.peek() == '#' && isBlank(p.at(p.pos-1)) {
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		return nil, p.error(
+			"expected a comment or a line break after block scalar header", p.pos)
+	}
+
+	contentIndent := -1
+	if increment > 0 {
+		contentIndent = max(indent, 0) + increment
+	}
+	var lines []string // lines without indentation, "" for empty ones
+	lastBreak := false // last content line ends with a line break
+	for p.pos+1 < len(p.src) {
+		lineStart := p.pos + 1
+		i := lineStart
+		for p.at(i) == ' ' {
+			i++
+		}
+		end := strings.IndexByte(p.src[lineStart:], '\n')
+		if end < 0 {
+			end = len(p.src)
+		} else {
+			end += lineStart
+		}
+		if i == end && (contentIndent < 0 || i-lineStart <= contentIndent) {
+			lines = append(lines, "")
+			p.pos = end
+			continue
+		}
+		if contentIndent < 0 {
+			if i-lineStart <= indent {
+				break
+			}
+			contentIndent = i - lineStart
+		}
+		if i-lineStart < contentIndent || p.atMarker(lineStart, "---") ||
+			p.atMarker(lineStart, "...") {
+			break
+		}
+		lines = append(lines, p.src[lineStart+contentIndent:end])
+		lastBreak = end < len(p.src)
+		p.pos = end
+	}
+
+	content := len(lines)
+	for content > 0 && lines[content-1] == "" {
+		content--
+	}
+	var text string
+	if literal {
+		text = strings.Join(lines[:content], "\n")
+	} else {
+		text = foldLines(lines[:content])
+	}
+	switch {
+	case chomp == '+':
+		if lastBreak {
+			text += "\n"
+		}
+		text += strings.Repeat("\n", len(lines)-content)
+	case chomp == 0 && content > 0 && lastBreak:
+		text += "\n"
+	}
+	return &node{kind: scalarNode, value: text, pos: start}, nil
+}
+
+// foldLines joins lines of folded scalar: with a space, unless there are
+// empty lines between or the lines are more indented.
+func foldLines(lines []string) string {
+	var sb strings.Builder
+	empty, prevMore := 0, false
+	for i, line := range lines {
+		if line == "" {
+			empty++
+			continue
+		}
+		more := isBlank(line[0])
+		switch {
+		case empty == i:
+			// Leading empty lines are kept
+			sb.WriteString(strings.Repeat("\n", empty))
+		case !more && !prevMore && empty == 0:
+			sb.WriteByte(' ')
+		case !more && !prevMore:
+			sb.WriteString(strings.Repeat("\n", empty))
+		default:
+			sb.WriteString(strings.Repeat("\n", empty+1))
+		}
+		sb.WriteString(line)
+		empty, prevMore = 0, more
+	}
+	return sb.String()
+}
+
+//=============================================================================
+
+// flowNode parses node inside flow collection.
+func (p *parser) flowNode() (*node, error) {
+	p.skipSpace()
+	start := p.pos
+	anchor, tag, err := p.properties()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	var n *node
+	switch c := p.peek(); {
+	case c == ',' || c == ']' || c == '}' || p.atValueIndicator() ||
+		(c == ':' && isFlowIndicator(p.at(p.pos+1))):
+		n = p.empty(p.pos)
+	case c == '[' || c == '{':
+		n, err = p.flowCollection()
+	case c == '*':
+		n, err = p.alias()
+	case c == '"':
+		n, err = p.doubleQuoted()
+	case c == '\'':
+		n, err = p.singleQuoted()
+	default:
+		n, err = p.plain(0, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return n, p.attach(n, anchor, tag, start)
+}
+
+// flowCollection parses flow sequence or flow mapping, sequence entries
+// with ':' are single pair mappings.
+func (p *parser) flowCollection() (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	start := p.pos
+	n := &node{kind: sequenceNode, pos: start}
+	closing := byte(']')
+	if p.peek() == '{' {
+		n.kind, closing = mappingNode, '}'
+	}
+	p.pos++
+	for {
+		p.skipSpace()
+		if p.peek() == closing {
+			p.pos++
+			return n, nil
+		}
+		explicit := p.peek() == '?' && p.isSpace(p.pos+1)
+		if explicit {
+			p.pos++
+		}
+		key, err := p.flowNode()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		var value *node
+		if c := p.peek(); c == ':' && (p.isSpace(p.pos+1) ||
+			isFlowIndicator(p.at(p.pos+1)) || !key.plain) {
+			p.pos++
+			if value, err = p.flowNode(); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+		}
+		switch {
+		case n.kind == mappingNode && value == nil:
+			n.children = append(n.children, key, p.empty(p.pos))
+		case n.kind == mappingNode:
+			n.children = append(n.children, key, value)
+		case value != nil || explicit:
+			if value == nil {
+				value = p.empty(p.pos)
+			}
+			n.children = append(n.children, &node{kind: mappingNode,
+				children: []*node{key, value}, pos: key.pos})
+		default:
+			n.children = append(n.children, key)
+		}
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case closing:
+		default:
+			if p.pos >= len(p.src) {
+				return nil, p.error("found unclosed flow collection", start)
+			}
+			return nil, p.error(fmt.Sprintf("expected ',' or '%c'", closing),
+				p.pos)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package yaml reads and writes YAML documents with listdict types.
+
+	value, _ := yaml.Load("name: web\nports: [80, 443]\n", nil)
+	// value = listdict.Dict{"name": "web", "ports": listdict.List{80, 443}}
+	s, _ := yaml.Dump(value, nil)	// s = "name: web\nports:\n- 80\n- 443\n"
+
+Scalars are resolved with the YAML 1.2 core schema, so yes, no, on and off
+stay strings:
+
+	null, ~, empty             nil
+	true, false                bool
+	12, 0o14, 0xC              int, or *big.Int if it doesn't fit
+	1.5, 1e3, .inf, .nan       float64
+	anything else              string
+
+Sequences are decoded as listdict.List and mappings as listdict.Dict,
+or *listdict.OrderedDict with LoadOptions.Ordered. Mappings with keys
+that are not strings are decoded as LoadOptions.Keys says. Anchors,
+aliases (an alias gives the same value as its anchor), merge keys (<<),
+multi-document streams and the standard tags !!str, !!int, !!float,
+!!bool, !!null, !!binary, !!seq, !!map, !!set and !!omap are supported.
+Other tags are given to LoadOptions.TagHook.
+*/
+package yaml
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gosimple/listdict"
+)
+
+// KeyPolicy is how Load decodes mappings with keys that are not strings.
+type KeyPolicy int
+
+const (
+	// KeysHashDict decodes such mappings as *listdict.HashDict, sequence
+	// keys are listdict.Tuple
+	KeysHashDict KeyPolicy = iota
+	// KeysToString converts scalar keys to strings as they are written,
+	// e.g. key 01 to "01", so the mapping is still a Dict
+	KeysToString
+	// KeysError returns ErrNonStringKey for such mappings
+	KeysError
+)
+
+// Style is how Dump writes collections.
+type Style int
+
+const (
+	// Block writes one item per line, nested collections indented
+	Block Style = iota
+	// Flow writes collections like JSON: [1, 2] and {a: 1}
+	Flow
+	// FlowLeaves writes collections holding only scalars in flow style
+	// and the others in block style, as PyYAML's default_flow_style=None
+	FlowLeaves
+)
+
+// LoadOptions are options of Load and LoadAll. The zero value decodes
+// mappings as Dict, non-string keys as HashDict and refuses unknown tags.
+type LoadOptions struct {
+	// Ordered decodes mappings as *listdict.OrderedDict, keeping keys in
+	// document order
+	Ordered bool
+	// Keys is how mappings with keys that are not strings are decoded
+	Keys KeyPolicy
+	// TagHook, if not nil, gets values of nodes with tags Load doesn't
+	// know, e.g. "!Ref", decoded as if there was no tag, and its result
+	// is used instead
+	TagHook func(tag string, value interface{}) (interface{}, error)
+}
+
+// DumpOptions are options of Dump and DumpAll. Use DefaultDumpOptions to
+// get PyYAML defaults.
+type DumpOptions struct {
+	// Indent is the number of spaces for each nesting level, from 2 to 9,
+	// other values mean 2
+	Indent int
+	// SortKeys sorts keys of ordered dictionaries, like OrderedDict.
+	// Keys of Dict and Go maps are always sorted, they have no order.
+	SortKeys bool
+	// Style is how collections are written
+	Style Style
+	// ExplicitStart begins every document with "---"
+	ExplicitStart bool
+	// Default returns a representable version of a value Dump doesn't
+	// know, it can be nil
+	Default func(value interface{}) (interface{}, error)
+}
+
+var (
+	// ErrInvalidYAML is returned when user want to decode invalid YAML
+	ErrInvalidYAML = errors.New("invalid YAML")
+	// ErrDuplicateKey is returned when user want to decode mapping with
+	// the same key twice
+	ErrDuplicateKey = errors.New("duplicate mapping key")
+	// ErrNonStringKey is returned when user want to decode mapping with
+	// key that is not a string using KeysError, or a collection key using
+	// KeysToString
+	ErrNonStringKey = errors.New("mapping key is not a string")
+	// ErrUnknownTag is returned when user want to decode node with a tag
+	// Load doesn't know and there is no TagHook
+	ErrUnknownTag = errors.New("unknown tag")
+	// ErrUnrepresentable is returned when user want to encode value of
+	// a type YAML doesn't support
+	ErrUnrepresentable = errors.New("value can't be represented in YAML")
+	// ErrCircularReference is returned when user want to encode value
+	// that contains itself
+	ErrCircularReference = errors.New("circular reference detected")
+)
+
+// LoadError is returned by Load and LoadAll for documents they can't
+// decode. It wraps ErrInvalidYAML, ErrDuplicateKey, ErrNonStringKey,
+// ErrUnknownTag or error of TagHook.
+type LoadError struct {
+	Msg  string // what is wrong
+	Line int    // line where decoding failed, from 1
+	Col  int    // column of Line, from 1
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%v: %s: line %d column %d", e.Err, e.Msg, e.Line,
+		e.Col)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+//=============================================================================
+
+// Load decodes a single YAML document, an empty stream gives nil. Options
+// can be nil. Invalid documents return *LoadError.
+//
+//	yaml.Load("[a, {b: 1}]", nil) => listdict.List{"a", listdict.Dict{"b": 1}}
+func Load(text string, options *LoadOptions) (interface{}, error) {
+	p := newParser(text)
+	docs, err := p.stream()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) > 1 {
+		return nil, p.error("expected a single document in the stream",
+			docs[1].pos)
+	}
+	values, err := decodeDocuments(p, docs, options)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// LoadAll decodes all documents of YAML stream, same as Load.
+//
+//	yaml.LoadAll("a\n---\nb\n", nil) => listdict.List{"a", "b"}
+func LoadAll(text string, options *LoadOptions) (listdict.List, error) {
+	p := newParser(text)
+	docs, err := p.stream()
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocuments(p, docs, options)
+}
+
+// DefaultDumpOptions returns options of PyYAML's dump with default
+// arguments: block style, 2 spaces indentation and sorted keys.
+func DefaultDumpOptions() *DumpOptions {
+	return &DumpOptions{Indent: 2, SortKeys: true}
+}
+
+// Dump returns value encoded as YAML document. If options is nil
+// DefaultDumpOptions are used. Containers used more than once are written
+// once with an anchor and then as aliases.
+//
+//	yaml.Dump(listdict.Dict{"a": listdict.List{1, "x"}}, nil) => "a:\n- 1\n- x\n"
+func Dump(value interface{}, options *DumpOptions) (string, error) {
+	return DumpAll(listdict.List{value}, options)
+}
+
+// DumpAll returns documents encoded as YAML stream, same as Dump.
+func DumpAll(docs listdict.List, options *DumpOptions) (string, error) {
+	if options == nil {
+		options = DefaultDumpOptions()
+	}
+	e := &encoder{options: options, indent: options.Indent}
+	if e.indent < 2 || e.indent > 9 {
+		e.indent = 2
+	}
+	for i, doc := range docs {
+		if err := e.document(doc, i > 0 || options.ExplicitStart); err != nil {
+			return "", err
+		}
+	}
+	return e.buf.String(), nil
+}
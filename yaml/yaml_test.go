@@ -0,0 +1,371 @@
+// Copyright 2012 Dobrosław Żybort
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gosimple/listdict"
+	"github.com/gosimple/listdict/internal/container"
+)
+
+//=============================================================================
+
+// Values checked against PyYAML's safe_load, apart from YAML 1.1 scalars
+var loadTests = []struct {
+	in   string
+	repr string
+}{
+	{"", "None"},
+	{"# only comment\n", "None"},
+	{"a: 1\nb: [x, 'y', \"z\"]\nc: {d: ~}\n",
+		"{'a': 1, 'b': ['x', 'y', 'z'], 'c': {'d': None}}"},
+	{"- null\n- ~\n-\n- true\n- False\n- 12\n- -3\n- 0o17\n- 0x1f\n- 1.5\n" +
+		"- -2e3\n- .inf\n- -.Inf\n- .5\n- 123456789012345678901234567890\n",
+		"[None, None, None, True, False, 12, -3, 15, 31, 1.5, -2000.0, inf, " +
+			"-inf, 0.5, 123456789012345678901234567890]"},
+	// YAML 1.1 booleans and sexagesimals are strings in YAML 1.2
+	{"[yes, no, on, off, 1:30, 2001-12-14]",
+		"['yes', 'no', 'on', 'off', '1:30', '2001-12-14']"},
+	{"# top\na: 1 # one\n# mid\nb: # empty\n  c: 2\n",
+		"{'a': 1, 'b': {'c': 2}}"},
+	{"k:\n- a\n- b\nj: 1\n", "{'j': 1, 'k': ['a', 'b']}"},
+	{"- name: a\n  items:\n  - 1\n  - 2\n- name: b\n",
+		"[{'items': [1, 2], 'name': 'a'}, {'name': 'b'}]"},
+	{"- - a\n  - b\n- - c\n", "[['a', 'b'], ['c']]"},
+	{"? a\n: 1\n? b\n: 2\n", "{'a': 1, 'b': 2}"},
+	{"- [a: 1, b]\n- {a, b: }\n", "[[{'a': 1}, 'b'], {'a': None, 'b': None}]"},
+	{"a: this is\n  multi line\n\n  text\n", "{'a': 'this is multi line\\ntext'}"},
+	{"- \"a\n\n  b\n   c\"\n", "['a\\nb c']"},
+	{"a: 'it''s'\nb: \"\\0\\a\\b\\e\\N\\_\\L\\P\\/\\ \\x41\\u017c\\U0001F600\"\n",
+		"{'a': \"it's\", 'b': '\\x00\\x07\\x08\\x1b\\x85\\xa0\\u2028\\u2029/ Aż\U0001F600'}"},
+	{"a: |\n  line1\n   line2\n\n  line3\nb: |-\n  strip\nc: |+\n  keep\n\nd: x\n",
+		"{'a': 'line1\\n line2\\n\\nline3\\n', 'b': 'strip', 'c': 'keep\\n\\n', " +
+			"'d': 'x'}"},
+	{"a: >\n  folded\n  line\n\n  next\n    more\n  last\nb: >-\n  x\n  y\n",
+		"{'a': 'folded line\\nnext\\n  more\\nlast\\n', 'b': 'x y'}"},
+	{"- |2\n   lead\n- >\n  fold\n  ed\n", "[' lead\\n', 'fold ed\\n']"},
+	{"- !!str 12\n- !!int '7'\n- !!float '3.5'\n- !!binary aGVsbG8=\n" +
+		"- !!bool 'true'\n- !!null ''\n- !<tag:yaml.org,2002:str> 5\n",
+		"['12', 7, 3.5, b'hello', True, None, '5']"},
+	{"%TAG !e! tag:yaml.org,2002:\n---\n!e!str 5\n", "'5'"},
+	{"\ufeffa: 1\r\nb: 2\r\n", "{'a': 1, 'b': 2}"},
+	{"[1, 2]: pair\n3: three\n", "{(1, 2): 'pair', 3: 'three'}"},
+	{"!!set {a, b}", "{'a', 'b'}"},
+}
+
+func TestLoad(t *testing.T) {
+	for index, lt := range loadTests {
+		out, err := Load(lt.in, nil)
+		if repr := listdict.Repr(out); repr != lt.repr || err != nil {
+			t.Errorf("%d. Load(%q) => %s, %v, want %s",
+				index, lt.in, repr, err, lt.repr)
+		}
+	}
+
+	out, _ := Load("a: [1, 2.5, x]\n", nil)
+	want := listdict.Dict{"a": listdict.List{1, 2.5, "x"}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("Load() => %#v, want %#v", out, want)
+	}
+	if out, _ := Load("- .nan\n", nil); !math.IsNaN(out.(listdict.List)[0].(float64)) {
+		t.Errorf("Load(.nan) => %v, want NaN", out)
+	}
+}
+
+func TestLoadAnchors(t *testing.T) {
+	out, err := Load("base: &b\n  x: 1\n  y: 2\nother: &o {z: 3}\n"+
+		"d:\n  <<: [*b, *o]\n  y: 9\ne: {<<: *b}\nlist: &l [1]\nagain: *l\n", nil)
+	want := "{'again': [1], 'base': {'x': 1, 'y': 2}, 'd': {'x': 1, 'y': 9, " +
+		"'z': 3}, 'e': {'x': 1, 'y': 2}, 'list': [1], 'other': {'z': 3}}"
+	if repr := listdict.Repr(out); repr != want || err != nil {
+		t.Fatalf("Load(anchors) => %s, %v, want %s", repr, err, want)
+	}
+	// An alias gives the same value as its anchor
+	dict := out.(listdict.Dict)
+	dict["list"].(listdict.List)[0] = "changed"
+	if dict["again"].(listdict.List)[0] != "changed" {
+		t.Errorf("alias is not shared with anchor")
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	out, err := LoadAll("---\na: 1\n---\n- x\n...\n---\nplain\n", nil)
+	want := "[{'a': 1}, ['x'], 'plain']"
+	if repr := listdict.Repr(out); repr != want || err != nil {
+		t.Errorf("LoadAll() => %s, %v, want %s", repr, err, want)
+	}
+	if out, err := LoadAll("", nil); len(out) != 0 || err != nil {
+		t.Errorf("LoadAll(\"\") => %v, %v, want []", out, err)
+	}
+	if _, err := Load("a\n---\nb\n", nil); !errors.Is(err, ErrInvalidYAML) {
+		t.Errorf("Load(2 documents) => %v, want ErrInvalidYAML", err)
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	out, err := Load("z: 1\na: {y: 2, b: 3}\n", &LoadOptions{Ordered: true})
+	want := "OrderedDict({'z': 1, 'a': OrderedDict({'y': 2, 'b': 3})})"
+	if repr := listdict.Repr(out); repr != want || err != nil {
+		t.Errorf("Load(Ordered) => %s, %v, want %s", repr, err, want)
+	}
+
+	out, err = Load("01: a\n2.50: b\nnull: c\n", &LoadOptions{Keys: KeysToString})
+	want = "{'01': 'a', '2.50': 'b', 'null': 'c'}"
+	if repr := listdict.Repr(out); repr != want || err != nil {
+		t.Errorf("Load(KeysToString) => %s, %v, want %s", repr, err, want)
+	}
+	if _, err := Load("[1]: a\n", &LoadOptions{Keys: KeysToString}); !errors.
+		Is(err, ErrNonStringKey) {
+		t.Errorf("Load([1] key, KeysToString) => %v, want ErrNonStringKey", err)
+	}
+	if _, err := Load("1: a\n", &LoadOptions{Keys: KeysError}); !errors.
+		Is(err, ErrNonStringKey) {
+		t.Errorf("Load(1 key, KeysError) => %v, want ErrNonStringKey", err)
+	}
+
+	hook := func(tag string, value interface{}) (interface{}, error) {
+		if tag == "!fail" {
+			return nil, errors.New("failed")
+		}
+		return listdict.List{tag, value}, nil
+	}
+	out, err = Load("- !Ref name\n- !Sub [a, 1]\n- !env {k: 1}\n",
+		&LoadOptions{TagHook: hook})
+	want = "[['!Ref', 'name'], ['!Sub', ['a', 1]], ['!env', {'k': 1}]]"
+	if repr := listdict.Repr(out); repr != want || err != nil {
+		t.Errorf("Load(TagHook) => %s, %v, want %s", repr, err, want)
+	}
+	if _, err := Load("!fail x", &LoadOptions{TagHook: hook}); err == nil ||
+		err.Error() != `failed: tag "!fail": line 1 column 1` {
+		t.Errorf("Load(!fail) => %v", err)
+	}
+}
+
+var loadErrorTests = []struct {
+	in  string
+	err error
+	msg string
+}{
+	{"a: *nope\n", ErrInvalidYAML,
+		`invalid YAML: found undefined alias "nope": line 1 column 4`},
+	{"a: b: c\n", ErrInvalidYAML,
+		"invalid YAML: mapping values are not allowed here: line 1 column 5"},
+	{"a:\n  b: 1\n c: 2\n", ErrInvalidYAML,
+		"invalid YAML: bad indentation of a mapping entry: line 3 column 2"},
+	{"a: [1, 2\n", ErrInvalidYAML,
+		"invalid YAML: found unclosed flow collection: line 1 column 4"},
+	{"a: 1\nb: 2\na: 3\n", ErrDuplicateKey,
+		"duplicate mapping key: found duplicate key 'a': line 3 column 1"},
+	{"!!int x", ErrInvalidYAML,
+		`invalid YAML: invalid value "x" for the tag "tag:yaml.org,2002:int": ` +
+			"line 1 column 1"},
+	{"!Ref x", ErrUnknownTag,
+		`unknown tag: could not determine a constructor for the tag "!Ref": ` +
+			"line 1 column 1"},
+	{"{a: 1}: x\n", nil, ""},
+	{strings.Repeat("[", container.MaxDepth+1), ErrInvalidYAML,
+		"invalid YAML: maximum nesting depth exceeded: line 1 column 10001"},
+	{strings.Repeat("- ", container.MaxDepth+1) + "x\n", ErrInvalidYAML,
+		"invalid YAML: maximum nesting depth exceeded: line 1 column 20001"},
+}
+
+func TestLoadErrors(t *testing.T) {
+	for index, lt := range loadErrorTests {
+		_, err := Load(lt.in, nil)
+		var loadError *LoadError
+		if lt.err == nil {
+			// Dict keys are unhashable
+			if !errors.As(err, &loadError) {
+				t.Errorf("%d. Load(%q) => %v, want *LoadError", index, lt.in, err)
+			}
+			continue
+		}
+		if !errors.Is(err, lt.err) || !errors.As(err, &loadError) ||
+			err.Error() != lt.msg {
+			t.Errorf("%d. Load(%q) => %v, want %s", index, lt.in, err, lt.msg)
+		}
+	}
+	nested := strings.Repeat("[", container.MaxDepth) + strings.Repeat("]", container.MaxDepth)
+	if _, err := Load(nested, nil); err != nil {
+		t.Errorf("Load(nested %d times) => %v, want nil", container.MaxDepth, err)
+	}
+}
+
+//=============================================================================
+
+// Output of PyYAML's dump with the same options
+var dumpTests = []struct {
+	in      interface{}
+	options *DumpOptions
+	out     string
+}{
+	{nil, nil, "null\n...\n"},
+	{"'", nil, "''''\n"},
+	{"x", nil, "x\n...\n"},
+	{listdict.Dict{}, nil, "{}\n"},
+	{listdict.Dict{"b": listdict.List{1, "x", nil}, "a": listdict.Dict{"c": true}},
+		nil, "a:\n  c: true\nb:\n- 1\n- x\n- null\n"},
+	{listdict.List{listdict.List{1, 2}, listdict.Dict{"x": 1, "y": listdict.List{}}},
+		nil, "- - 1\n  - 2\n- x: 1\n  y: []\n"},
+	{listdict.List{listdict.List{1, 2}, listdict.Dict{"x": 1, "y": listdict.List{}}},
+		&DumpOptions{Indent: 4, SortKeys: true},
+		"-   - 1\n    - 2\n-   x: 1\n    y: []\n"},
+	{listdict.Dict{"a": listdict.List{1, 2}, "b": listdict.Dict{"c": "d"}},
+		&DumpOptions{Style: Flow}, "{a: [1, 2], b: {c: d}}\n"},
+	{listdict.Dict{"a": listdict.List{1, 2}, "b": listdict.Dict{"c": "d"}},
+		&DumpOptions{Style: FlowLeaves}, "a: [1, 2]\nb: {c: d}\n"},
+	{listdict.List{"", "yes", "No", "1st", "12:30", "null", "1.5", "-x", "- x",
+		"a: b", "a #b", "#c", "a,b", "'q'", "...", "---", "<<", "key:"}, nil,
+		"- ''\n- 'yes'\n- 'No'\n- 1st\n- '12:30'\n- 'null'\n- '1.5'\n- -x\n" +
+			"- '- x'\n- 'a: b'\n- 'a #b'\n- '#c'\n- a,b\n- '''q'''\n- '...'\n" +
+			"- '---'\n- '<<'\n- 'key:'\n"},
+	{listdict.List{"a,b", "x:y", "?q"}, &DumpOptions{Style: Flow},
+		"['a,b', 'x:y', '?q']\n"},
+	{listdict.List{"tab\there", "ctl\x01", "é", "\u2028"}, nil,
+		"- \"tab\\there\"\n- \"ctl\\x01\"\n- é\n- \"\\L\"\n"},
+	{listdict.Dict{"k": "a\nb\n", "l": " x\ny", "m": "x\n\n", "n": "x\ny"}, nil,
+		"k: |\n  a\n  b\nl: |2-\n   x\n  y\nm: |+\n  x\n\nn: |-\n  x\n  y\n"},
+	{listdict.List{0, -5, 1.0, 0.1, 1e16, 1e-7, math.Inf(-1), math.NaN(),
+		uint8(7), float32(1.5)}, nil,
+		"- 0\n- -5\n- 1.0\n- 0.1\n- 1.0e+16\n- 1.0e-07\n- -.inf\n- .nan\n" +
+			"- 7\n- 1.5\n"},
+	{listdict.List{[]byte("hello")}, nil, "- !!binary |\n  aGVsbG8=\n"},
+	{map[string]int{"b": 2, "a": 1}, nil, "a: 1\nb: 2\n"},
+}
+
+func TestDump(t *testing.T) {
+	for index, dt := range dumpTests {
+		if out, err := Dump(dt.in, dt.options); out != dt.out || err != nil {
+			t.Errorf("%d. Dump(%v) => %q, %v, want %q",
+				index, dt.in, out, err, dt.out)
+		}
+	}
+}
+
+func TestDumpContainers(t *testing.T) {
+	ordered := listdict.NewOrderedDict()
+	ordered.Set("z", 1)
+	ordered.Set("a", 2)
+	options := &DumpOptions{}
+	if out, err := Dump(ordered, options); out != "z: 1\na: 2\n" || err != nil {
+		t.Errorf("Dump(OrderedDict) => %q, %v", out, err)
+	}
+	options.SortKeys = true
+	if out, err := Dump(ordered, options); out != "a: 2\nz: 1\n" || err != nil {
+		t.Errorf("Dump(OrderedDict, SortKeys) => %q, %v", out, err)
+	}
+
+	// Keys that can't be compared keep their order, as in PyYAML
+	hashDict := listdict.NewHashDict()
+	hashDict.Set(listdict.Tuple{1, 2}, "pair")
+	hashDict.Set(nil, 3)
+	out, err := Dump(hashDict, nil)
+	if out != "[1, 2]: pair\nnull: 3\n" || err != nil {
+		t.Errorf("Dump(HashDict) => %q, %v", out, err)
+	}
+
+	set, _ := listdict.SetFromList(listdict.List{"b", "a"})
+	out, err = Dump(listdict.Dict{"s": set}, nil)
+	if out != "s: !!set\n  a: null\n  b: null\n" || err != nil {
+		t.Errorf("Dump(Set) => %q, %v", out, err)
+	}
+	if back, _ := Load(out, nil); listdict.Repr(back) != "{'s': {'a', 'b'}}" {
+		t.Errorf("Load(Dump(Set)) => %s", listdict.Repr(back))
+	}
+}
+
+func TestDumpShared(t *testing.T) {
+	shared := listdict.List{1, 2}
+	value := listdict.Dict{"a": shared, "b": shared, "c": listdict.List{shared}}
+	want := "a: &id001\n- 1\n- 2\nb: *id001\nc:\n- *id001\n"
+	if out, err := Dump(value, nil); out != want || err != nil {
+		t.Errorf("Dump(shared) => %q, %v, want %q", out, err, want)
+	}
+	options := &DumpOptions{Style: Flow, SortKeys: true}
+	want = "{a: &id001 [1, 2], b: *id001, c: [*id001]}\n"
+	out, err := Dump(value, options)
+	if out != want || err != nil {
+		t.Errorf("Dump(shared, Flow) => %q, %v, want %q", out, err, want)
+	}
+	back, _ := Load(out, nil)
+	if listdict.Repr(back) != listdict.Repr(value) {
+		t.Errorf("Load(Dump(shared)) => %s", listdict.Repr(back))
+	}
+
+	list := listdict.List{1, nil}
+	list[1] = list
+	if _, err := Dump(list, nil); !errors.Is(err, ErrCircularReference) {
+		t.Errorf("Dump(cycle) => %v, want ErrCircularReference", err)
+	}
+}
+
+func TestDumpAll(t *testing.T) {
+	out, err := DumpAll(listdict.List{listdict.Dict{"a": 1}, "x"}, nil)
+	if out != "a: 1\n--- x\n...\n" || err != nil {
+		t.Errorf("DumpAll() => %q, %v", out, err)
+	}
+	out, err = DumpAll(listdict.List{listdict.List{1}},
+		&DumpOptions{ExplicitStart: true})
+	if out != "---\n- 1\n" || err != nil {
+		t.Errorf("DumpAll(ExplicitStart) => %q, %v", out, err)
+	}
+}
+
+func TestDumpErrors(t *testing.T) {
+	_, err := Dump(listdict.List{struct{}{}}, nil)
+	if !errors.Is(err, ErrUnrepresentable) {
+		t.Errorf("Dump(struct{}) => %v, want ErrUnrepresentable", err)
+	}
+
+	options := DefaultDumpOptions()
+	options.Default = func(value interface{}) (interface{}, error) {
+		if c, ok := value.(complex128); ok {
+			return fmt.Sprint(c), nil
+		}
+		return nil, ErrUnrepresentable
+	}
+	out, err := Dump(listdict.List{complex(1, 2)}, options)
+	if out != "- (1+2i)\n" || err != nil {
+		t.Errorf("Dump(Default) => %q, %v", out, err)
+	}
+}
+
+var roundTripTests = []interface{}{
+	listdict.List{"", " lead", "trail ", "a\nb", "\nlead", "\n\n", "x\n\n\ny",
+		"tab\t", "ż", "\x00", "%c", "&d", "*e", "!f", "|g", ">h", "@a", "`b",
+		"0x1F", ".5", "+1", ".inf", "NaN", "~", "?", "-", ":x", "[x]", "{y}"},
+	listdict.Dict{"a\nb": 1, "": 2, "1": 3, "x": listdict.Dict{"y": listdict.
+		List{listdict.Dict{}, listdict.List{}, listdict.Dict{"z": "p\nq"}}}},
+	listdict.List{1 << 62, -0.5, 1e100, true, false, nil, []byte{0, 255}},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, style := range []Style{Block, Flow, FlowLeaves} {
+		for _, indent := range []int{2, 4} {
+			options := &DumpOptions{Indent: indent, Style: style}
+			for index, value := range roundTripTests {
+				out, err := Dump(value, options)
+				if err != nil {
+					t.Errorf("%d. Dump(%v, %d, %d) => %v",
+						index, value, style, indent, err)
+					continue
+				}
+				back, err := Load(out, nil)
+				if !reflect.DeepEqual(back, value) || err != nil {
+					t.Errorf("%d. Load(Dump(%v, %d, %d)) => %#v, %v\n%s",
+						index, value, style, indent, back, err, out)
+				}
+			}
+		}
+	}
+}